@@ -0,0 +1,230 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package controller
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/slukits/gini/cmd/gini/model"
+	"github.com/slukits/gini/pkg/dir"
+	"github.com/slukits/gini/pkg/file"
+	"github.com/slukits/gini/pkg/gnh"
+	"github.com/slukits/gini/pkg/lex"
+	"github.com/slukits/gini/pkg/lg"
+	"github.com/slukits/gini/pkg/spell"
+)
+
+// errNoFile is returned by a buffer's Save if it isn't backed by a
+// file.
+var errNoFile = errors.New("gini: controller: buffer: no file to save")
+
+// buffer maps an editor's requests to the model buffer of the edited
+// text.  Next to providing the buffer's content it makes sure that an
+// insert-mode session becomes one undo step while the u/U commands of
+// an editor are mapped to the buffer's undo-history.
+type buffer struct {
+	*model.Buffer
+
+	// file backs the buffer's content.
+	file *file.File
+
+	// edit is called before the buffer is edited for the first time.
+	edit func() error
+
+	editing bool
+
+	// path is the displayed path of file.
+	path string
+
+	// modified is true if the content was modified since the last
+	// save.
+	modified bool
+
+	// config provides the settings applied to file on saving.
+	config *config
+
+	// page is the name of the help page the buffer holds or empty.
+	page string
+
+	// doc is the parsed help page which is reset by modifications.
+	doc *gnh.Node
+
+	// lexed are the lines lexed by lexer which is the lexer of the
+	// buffer's file type if typed is true (see highlight.go).
+	lexed *lex.Lexed
+	lexer *lex.Lexer
+	typed bool
+
+	// checker spell-checks the buffer (see spell.go) according to the
+	// spelling settings of its config's spellings count.
+	checker   *spell.Checker
+	spellings int
+}
+
+// Editing is informed by an editor about entering (editing = true) or
+// leaving (editing = false) the insert or overwrite mode.  All
+// modifications in between are undone and redone at once.
+func (b *buffer) Editing(editing bool) {
+	if b.editing == editing {
+		return
+	}
+	b.editing = editing
+	if editing {
+		b.firstEdit()
+		b.Begin()
+		return
+	}
+	b.End()
+}
+
+// firstEdit calls a buffer's edit hook once logging its error.
+func (b *buffer) firstEdit() {
+	if b.edit == nil {
+		return
+	}
+	edit := b.edit
+	b.edit = nil
+	if err := edit(); err != nil && b.file != nil {
+		b.file.Log.Tof(lg.ERR, "%v", err)
+	}
+}
+
+// Insert inserts given string s at given position marking the buffer as
+// modified and re-lexing the touched lines.  The edit hook is called
+// before the first modification, e.g. by a command mode paste.
+func (b *buffer) Insert(ln, cl int, s string) error {
+	b.firstEdit()
+	if err := b.Buffer.Insert(ln, cl, s); err != nil {
+		return err
+	}
+	b.modified, b.doc = true, nil
+	b.relex(ln, 1, 1+strings.Count(s, "\n"))
+	return nil
+}
+
+// Delete deletes given number n of runes at given position marking the
+// buffer as modified and re-lexing the touched lines.  The edit hook is
+// called before the first modification.
+func (b *buffer) Delete(ln, cl, n int) (string, error) {
+	b.firstEdit()
+	s, err := b.Buffer.Delete(ln, cl, n)
+	if err != nil {
+		return s, err
+	}
+	b.modified, b.doc = true, nil
+	b.relex(ln, 1+strings.Count(s, "\n"), 1)
+	return s, nil
+}
+
+// Undo reverts the last undo step returning the position where the
+// undone modification happened.  Undo leaves a running insert-mode
+// session.
+func (b *buffer) Undo() (ln, cl int, ok bool) {
+	b.Editing(false)
+	ln, cl, ok = b.Buffer.Undo()
+	b.modified, b.doc = b.modified || ok, nil
+	b.unlex()
+	return ln, cl, ok
+}
+
+// Redo reapplies the last undone undo step returning the position
+// where the redone modification happened.
+func (b *buffer) Redo() (ln, cl int, ok bool) {
+	b.Editing(false)
+	ln, cl, ok = b.Buffer.Redo()
+	b.modified, b.doc = b.modified || ok, nil
+	b.unlex()
+	return ln, cl, ok
+}
+
+// Modified returns true if the buffer was modified since it was loaded
+// or saved.
+func (b *buffer) Modified() bool { return b.modified }
+
+// File returns the path of the buffer's file relative to the root of
+// the repository containing it or the full path if it isn't inside a
+// repository.
+func (b *buffer) File() string {
+	if b.file == nil {
+		return ""
+	}
+	if b.path == "" {
+		b.path = repoPath(b.file.Log, b.file.Path)
+	}
+	return b.path
+}
+
+// repoPath returns given path relative to the root of the repository
+// containing it or path itself if there is no such repository.
+func repoPath(lgg *lg.Logger, path string) string {
+	root := projectRoot(lgg, path)
+	if root == "" {
+		return path
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
+
+// projectRoot returns the root of the repository containing given path
+// or an empty string if there is no such repository.
+func projectRoot(lgg *lg.Logger, path string) string {
+	if path == "" {
+		return ""
+	}
+	d := filepath.Dir(path)
+	if _, err := os.Stat(d); err != nil {
+		return ""
+	}
+	repo, ok := (&dir.Dir{Log: lgg, Path: d}).Repo()
+	if !ok {
+		return ""
+	}
+	return repo.String()
+}
+
+// Save writes the buffer's content to its backing file which backs up
+// the overwritten content according to the file's settings.  Saving a
+// settings file reloads the settings.  Errors are logged to lg.ERR and
+// returned.
+func (b *buffer) Save() error {
+	if b.file == nil {
+		return errNoFile
+	}
+	if b.config != nil {
+		b.config.apply(b.file)
+	}
+	if err := b.file.Save(b.Buffer); err != nil {
+		b.file.Log.Tof(lg.ERR, "%v", err)
+		return err
+	}
+	b.modified = false
+	if b.config != nil && b.config.ss.IsFile(b.file.Path) {
+		b.config.ss.Reload()
+	}
+	return nil
+}
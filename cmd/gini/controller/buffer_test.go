@@ -0,0 +1,127 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package controller
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/slukits/gini/cmd/gini/model"
+	"github.com/slukits/gini/pkg/env"
+	"github.com/slukits/gini/pkg/file"
+	"github.com/slukits/gini/pkg/lg"
+	. "github.com/slukits/gounit"
+)
+
+type Buffer struct{ Suite }
+
+func (s *Buffer) SetUp(t *T) { t.Parallel() }
+
+func (s *Buffer) Undoes_an_editing_session_at_once(t *T) {
+	b := &buffer{Buffer: model.NewBuffer([]byte("gini\n"))}
+	b.Editing(true)
+	t.FatalOn(b.Insert(1, 0, "is"))
+	t.FatalOn(b.Insert(1, 2, " not"))
+	b.Editing(false)
+	ln, cl, ok := b.Undo()
+	t.True(ok)
+	t.Eq("gini\n", b.String())
+	t.Eq(1, ln)
+	t.Eq(0, cl)
+	_, _, ok = b.Redo()
+	t.True(ok)
+	t.Eq("gini\nis not", b.String())
+}
+
+func (s *Buffer) Undo_leaves_editing_session(t *T) {
+	b := &buffer{Buffer: model.NewBuffer([]byte("gini"))}
+	b.Editing(true)
+	t.FatalOn(b.Insert(0, 0, ">"))
+	b.Undo()
+	t.Not.True(b.editing)
+	t.FatalOn(b.Insert(0, 0, "<"))
+	b.Undo()
+	t.Eq("gini", b.String())
+}
+
+func (s *Buffer) Fails_saving_without_file(t *T) {
+	b := &buffer{Buffer: model.NewBuffer([]byte("gini"))}
+	t.ErrIs(b.Save(), errNoFile)
+}
+
+func fileFX(t *T) *file.File {
+	lgg := &lg.Logger{Env: (&env.Env{}).SetHome(t.FS().Tmp().Path())}
+	return &file.File{Log: lgg, Path: filepath.Join(
+		lgg.Env.Home(), "gini.txt")}
+}
+
+func (s *Buffer) Saves_its_content_to_its_file(t *T) {
+	b := &buffer{Buffer: model.NewBuffer([]byte("gini")), file: fileFX(t)}
+	t.FatalOn(b.Insert(0, 4, "!"))
+	t.FatalOn(b.Save())
+	bb, err := os.ReadFile(b.file.Path)
+	t.FatalOn(err)
+	t.Eq("gini!", string(bb))
+}
+
+func (s *Buffer) Logs_save_errors(t *T) {
+	b := &buffer{Buffer: model.NewBuffer([]byte("gini")), file: fileFX(t)}
+	b.file.Lib.Rename = func(_, _ string) error {
+		return errors.New("rename mock error")
+	}
+	t.Not.True(b.Save() == nil)
+	t.Contains(b.file.Log.String(lg.ERR), "rename mock error")
+}
+
+func (s *Buffer) Is_modified_until_saved(t *T) {
+	b := &buffer{Buffer: model.NewBuffer([]byte("gini")), file: fileFX(t)}
+	t.Not.True(b.Modified())
+	t.FatalOn(b.Insert(0, 4, "!"))
+	t.True(b.Modified())
+	t.FatalOn(b.Save())
+	t.Not.True(b.Modified())
+	_, err := b.Delete(0, 4, 1)
+	t.FatalOn(err)
+	t.True(b.Modified())
+}
+
+func (s *Buffer) Reports_its_file_relative_to_its_repository(t *T) {
+	fx := fileFX(t)
+	t.FatalOn(os.Mkdir(filepath.Join(fx.Log.Env.Home(), ".git"), 0700))
+	sub := filepath.Join(fx.Log.Env.Home(), "sub")
+	t.FatalOn(os.Mkdir(sub, 0700))
+	fx.Path = filepath.Join(sub, "gini.txt")
+	b := &buffer{Buffer: model.NewBuffer([]byte("gini")), file: fx}
+	t.Eq(filepath.Join("sub", "gini.txt"), b.File())
+}
+
+func (s *Buffer) Reports_its_full_file_path_outside_a_repository(t *T) {
+	b := &buffer{Buffer: model.NewBuffer([]byte("gini")), file: fileFX(t)}
+	t.Eq(b.file.Path, b.File())
+}
+
+func TestBuffer(t *testing.T) {
+	t.Parallel()
+	Run(&Buffer{}, t)
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package controller
+
+import (
+	"github.com/slukits/gini/cmd/gini/view"
+	ring "github.com/slukits/gini/pkg/collections"
+)
+
+// commands implement the view's Commands keeping executed commands in a
+// command ring and a movement ring.  Since a command's motion is a
+// function commands aren't persisted across sessions.
+type commands struct {
+	commands, movements ring.Ring[view.Cmd]
+}
+
+// Executed adds given command c to the movement ring if it only moves
+// the cursor and to the command ring otherwise.
+func (cc *commands) Executed(c view.Cmd) {
+	if c.IsMovement() {
+		cc.movements.Add(c)
+		return
+	}
+	cc.commands.Add(c)
+}
+
+// Commands returns the command ring's commands latest first.
+func (cc *commands) Commands() []view.Cmd { return elements(&cc.commands) }
+
+// Movements returns the movement ring's commands latest first.
+func (cc *commands) Movements() []view.Cmd { return elements(&cc.movements) }
+
+func elements(r *ring.Ring[view.Cmd]) (cc []view.Cmd) {
+	r.For(func(_ int, c view.Cmd) (stop bool) {
+		cc = append(cc, c)
+		return false
+	})
+	return cc
+}
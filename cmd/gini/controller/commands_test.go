@@ -0,0 +1,64 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/slukits/gini/cmd/gini/view"
+	. "github.com/slukits/gounit"
+)
+
+type Commands struct{ Suite }
+
+func (s *Commands) SetUp(t *T) { t.Parallel() }
+
+func (s *Commands) Keep_movements_apart_from_other_commands(t *T) {
+	cc := &commands{}
+	cc.Executed(view.Cmd{Op: view.Move, Target: "til '42'"})
+	cc.Executed(view.Cmd{Op: view.Delete, Target: "til word end"})
+	cc.Executed(view.Cmd{Op: view.Paste, Text: "gini"})
+	t.FatalIfNot(t.Eq(1, len(cc.Movements())))
+	t.Eq("go til '42'", cc.Movements()[0].String())
+	t.FatalIfNot(t.Eq(2, len(cc.Commands())))
+	t.Eq("paste 'gini'", cc.Commands()[0].String())
+	t.Eq("delete til word end", cc.Commands()[1].String())
+}
+
+func (s *Commands) Are_repeatable_in_the_view(t *T) {
+	fx, _ := tmpFX(t)
+	vw := fx.Root().(*view.View)
+	for _, r := range "tjks" {
+		fx.FireRune(r)
+	}
+	ed := vw.Editor().(interface{ Cursor() (int, int) })
+	_, cl := ed.Cursor()
+	fx.FireRune('m')
+	_, got := ed.Cursor()
+	t.True(got > cl)
+	t.Eq("go til space", vw.Commands.Movements()[0].String())
+}
+
+func TestCommands(t *testing.T) {
+	t.Parallel()
+	Run(&Commands{}, t)
+}
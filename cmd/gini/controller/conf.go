@@ -0,0 +1,87 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/slukits/gini/pkg/env"
+	"github.com/slukits/gini/pkg/file"
+	"github.com/slukits/gini/pkg/lg"
+)
+
+// confPath returns the path of the file with given name in the config
+// directory of given logger lgg's environment.
+func confPath(lgg *lg.Logger, name string) string {
+	if lgg.Env == nil {
+		lgg.Env = &env.Env{}
+	}
+	return filepath.Join(lgg.Env.Conf(), name)
+}
+
+// loadConf unmarshals the json file with given name in the config
+// directory into given value v.  It returns false if the file doesn't
+// exist or can't be loaded whereas the later is logged to lg.ERR.
+func loadConf(lgg *lg.Logger, name string, v interface{}) bool {
+	bb, err := os.ReadFile(confPath(lgg, name))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logConf(lgg, name, err)
+		}
+		return false
+	}
+	if err := json.Unmarshal(bb, v); err != nil {
+		logConf(lgg, name, err)
+		return false
+	}
+	return true
+}
+
+// saveConf writes given value v as json to the file with given name in
+// the config directory logging errors to lg.ERR.  The file is replaced
+// atomically, i.e. it is either completely written or left untouched,
+// while its previous content is kept as its backup.
+func saveConf(lgg *lg.Logger, name string, v interface{}) {
+	bb, err := json.Marshal(v)
+	if err != nil {
+		logConf(lgg, name, err)
+		return
+	}
+	path := confPath(lgg, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		logConf(lgg, name, err)
+		return
+	}
+	f := &file.File{Log: lgg, Path: path, Backups: 1, Perm: 0600}
+	if err := f.Save(bytes.NewBuffer(bb)); err != nil {
+		logConf(lgg, name, err)
+	}
+}
+
+func logConf(lgg *lg.Logger, name string, err error) {
+	lgg.Tof(lg.ERR, "gini: controller: %s: %v",
+		strings.TrimSuffix(name, filepath.Ext(name)), err)
+}
@@ -27,7 +27,12 @@ expressing the response.
 package controller
 
 import (
+	"path/filepath"
+
+	"github.com/slukits/gini/cmd/gini/model"
 	"github.com/slukits/gini/cmd/gini/view"
+	"github.com/slukits/gini/hlp"
+	"github.com/slukits/gini/pkg/file"
 	"github.com/slukits/gini/pkg/lg"
 	"github.com/slukits/lines"
 )
@@ -47,10 +52,54 @@ func New(init Init) {
 		}
 		init.Log.Fatalf("GINI: controller: panic: %v", err)
 	}()
-	ll := init.UIFactory()(&view.View{})
+	cfg := newConfig(&init.Log)
+	help, err := helpBuffer(&init.Log, cfg, hlp.Index)
+	if err != nil {
+		init.Log.Tof(lg.ERR, "%v", err)
+	}
+	registers := newRegisters(&init.Log)
+	ll := init.UIFactory()(&view.View{
+		Buffer:     help,
+		Registers:  registers,
+		Macros:     newMacros(&init.Log),
+		Commands:   &commands{},
+		Grepper:    newGrepper(&init.Log, cfg, help),
+		SavedGreps: newGreps(&init.Log),
+		Settings:   cfg,
+		Helper:     newHelper(&init.Log, cfg, help),
+		FileTypes:  cfg,
+	})
+	ll.OnQuit(registers.save)
+	if ll.Quitting != nil {
+		// 'q' is needed for editing
+		ll.Quitting.DelRune('q')
+	}
 	ll.WaitForQuit()
 }
 
+// helpBuffer returns the buffer of the help page with given name.  Its
+// modifications are saved to the page's file which is outside GINI's
+// repository a copy in the user's cache made as soon as the page is
+// edited for the first time.  A help page is displayed by its path
+// relative to the help directory's parent wherever it is stored.  Given
+// config provides the settings of the page's file.  The buffer styles,
+// indexes and links its page (see gnh).  The buffer of a page which
+// can't be loaded is empty.
+func helpBuffer(
+	lgg *lg.Logger, cfg *config, name string,
+) (*buffer, error) {
+	pp := &hlp.Pages{Log: lgg}
+	bb, err := pp.Load(name)
+	return &buffer{
+		Buffer: model.NewBuffer(bb),
+		file:   &file.File{Log: lgg, Path: pp.Path(name)},
+		edit:   func() error { return pp.Edit(name) },
+		path:   filepath.Join(hlp.Dir, name),
+		config: cfg,
+		page:   name,
+	}, err
+}
+
 type Init struct {
 	Lines func(lines.Componenter) *lines.Lines
 	Log   lg.Logger
@@ -33,9 +33,13 @@ package controller
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/slukits/gini/cmd/gini/view"
+	"github.com/slukits/gini/hlp"
 	"github.com/slukits/gini/pkg/env"
 	"github.com/slukits/gini/pkg/lg"
 	. "github.com/slukits/gounit"
@@ -89,7 +93,10 @@ func (s *GINI) Initializes_lines_fixture_and_view_on_new(t *T) {
 	t.True(ok)
 }
 
-func (s *GINI) Initially_shows_introductory_help(t *T) {
+// tmpFX creates a gini instance in a temporary environment whose
+// working directory is the environment's home directory which is not
+// inside gini's repository.
+func tmpFX(t *T) (*lines.Fixture, *Init) {
 	var init Init
 	var fx *lines.Fixture
 	init.Lines = func(c lines.Componenter) *lines.Lines {
@@ -100,8 +107,67 @@ func (s *GINI) Initially_shows_introductory_help(t *T) {
 	init.Log.Env.Lib.Chdir = func(path string) error { return nil }
 	t.FatalOn(init.Log.Env.ChWD(init.Log.Env.Home()))
 	New(init)
+	return fx, &init
+}
+
+func (s *GINI) Does_not_quit_on_q_in_command_mode(t *T) {
+	fx, _ := tmpFX(t)
+	vw := fx.Root().(*view.View)
+	fx.FireRune('q')
+	t.Not.True(fx.Lines.Quitting.Rune('q'))
+	fx.FireRune('i')
+	fx.FireRune('x')
+	t.Contains(fx.ScreenOf(vw.Editor()), "x# GINI Is Not an IDE")
+}
+
+func (s *GINI) Initially_shows_introductory_help(t *T) {
+	fx, _ := tmpFX(t)
 	vw := fx.Root().(*view.View)
 	t.Contains(fx.ScreenOf(vw.Context()), "hlp/index.gnh")
+	t.Contains(fx.ScreenOf(vw.Editor()), "GINI Is Not an IDE")
+}
+
+func (s *GINI) Copies_help_page_to_cache_on_first_edit(t *T) {
+	fx, init := tmpFX(t)
+	path := filepath.Join(init.Log.Env.Cache(), hlp.Dir, hlp.Index)
+	_, err := os.Stat(path)
+	t.True(os.IsNotExist(err))
+	fx.FireRune('i')
+	_, err = os.Stat(path)
+	t.FatalOn(err)
+}
+
+func (s *GINI) Saves_edited_help_page_to_cache(t *T) {
+	fx, init := tmpFX(t)
+	fx.FireRune('i')
+	fx.FireRune('q')
+	fx.FireKey(lines.Esc)
+	fx.FireRune('s')
+	bb, err := os.ReadFile(
+		filepath.Join(init.Log.Env.Cache(), hlp.Dir, hlp.Index))
+	t.FatalOn(err)
+	t.True(strings.HasPrefix(string(bb), "q# GINI"))
+}
+
+func (s *GINI) Saves_help_page_edited_in_command_mode_to_cache(t *T) {
+	fx, init := tmpFX(t)
+	fx.FireKey(lines.Delete)
+	fx.FireRune('s')
+	bb, err := os.ReadFile(
+		filepath.Join(init.Log.Env.Cache(), hlp.Dir, hlp.Index))
+	t.FatalOn(err)
+	t.True(strings.HasPrefix(string(bb), " GINI"))
+}
+
+func (s *GINI) Saves_registers_on_quitting(t *T) {
+	fx, init := tmpFX(t)
+	vw := fx.Root().(*view.View)
+	vw.Registers.Copied("gini")
+	path := filepath.Join(init.Log.Env.Conf(), RegistersFile)
+	_, err := os.Stat(path)
+	t.True(os.IsNotExist(err))
+	fx.Lines.Quit()
+	t.Eq([]string{"gini"}, newRegisters(&init.Log).Copies())
 }
 
 func TestGINI(t *testing.T) {
@@ -0,0 +1,102 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/slukits/gini/cmd/gini/view"
+	"github.com/slukits/gini/pkg/ftype"
+)
+
+// typeOf returns the file type of given buffer b detected by its file's
+// path and its content.
+func (c *config) typeOf(b view.Buffer) *ftype.Type {
+	return c.types.Detect(filePath(b), head(b))
+}
+
+// head returns the leading lines of given buffer b which are needed to
+// detect its file type.
+func head(b view.Buffer) []byte {
+	if buf, ok := b.(*buffer); b == nil || ok && buf.Buffer == nil {
+		return nil
+	}
+	head := strings.Builder{}
+	for ln := 0; ln < b.Lines() && head.Len() < ftype.SniffLen; ln++ {
+		head.WriteString(b.Line(ln))
+		head.WriteByte('\n')
+	}
+	return []byte(head.String())
+}
+
+// Commands returns the commands of the file type of given buffer b
+// which replace b's content or report about it.
+func (c *config) Commands(b view.Buffer) (cc []view.FileTypeCmd) {
+	buf, ok := b.(*buffer)
+	if !ok {
+		return nil
+	}
+	t := c.typeOf(b)
+	for _, cmd := range t.Commands {
+		cmd := cmd
+		cc = append(cc, view.FileTypeCmd{Label: cmd.Name, Key: cmd.Key,
+			Exec: func() (string, error) { return c.exec(buf, t, cmd) }})
+	}
+	return cc
+}
+
+// exec executes given command cmd of given file type t on given buffer
+// b whose content is replaced by the command's result if any.
+func (c *config) exec(
+	b *buffer, t *ftype.Type, cmd ftype.Command,
+) (string, error) {
+	src, path := []byte(b.String()), filePath(b)
+	replace, msg, err := cmd.Exec(src, func(name string) interface{} {
+		v, err := c.ss.Value(t.Setting(name), path)
+		if err != nil {
+			return nil
+		}
+		return v.Value
+	})
+	if err != nil {
+		return "", err
+	}
+	if replace == nil || bytes.Equal(replace, src) {
+		return msg, nil
+	}
+	return msg, b.replace(string(replace))
+}
+
+// replace replaces given buffer b's content by given string s in one
+// undo step.
+func (b *buffer) replace(s string) error {
+	b.Editing(false)
+	b.Editing(true)
+	defer b.Editing(false)
+	n := utf8.RuneCountInString(b.String())
+	if _, err := b.Delete(0, 0, n); err != nil {
+		return err
+	}
+	return b.Insert(0, 0, s)
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package controller
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/slukits/gini/cmd/gini/model"
+	"github.com/slukits/gini/cmd/gini/view"
+	"github.com/slukits/gini/pkg/ftype"
+	. "github.com/slukits/gounit"
+)
+
+type FileTypes struct{ Suite }
+
+func (s *FileTypes) SetUp(t *T) { t.Parallel() }
+
+// typedFX returns a buffer of a file with given name and content src
+// having a config.
+func typedFX(t *T, name, src string) *buffer {
+	f := fileFX(t)
+	f.Path = filepath.Join(filepath.Dir(f.Path), name)
+	return &buffer{Buffer: model.NewBuffer([]byte(src)), file: f,
+		config: newConfig(f.Log)}
+}
+
+// labels returns the labels of given commands cc.
+func labels(cc []view.FileTypeCmd) (ll []string) {
+	for _, c := range cc {
+		ll = append(ll, c.Label)
+	}
+	return ll
+}
+
+func (s *FileTypes) Of_buffers_are_detected(t *T) {
+	b := typedFX(t, "script", "#!/bin/sh\necho gini")
+	t.Eq("sh", b.config.typeOf(b).Name)
+	b = typedFX(t, "a.json", "")
+	t.Eq("json", b.config.typeOf(b).Name)
+	t.True(b.config.typeOf(&buffer{}) == ftype.Text)
+}
+
+func (s *FileTypes) Provide_the_commands_of_a_buffers_type(t *T) {
+	b := typedFX(t, "a.json", `{"a":1}`)
+	t.Eq([]string{"format", "check"}, labels(b.config.Commands(b)))
+	t.Eq(0, len(b.config.Commands(typedFX(t, "a.txt", "gini"))))
+}
+
+func (s *FileTypes) Replace_content_in_one_undo_step(t *T) {
+	b := typedFX(t, "a.json", `{"a":1}`)
+	msg, err := b.config.Commands(b)[0].Exec()
+	t.FatalOn(err)
+	t.Eq("formatted", msg)
+	t.Eq("{\n  \"a\": 1\n}\n", b.String())
+	t.True(b.Modified())
+	_, _, ok := b.Undo()
+	t.True(ok)
+	t.Eq(`{"a":1}`, b.String())
+	msg, err = b.config.Commands(b)[1].Exec()
+	t.FatalOn(err)
+	t.Eq("no syntax errors", msg)
+}
+
+func (s *FileTypes) Use_type_specific_settings(t *T) {
+	b := typedFX(t, "a.json", `{"a":1}`)
+	name := "json " + ftype.IndentSetting
+	t.Contains(settingNames(b.config.Settings(b)), name)
+	t.Not.Contains(settingNames(b.config.Settings(&buffer{})), name)
+	t.FatalOn(b.config.Set(b, name, "0", "file"))
+	_, err := b.config.Commands(b)[0].Exec()
+	t.FatalOn(err)
+	t.Eq("{\n\"a\": 1\n}\n", b.String())
+}
+
+// settingNames returns the names of given settings ss joined by commas.
+func settingNames(ss []view.Setting) (s string) {
+	for _, st := range ss {
+		s += st.Name + ","
+	}
+	return s
+}
+
+func TestFileTypes(t *testing.T) {
+	t.Parallel()
+	Run(&FileTypes{}, t)
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/slukits/gini/cmd/gini/model"
+	"github.com/slukits/gini/cmd/gini/view"
+	"github.com/slukits/gini/pkg/dir"
+	"github.com/slukits/gini/pkg/env"
+	"github.com/slukits/gini/pkg/file"
+	"github.com/slukits/gini/pkg/grep"
+	"github.com/slukits/gini/pkg/lg"
+)
+
+// errNotFiled is returned by a grepper if the current file is grepped
+// while the grepped buffer isn't backed by a file.
+var errNotFiled = errors.New("gini: controller: grep: buffer has no file")
+
+// grepper implements the view's Grepper searching files with the grep
+// package.  Opened files are kept as buffers by their path, i.e. a file
+// is opened only once and replacements modify its open buffer.
+type grepper struct {
+	log     *lg.Logger
+	config  *config
+	buffers map[string]*buffer
+}
+
+// newGrepper returns a grepper knowing given file buffers bb whose
+// opened buffers are saved with the settings of given config.
+func newGrepper(lgg *lg.Logger, cfg *config, bb ...*buffer) *grepper {
+	g := &grepper{log: lgg, config: cfg, buffers: map[string]*buffer{}}
+	for _, b := range bb {
+		if b.file != nil {
+			g.buffers[b.file.Path] = b
+		}
+	}
+	return g
+}
+
+// Grep greps the file of given buffer b, its directory or the project
+// containing it according to given search s's scope.  If b has no file
+// its directory defaults to the working directory.  A project is the
+// repository containing the directory or the directory itself.  The
+// search stops once given context ctx is canceled.  Files with an open
+// buffer having unsaved modifications are grepped by their buffer's
+// content.
+func (g *grepper) Grep(
+	ctx context.Context, b view.Buffer, s view.Search,
+) (<-chan view.Match, error) {
+	re, err := s.Compile()
+	if err != nil {
+		return nil, err
+	}
+	path, recursive := filePath(b), false
+	switch s.Scope {
+	case view.FileScope:
+		if path == "" {
+			return nil, errNotFiled
+		}
+	case view.DirScope:
+		path = g.dir(path)
+	case view.ProjectScope:
+		path, recursive = g.dir(path), true
+		if repo, ok := (&dir.Dir{Log: g.log, Path: path}).Repo(); ok {
+			path = repo.String()
+		}
+	}
+	gp, unsaved := &grep.Grep{Log: g.log}, g.unsaved()
+	gp.Lib.ReadFile = func(path string) ([]byte, error) {
+		if bb, ok := unsaved[path]; ok {
+			return bb, nil
+		}
+		return os.ReadFile(path)
+	}
+	mm, vmm := gp.Search(ctx, re, recursive, path), make(chan view.Match)
+	go func() {
+		defer close(vmm)
+		files := map[string]string{}
+		for m := range mm {
+			if _, ok := files[m.Path]; !ok {
+				files[m.Path] = repoPath(g.log, m.Path)
+			}
+			select {
+			case vmm <- view.Match{Path: m.Path, File: files[m.Path],
+				Ln: m.Ln, Cl: m.Cl, Len: m.Len, Line: m.Line}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return vmm, nil
+}
+
+// unsaved returns the contents of the open buffers having unsaved
+// modifications by the paths of their files.
+func (g *grepper) unsaved() map[string][]byte {
+	bb := map[string][]byte{}
+	for path, b := range g.buffers {
+		if b.Modified() {
+			bb[path] = []byte(b.String())
+		}
+	}
+	return bb
+}
+
+// dir returns the directory of given file path or the working
+// directory if path is empty.
+func (g *grepper) dir(path string) string {
+	if path != "" {
+		return filepath.Dir(path)
+	}
+	if g.log.Env == nil {
+		g.log.Env = &env.Env{}
+	}
+	return g.log.Env.WD()
+}
+
+// Open returns the buffer of the file containing given match m which is
+// loaded if it isn't already open.
+func (g *grepper) Open(m view.Match) (view.Buffer, error) {
+	return g.open(m.Path)
+}
+
+func (g *grepper) open(path string) (*buffer, error) {
+	if b, ok := g.buffers[path]; ok {
+		return b, nil
+	}
+	f := &file.File{Log: g.log, Path: path}
+	bb, err := f.Load()
+	if err != nil {
+		return nil, err
+	}
+	b := &buffer{Buffer: model.NewBuffer(bb), file: f, config: g.config}
+	g.buffers[path] = b
+	return b, nil
+}
+
+// Replace replaces in the lines of given matches mm all matches of given
+// search s by given replacement.  The replacements of a file are one
+// undo step of its buffer which is left unsaved for the user to review,
+// undo or save the replacements.
+func (g *grepper) Replace(
+	s view.Search, mm []view.Match, replacement string,
+) (int, error) {
+	r, err := s.Replacer(replacement)
+	if err != nil {
+		return 0, err
+	}
+	paths, files := []string{}, map[string][]view.Match{}
+	for _, m := range mm {
+		if _, ok := files[m.Path]; !ok {
+			paths = append(paths, m.Path)
+		}
+		files[m.Path] = append(files[m.Path], m)
+	}
+	n := 0
+	for _, p := range paths {
+		b, err := g.open(p)
+		if err != nil {
+			return n, err
+		}
+		b.Editing(true)
+		replaced, err := view.ReplaceIn(b, files[p], r)
+		b.Editing(false)
+		n += replaced
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
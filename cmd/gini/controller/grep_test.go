@@ -0,0 +1,164 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/slukits/gini/cmd/gini/view"
+	"github.com/slukits/gini/pkg/file"
+	"github.com/slukits/gini/pkg/lg"
+	. "github.com/slukits/gounit"
+)
+
+type Grepper struct{ Suite }
+
+func (s *Grepper) SetUp(t *T) { t.Parallel() }
+
+// ctx is the context of greps which aren't canceled.
+var ctx = context.Background()
+
+// grepFX returns a grepper and the buffer of the file a.txt in a
+// temporary project having a .git directory, the files a.txt and b.txt
+// and a sub-directory with the file c.txt.
+func grepFX(t *T) (*grepper, *buffer, string) {
+	lgg := registersFX(t)
+	d := t.FS().Tmp()
+	d.Mk(".git")
+	d.MkFile("a.txt", []byte("gini is\nnot an ide"))
+	d.MkFile("b.txt", []byte("gini"))
+	sub, _ := d.Mk("sub")
+	sub.MkFile("c.txt", []byte("a gini"))
+	g := newGrepper(lgg, nil)
+	b, err := g.open(filepath.Join(d.Path(), "a.txt"))
+	t.FatalOn(err)
+	return g, b, d.Path()
+}
+
+// matches returns given matches mm sorted by their displayed file.
+func matches(mm <-chan view.Match, err error) (ss []view.Match) {
+	if err != nil {
+		panic(err)
+	}
+	for m := range mm {
+		ss = append(ss, m)
+	}
+	sort.Slice(ss, func(i, j int) bool { return ss[i].File < ss[j].File })
+	return ss
+}
+
+func (s *Grepper) Greps_file_directory_or_project_of_a_buffer(t *T) {
+	g, b, _ := grepFX(t)
+	got := matches(g.Grep(ctx, b, view.Search{Text: "gini"}))
+	t.FatalIfNot(t.Eq(1, len(got)))
+	t.Eq("a.txt", got[0].File)
+	got = matches(g.Grep(ctx, b, view.Search{Text: "gini",
+		Scope: view.DirScope}))
+	t.Eq(2, len(got))
+	got = matches(g.Grep(ctx, b, view.Search{Text: "g.n",
+		Regexp: true, Scope: view.ProjectScope}))
+	t.FatalIfNot(t.Eq(3, len(got)))
+	t.Eq(filepath.Join("sub", "c.txt"), got[2].File)
+	t.Eq(2, got[2].Cl)
+}
+
+func (s *Grepper) Fails_to_grep_current_file_of_unfiled_buffer(t *T) {
+	g := newGrepper(registersFX(t), nil)
+	_, err := g.Grep(ctx, &buffer{}, view.Search{Text: "gini"})
+	t.ErrIs(err, errNotFiled)
+}
+
+func (s *Grepper) Opens_a_file_only_once(t *T) {
+	g, b, root := grepFX(t)
+	got, err := g.Open(view.Match{Path: filepath.Join(root, "a.txt")})
+	t.FatalOn(err)
+	t.True(got == view.Buffer(b))
+	_, err = g.Open(view.Match{Path: filepath.Join(root, "x.txt")})
+	t.ErrIs(err, fs.ErrNotExist)
+}
+
+func (s *Grepper) Replaces_without_saving(t *T) {
+	g, b, root := grepFX(t)
+	t.FatalOn(b.Insert(1, 0, "really "))
+	mm := matches(g.Grep(ctx, b, view.Search{Text: "gini",
+		Scope: view.DirScope}))
+	n, err := g.Replace(view.Search{Text: "gini"}, mm, "GINI")
+	t.FatalOn(err)
+	t.Eq(2, n)
+	t.Eq("GINI is\nreally not an ide", b.String())
+	other, err := g.open(filepath.Join(root, "b.txt"))
+	t.FatalOn(err)
+	t.Eq("GINI", other.String())
+	t.True(other.Modified())
+	for _, name := range []string{"a.txt", "b.txt"} {
+		bb, err := os.ReadFile(filepath.Join(root, name))
+		t.FatalOn(err)
+		t.Contains(string(bb), "gini")
+	}
+	b.Undo()
+	t.Eq("gini is\nreally not an ide", b.String())
+}
+
+func (s *Grepper) Greps_unsaved_buffers_by_their_content(t *T) {
+	g, b, _ := grepFX(t)
+	t.FatalOn(b.Insert(1, 0, "gini "))
+	mm := matches(g.Grep(ctx, b, view.Search{Text: "gini"}))
+	t.FatalIfNot(t.Eq(2, len(mm)))
+	t.Eq("gini not an ide", mm[1].Line)
+}
+
+func (s *Grepper) Knows_given_file_buffers(t *T) {
+	lgg := &lg.Logger{}
+	b := &buffer{file: &file.File{Log: lgg, Path: "/gini/a.txt"}}
+	got, err := newGrepper(lgg, nil, b).Open(view.Match{Path: "/gini/a.txt"})
+	t.FatalOn(err)
+	t.True(got == view.Buffer(b))
+}
+
+func (s *Grepper) Stops_grepping_if_canceled(t *T) {
+	g, b, dir := grepFX(t)
+	for i := 0; i < 100; i++ {
+		t.FatalOn(os.WriteFile(filepath.Join(dir, fmt.Sprintf(
+			"%d.txt", i)), []byte("gini"), 0644))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	mm, err := g.Grep(ctx, b, view.Search{Text: "gini",
+		Scope: view.ProjectScope})
+	t.FatalOn(err)
+	cancel()
+	n := 0
+	for range mm {
+		n++
+	}
+	t.True(n < 100)
+}
+
+func TestGrepper(t *testing.T) {
+	t.Parallel()
+	Run(&Grepper{}, t)
+}
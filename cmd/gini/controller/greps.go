@@ -0,0 +1,102 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package controller
+
+import (
+	"strings"
+
+	"github.com/slukits/gini/cmd/gini/model"
+	"github.com/slukits/gini/cmd/gini/view"
+	"github.com/slukits/gini/pkg/lg"
+)
+
+// GrepsFile is the file in the config directory which persists the
+// greps saved under hotkeys across sessions.
+const GrepsFile = "greps.json"
+
+// greps implement the view's SavedGreps keeping greps by their hotkeys
+// which are saved to GrepsFile in the config directory on each
+// modification.  Greps are persisted by their text representation (see
+// view.SavedGrep.String).
+type greps struct {
+	log *lg.Logger
+	gg  map[rune]view.Search
+}
+
+// newGreps returns saved greps loaded from the config directory of
+// given logger lgg's environment.  Load and parse errors are logged to
+// lg.ERR.
+func newGreps(lgg *lg.Logger) *greps {
+	gg, tt := &greps{log: lgg, gg: map[rune]view.Search{}}, []string{}
+	if !loadConf(lgg, GrepsFile, &tt) {
+		return gg
+	}
+	saved, err := view.ParseSavedGreps(strings.Join(tt, "\n"))
+	if err != nil {
+		lgg.Tof(lg.ERR, "gini: controller: greps: %v", err)
+		return gg
+	}
+	for _, g := range saved {
+		gg.gg[g.Key] = g.Search
+	}
+	return gg
+}
+
+// Saved saves given search s under given hotkey k.
+func (gg *greps) Saved(k rune, s view.Search) {
+	gg.gg[k] = s
+	gg.save()
+}
+
+// Greps returns the saved greps sorted by their hotkeys.
+func (gg *greps) Greps() []view.SavedGrep { return view.SortedGreps(gg.gg) }
+
+// Buffer returns the text of the saved greps whose saving replaces the
+// saved greps by the parsed text.
+func (gg *greps) Buffer() view.Buffer {
+	b := &textBuffer{Buffer: model.NewBuffer(
+		[]byte(strings.Join(gg.texts(), "\n")))}
+	b.save = func(text string) error {
+		saved, err := view.ParseSavedGreps(text)
+		if err != nil {
+			return err
+		}
+		gg.gg = map[rune]view.Search{}
+		for _, g := range saved {
+			gg.gg[g.Key] = g.Search
+		}
+		gg.save()
+		return nil
+	}
+	return b
+}
+
+func (gg *greps) save() { saveConf(gg.log, GrepsFile, gg.texts()) }
+
+// texts returns the text representations of the saved greps.
+func (gg *greps) texts() []string {
+	tt := []string{}
+	for _, g := range gg.Greps() {
+		tt = append(tt, g.String())
+	}
+	return tt
+}
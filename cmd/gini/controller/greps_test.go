@@ -0,0 +1,78 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/slukits/gini/cmd/gini/view"
+	"github.com/slukits/gini/pkg/lg"
+	. "github.com/slukits/gounit"
+)
+
+type Greps struct{ Suite }
+
+func (s *Greps) SetUp(t *T) { t.Parallel() }
+
+func (s *Greps) Persist_across_sessions(t *T) {
+	lgg := registersFX(t)
+	gg := newGreps(lgg)
+	gg.Saved('x', view.Search{Text: "g.*i", Regexp: true,
+		Scope: view.ProjectScope})
+	gg.Saved('a', view.Search{Text: "gini"})
+	gg.Saved('x', view.Search{Text: "ide", Scope: view.DirScope})
+	t.Eq([]view.SavedGrep{
+		{Key: 'a', Search: view.Search{Text: "gini"}},
+		{Key: 'x', Search: view.Search{Text: "ide",
+			Scope: view.DirScope}},
+	}, newGreps(lgg).Greps())
+}
+
+func (s *Greps) Are_edited_as_text(t *T) {
+	lgg := registersFX(t)
+	gg := newGreps(lgg)
+	gg.Saved('a', view.Search{Text: "gini"})
+	b := gg.Buffer()
+	t.Eq("a grep file 'gini'", b.Line(0))
+	t.FatalOn(b.Insert(0, 0, "b grep dir re 'i+'\n"))
+	t.FatalOn(b.(view.Saver).Save())
+	got := newGreps(lgg).Greps()
+	t.FatalIfNot(t.Eq(2, len(got)))
+	t.Eq(view.Search{Text: "i+", Regexp: true, Scope: view.DirScope},
+		got[1].Search)
+}
+
+func (s *Greps) Are_not_saved_if_invalid(t *T) {
+	lgg := registersFX(t)
+	gg := newGreps(lgg)
+	gg.Saved('a', view.Search{Text: "gini"})
+	b := gg.Buffer()
+	t.FatalOn(b.Insert(0, 0, "bogus\n"))
+	t.ErrIs(b.(view.Saver).Save(), view.ErrSearch)
+	t.Eq(1, len(newGreps(lgg).Greps()))
+	t.Eq("", lgg.String(lg.ERR))
+}
+
+func TestGreps(t *testing.T) {
+	t.Parallel()
+	Run(&Greps{}, t)
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/slukits/gini/cmd/gini/view"
+	"github.com/slukits/gini/hlp"
+	"github.com/slukits/gini/pkg/gnh"
+	"github.com/slukits/gini/pkg/lg"
+	"github.com/slukits/lines"
+)
+
+// errSection is returned by a helper for the anchor of a section which
+// isn't found in its help page.
+var errSection = errors.New("gini: controller: help: no section")
+
+// helper implements the view's Helper providing the sections of GINI's
+// help pages.  A help page is loaded once and kept as buffer by its
+// name, i.e. a page shown in the help context is the same buffer as the
+// page opened for editing.
+type helper struct {
+	log    *lg.Logger
+	config *config
+	pages  map[string]*buffer
+}
+
+// newHelper returns a helper knowing given buffer of the index page
+// whose loaded pages are saved with the settings of given config.
+func newHelper(lgg *lg.Logger, cfg *config, index *buffer) *helper {
+	return &helper{log: lgg, config: cfg,
+		pages: map[string]*buffer{hlp.Index: index}}
+}
+
+// Help returns the buffer of the help page of given anchor (see
+// hlp.Anchor) and the line of the heading whose title matches the
+// anchor's section ignoring the case.  An anchor without section
+// refers to the beginning of its page.
+func (h *helper) Help(anchor string) (view.Buffer, int, error) {
+	page, section := hlp.Anchor(anchor)
+	b, ok := h.pages[page]
+	if !ok {
+		var err error
+		if b, err = helpBuffer(h.log, h.config, page); err != nil {
+			return nil, 0, err
+		}
+		h.pages[page] = b
+	}
+	if section == "" {
+		return b, 0, nil
+	}
+	for ln := 0; ln < b.Lines(); ln++ {
+		title, ok := hlp.Heading(b.Line(ln))
+		if ok && strings.EqualFold(title, section) {
+			return b, ln, nil
+		}
+	}
+	return nil, 0, fmt.Errorf("%w: %s", errSection, anchor)
+}
+
+// pageStyles are the styles of a help page's nodes by their kind.
+var pageStyles = map[gnh.Kind]lines.Style{
+	gnh.Heading:  lines.DefaultStyle.WithAA(lines.Bold),
+	gnh.Emphasis: lines.DefaultStyle.WithAA(lines.Bold),
+	gnh.Key:      lines.DefaultStyle.WithAA(lines.Reverse),
+	gnh.Link:     lines.DefaultStyle.WithAA(lines.Underline),
+}
+
+// parsed returns the document tree of given buffer b's help page or nil
+// if b doesn't hold a help page.
+func (b *buffer) parsed() *gnh.Node {
+	if b.page == "" {
+		return nil
+	}
+	if b.doc == nil {
+		b.doc = gnh.Parse(b.String())
+	}
+	return b.doc
+}
+
+// pageSpans returns the spans of headings, emphases, keys and links of
+// the line with given index ln of a help page.
+func (b *buffer) pageSpans(ln int) (ss []view.Span) {
+	doc := b.parsed()
+	if doc == nil {
+		return nil
+	}
+	for _, block := range doc.Children {
+		if ln < block.Ln || ln > block.EndLn {
+			continue
+		}
+		block.Walk(func(n *gnh.Node) {
+			sty, ok := pageStyles[n.Kind]
+			if !ok || n.Ln != ln {
+				return
+			}
+			ss = append(ss, view.Span{Start: n.Cl, End: n.EndCl,
+				Style: sty})
+		})
+	}
+	return ss
+}
+
+// Link returns the target of the link at given position of a help page
+// whereas a target without page refers to the buffer's page.
+func (b *buffer) Link(ln, cl int) (string, bool) {
+	doc := b.parsed()
+	if doc == nil {
+		return "", false
+	}
+	n := doc.At(ln, cl)
+	if n == nil || n.Kind != gnh.Link {
+		return "", false
+	}
+	if strings.HasPrefix(n.Target, "#") || n.Target == "" {
+		return b.page + n.Target, true
+	}
+	return n.Target, true
+}
+
+// Index returns the headings of a help page.
+func (b *buffer) Index() (hh []view.Heading) {
+	doc := b.parsed()
+	if doc == nil {
+		return nil
+	}
+	for _, h := range doc.Headings() {
+		hh = append(hh, view.Heading{Title: h.Text, Level: h.Level,
+			Ln: h.Ln})
+	}
+	return hh
+}
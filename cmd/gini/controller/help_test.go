@@ -0,0 +1,122 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package controller
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/slukits/gini/cmd/gini/model"
+	"github.com/slukits/gini/cmd/gini/view"
+	"github.com/slukits/gini/hlp"
+	"github.com/slukits/gini/pkg/env"
+	"github.com/slukits/gini/pkg/gnh"
+	"github.com/slukits/gini/pkg/lg"
+	. "github.com/slukits/gounit"
+)
+
+type Helper struct{ Suite }
+
+func (s *Helper) SetUp(t *T) { t.Parallel() }
+
+// helperFX returns a helper in a temporary environment whose working
+// directory is outside GINI's repository, i.e. it provides the embedded
+// help pages.
+func helperFX(t *T) *helper {
+	lgg := &lg.Logger{Env: (&env.Env{}).SetHome(t.FS().Tmp().Path())}
+	lgg.Env.Lib.Chdir = func(path string) error { return nil }
+	t.FatalOn(lgg.Env.ChWD(lgg.Env.Home()))
+	index, err := helpBuffer(lgg, nil, hlp.Index)
+	t.FatalOn(err)
+	return newHelper(lgg, nil, index)
+}
+
+func (s *Helper) Provides_the_sections_of_help_pages(t *T) {
+	h := helperFX(t)
+	b, ln, err := h.Help(view.ContextsHelp + "Copy and Delete")
+	t.FatalOn(err)
+	t.Eq("## copy and delete", b.Line(ln))
+	again, _, err := h.Help(view.ContextsHelp + "lines")
+	t.FatalOn(err)
+	t.True(b == again)
+	b, ln, err = h.Help("")
+	t.FatalOn(err)
+	t.True(b == h.pages[hlp.Index])
+	t.Eq(0, ln)
+}
+
+func (s *Helper) Fails_for_unknown_sections_and_pages(t *T) {
+	h := helperFX(t)
+	_, _, err := h.Help(view.ContextsHelp + "unknown")
+	t.ErrIs(err, errSection)
+	_, _, err = h.Help("unknown.gnh#lines")
+	t.ErrIs(err, fs.ErrNotExist)
+}
+
+func (s *Helper) Has_a_section_for_each_context_bar_entry(t *T) {
+	fx, _ := tmpFX(t)
+	vw := fx.Root().(*view.View)
+	for _, r := range " .h" {
+		fx.FireRune(r)
+	}
+	for _, r := range "lcm*/#." {
+		fx.FireRune(' ')
+		fx.FireRune(r)
+		t.Not.Contains(fx.ScreenOf(vw.Context()), "help:")
+		fx.FireRune(' ')
+	}
+	help := vw.Columns().Column(0).Split(1)
+	t.True(strings.HasPrefix(fx.ScreenOf(help).String(), "## app"))
+}
+
+func (s *Helper) Indexes_styles_and_links_help_pages(t *T) {
+	b := &buffer{Buffer: model.NewBuffer([]byte(
+		"# see\n\nsee [lines](#lines) or [mode](contexts.gnh#mode)")),
+		page: "page.gnh"}
+	t.Eq([]view.Heading{{Title: "see", Level: 1, Ln: 0}}, b.Index())
+	t.Eq([]view.Span{{Start: 0, End: 5,
+		Style: pageStyles[gnh.Heading]}}, b.Styles(0))
+	t.Eq(2, len(b.Styles(2)))
+	target, ok := b.Link(2, 4)
+	t.True(ok)
+	t.Eq("page.gnh#lines", target)
+	target, _ = b.Link(2, 30)
+	t.Eq("contexts.gnh#mode", target)
+	_, ok = b.Link(2, 0)
+	t.Not.True(ok)
+	t.FatalOn(b.Insert(0, 0, "#"))
+	t.Eq(2, b.Index()[0].Level)
+}
+
+func (s *Helper) Ignores_buffers_without_help_page(t *T) {
+	b := &buffer{Buffer: model.NewBuffer([]byte("# see [x](y)"))}
+	t.Eq(0, len(b.Index()))
+	t.Eq(0, len(b.Styles(0)))
+	_, ok := b.Link(0, 7)
+	t.Not.True(ok)
+}
+
+func TestHelper(t *testing.T) {
+	t.Parallel()
+	Run(&Helper{}, t)
+}
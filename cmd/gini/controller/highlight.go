@@ -0,0 +1,159 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package controller
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/slukits/gini/cmd/gini/view"
+	"github.com/slukits/gini/pkg/ftype"
+	"github.com/slukits/gini/pkg/lex"
+	"github.com/slukits/gini/pkg/lg"
+)
+
+// Styles returns the styled spans of the line with given index ln which
+// are the headings, emphases, keys and links of a help page or the
+// tokens of a lexed file styled by the theme.  The line of a file whose
+// type has no lexer is plain text.  Misspelled words are styled on top
+// (see spell.go).
+func (b *buffer) Styles(ln int) []view.Span {
+	if b.page != "" {
+		return b.pageSpans(ln)
+	}
+	return overlaid(b.tokenStyles(ln), b.misspelledStyles(ln))
+}
+
+// overlaid returns given spans ss with given spans top on top of them.
+// The parts of ss which are covered by top are cut out since a line
+// isn't styled reliably by overlapping spans.  Both ss and top must be
+// ordered and free of overlaps.
+func overlaid(ss, top []view.Span) []view.Span {
+	if len(top) == 0 {
+		return ss
+	}
+	out := make([]view.Span, 0, len(ss)+len(top))
+	for _, s := range ss {
+		start := s.Start
+		for _, t := range top {
+			if t.End <= start || t.Start >= s.End {
+				continue
+			}
+			if t.Start > start {
+				out = append(out, view.Span{Start: start, End: t.Start,
+					Style: s.Style})
+			}
+			start = t.End
+		}
+		if start < s.End {
+			out = append(out, view.Span{Start: start, End: s.End,
+				Style: s.Style})
+		}
+	}
+	out = append(out, top...)
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Start < out[j].Start
+	})
+	return out
+}
+
+// tokenStyles returns the spans of the tokens of the line with given
+// index ln whose class is styled by the theme.
+func (b *buffer) tokenStyles(ln int) (ss []view.Span) {
+	x := b.lexedLines()
+	if x == nil {
+		return nil
+	}
+	for _, t := range x.Tokens(ln) {
+		sty, ok := b.config.theme[t.Class]
+		if !ok {
+			continue
+		}
+		ss = append(ss, view.Span{Start: t.Cl, End: t.Cl + t.Len,
+			Style: sty})
+	}
+	return ss
+}
+
+// lexedLines returns given buffer b's lines lexed by the lexer of its
+// file type on first request.  It is nil for a help page or a buffer
+// whose file type has no lexer.
+func (b *buffer) lexedLines() *lex.Lexed {
+	if b.lexed != nil || !b.detect() {
+		return b.lexed
+	}
+	b.lexed = b.lexer.Lex(b.lines(0, b.Lines()))
+	return b.lexed
+}
+
+// detect detects the lexer of given buffer b's file type once and
+// returns false if there is none.
+func (b *buffer) detect() bool {
+	if b.typed {
+		return b.lexer != nil
+	}
+	b.typed = true
+	if b.config == nil || b.Buffer == nil || b.page != "" {
+		return false
+	}
+	l, err := b.config.typeOf(b).Lexer()
+	if err != nil && !errors.Is(err, ftype.ErrNoLexer) && b.file != nil {
+		b.file.Log.Tof(lg.ERR, "%v", err)
+	}
+	b.lexer = l
+	return l != nil
+}
+
+// relex re-lexes given number of added lines starting at given line ln
+// which replaced given number of removed lines.  Only the touched lines
+// are re-lexed and following lines as long as their lexer state
+// changed.  Since an edit of the first line may change the file type,
+// e.g. by a shebang, the type is detected anew in this case and b is
+// lexed anew on the next request if its lexer changed.
+func (b *buffer) relex(ln, removed, added int) {
+	if ln == 0 && b.typed {
+		l := b.lexer
+		b.typed = false
+		if b.detect(); b.lexer != l {
+			b.lexed = nil
+			return
+		}
+	}
+	if b.lexed == nil {
+		return
+	}
+	b.lexed.Update(ln, removed, b.lines(ln, added))
+}
+
+// unlex resets given buffer b's lexed lines and its detected lexer,
+// e.g. after an undo step, to have b lexed anew on the next request.
+func (b *buffer) unlex() { b.lexed, b.lexer, b.typed = nil, nil, false }
+
+// lines returns given number n of given buffer b's lines starting at
+// given line ln.
+func (b *buffer) lines(ln, n int) []string {
+	ll := make([]string, 0, n)
+	for i := ln; i < ln+n && i < b.Lines(); i++ {
+		ll = append(ll, b.Line(i))
+	}
+	return ll
+}
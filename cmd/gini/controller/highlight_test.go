@@ -0,0 +1,116 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/slukits/gini/cmd/gini/model"
+	"github.com/slukits/gini/cmd/gini/view"
+	"github.com/slukits/gini/pkg/lg"
+	. "github.com/slukits/gounit"
+	"github.com/slukits/lines"
+)
+
+type Highlighting struct{ Suite }
+
+func (s *Highlighting) SetUp(t *T) { t.Parallel() }
+
+const goFX = "package gini\n\nvar s = \"gini\"\n// gini\n"
+
+func (s *Highlighting) Styles_tokens_by_the_default_theme(t *T) {
+	vw := &view.View{Buffer: typedFX(t, "gini.go", goFX)}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	cc := fx.CellsOf(vw.Editor())
+	t.True(cc[0].HasAA(0, lines.Bold))
+	t.Not.True(cc[0].HasAA(8, lines.Bold))
+	t.True(cc[2].HasFG(8, lines.Green))
+	t.True(cc[3].HasFG(0, lines.Gray))
+}
+
+func (s *Highlighting) Falls_back_to_plain_text_without_lexer(t *T) {
+	b := typedFX(t, "gini.txt", "for gini\n// gini")
+	t.Eq(0, len(b.Styles(0)))
+	vw := &view.View{Buffer: b}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	t.Not.True(fx.CellsOf(vw.Editor())[0].HasAA(0, lines.Bold))
+}
+
+func (s *Highlighting) Re_lexes_only_edited_lines(t *T) {
+	b := typedFX(t, "gini.go", goFX)
+	x := b.lexedLines()
+	t.FatalOn(b.Insert(1, 0, "/*"))
+	t.True(x == b.lexedLines())
+	t.Eq("comment", x.Tokens(2)[0].Class)
+	_, err := b.Delete(1, 0, 2)
+	t.FatalOn(err)
+	t.Eq("keyword", x.Tokens(2)[0].Class)
+	t.FatalOn(b.Insert(2, 0, "\n"))
+	t.Eq("keyword", x.Tokens(3)[0].Class)
+	_, _, ok := b.Undo()
+	t.True(ok)
+	t.Not.True(x == b.lexedLines())
+}
+
+func (s *Highlighting) Updates_styles_of_typed_text(t *T) {
+	vw := &view.View{Buffer: typedFX(t, "gini.go", goFX)}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fx.FireRune('j')
+	fx.FireRune('i')
+	fx.FireRune('/')
+	fx.FireRune('/')
+	t.True(fx.CellsOf(vw.Editor())[1].HasFG(0, lines.Gray))
+}
+
+func (s *Highlighting) Detects_a_typed_shebang(t *T) {
+	b := typedFX(t, "script", "echo $HOME")
+	t.Eq(0, len(b.Styles(0)))
+	t.FatalOn(b.Insert(0, 0, "#!/bin/sh\n"))
+	t.True(b.Styles(1)[0].Style.FG() == lines.Purple)
+}
+
+func (s *Highlighting) Applies_the_theme_file(t *T) {
+	f := fileFX(t)
+	f.Path = filepath.Join(filepath.Dir(f.Path), "gini.go")
+	path := confPath(f.Log, ThemeFile)
+	t.FatalOn(os.MkdirAll(filepath.Dir(path), 0700))
+	t.FatalOn(os.WriteFile(path, []byte(`{
+		"keyword": {"fg": "#ff0000", "aa": ["underline"]},
+		"comment": {"fg": "nocolor"}}`), 0600))
+	b := &buffer{Buffer: model.NewBuffer([]byte(goFX)), file: f,
+		config: newConfig(f.Log)}
+	vw := &view.View{Buffer: b}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	cc := fx.CellsOf(vw.Editor())
+	t.True(cc[0].HasFG(0, lines.Red))
+	t.True(cc[0].HasAA(0, lines.Underline))
+	t.True(cc[2].HasFG(8, lines.Green))
+	t.Not.True(cc[3].HasFG(0, lines.Gray))
+	t.Contains(f.Log.String(lg.ERR), "nocolor")
+}
+
+func TestHighlighting(t *testing.T) {
+	t.Parallel()
+	Run(&Highlighting{}, t)
+}
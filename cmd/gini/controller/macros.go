@@ -0,0 +1,135 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package controller
+
+import (
+	"github.com/slukits/gini/cmd/gini/model"
+	"github.com/slukits/gini/cmd/gini/view"
+	ring "github.com/slukits/gini/pkg/collections"
+	"github.com/slukits/gini/pkg/lg"
+)
+
+// MacrosFile is the file in the config directory which persists the
+// recorded keyboard macros across sessions.
+const MacrosFile = "macros.json"
+
+// macros implement the view's Macros keeping recorded keyboard macros
+// in a ring which is saved to MacrosFile in the config directory on
+// each modification.  Macros are persisted by their text
+// representation (see view.Macro.String).
+type macros struct {
+	log  *lg.Logger
+	ring ring.Ring[view.Macro]
+}
+
+// newMacros returns macros loaded from the config directory of given
+// logger lgg's environment.  Load and parse errors are logged to
+// lg.ERR.
+func newMacros(lgg *lg.Logger) *macros {
+	mm, tt := &macros{log: lgg}, []string{}
+	if !loadConf(lgg, MacrosFile, &tt) {
+		return mm
+	}
+	for _, t := range tt {
+		m, err := view.ParseMacro(t)
+		if err != nil {
+			lgg.Tof(lg.ERR, "gini: controller: macros: %v", err)
+			continue
+		}
+		mm.ring.Add(m)
+	}
+	return mm
+}
+
+// Recorded adds given macro m as the latest macro.
+func (mm *macros) Recorded(m view.Macro) {
+	mm.ring.Add(m)
+	mm.save()
+}
+
+// Macros returns the kept macros latest first.
+func (mm *macros) Macros() (ss []view.Macro) {
+	mm.ring.For(func(_ int, m view.Macro) (stop bool) {
+		ss = append(ss, m)
+		return false
+	})
+	return ss
+}
+
+// Buffer returns the text of the i-th latest macro whose saving replaces
+// the macro by the parsed text which becomes the latest macro.  The
+// edited macro is looked up by its text on saving since its index
+// changes if a macro is recorded meanwhile.
+func (mm *macros) Buffer(i int) view.Buffer {
+	m, ok := mm.ring.At(i)
+	if !ok {
+		return nil
+	}
+	edited := m.String()
+	b := &textBuffer{Buffer: model.NewBuffer([]byte(edited))}
+	b.save = func(text string) error {
+		m, err := view.ParseMacro(text)
+		if err != nil {
+			return err
+		}
+		if i, ok := mm.index(edited); ok {
+			mm.ring.Remove(i)
+		}
+		mm.ring.Add(m)
+		edited = m.String()
+		mm.save()
+		return nil
+	}
+	return b
+}
+
+// index returns the index of the latest macro having given text
+// representation s and false if there is no such macro.
+func (mm *macros) index(s string) (idx int, ok bool) {
+	mm.ring.For(func(i int, m view.Macro) (stop bool) {
+		if m.String() != s {
+			return false
+		}
+		idx, ok = i, true
+		return true
+	})
+	return idx, ok
+}
+
+func (mm *macros) save() {
+	tt := []string{}
+	mm.ring.For(func(_ int, m view.Macro) (stop bool) {
+		tt = append([]string{m.String()}, tt...)
+		return false
+	})
+	saveConf(mm.log, MacrosFile, tt)
+}
+
+// textBuffer is the editable text of a configuration, e.g. a macro,
+// which is parsed and stored on saving.
+type textBuffer struct {
+	*model.Buffer
+	save func(string) error
+}
+
+// Save stores the parsed text.
+func (b *textBuffer) Save() error { return b.save(b.String()) }
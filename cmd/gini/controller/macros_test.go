@@ -0,0 +1,104 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	"github.com/slukits/gini/cmd/gini/view"
+	. "github.com/slukits/gounit"
+	"github.com/slukits/lines"
+)
+
+type Macros struct{ Suite }
+
+func (s *Macros) SetUp(t *T) { t.Parallel() }
+
+func macroFX(name string, rr ...rune) view.Macro {
+	m := view.Macro{Name: name}
+	for _, r := range rr {
+		m.Keys = append(m.Keys, view.Keystroke{Rune: r})
+	}
+	return m
+}
+
+func (s *Macros) Persist_across_sessions(t *T) {
+	lgg := registersFX(t)
+	mm := newMacros(lgg)
+	mm.Recorded(macroFX("first", 'a'))
+	mm.Recorded(view.Macro{Name: "second", Keys: []view.Keystroke{
+		{Key: lines.Enter}}})
+	got := newMacros(lgg).Macros()
+	t.FatalIfNot(t.Eq(2, len(got)))
+	t.Eq("second", got[0].Name)
+	t.Eq(lines.Enter, got[0].Keys[0].Key)
+	t.Eq("first", got[1].Name)
+}
+
+func (s *Macros) Are_edited_as_text(t *T) {
+	lgg := registersFX(t)
+	mm := newMacros(lgg)
+	mm.Recorded(macroFX("first", 'a'))
+	mm.Recorded(macroFX("second", 'b'))
+	b := mm.Buffer(1)
+	t.Eq("first", b.Line(0))
+	t.Eq("a", b.Line(1))
+	t.FatalOn(b.Insert(1, 1, "<enter>"))
+	t.FatalOn(b.(view.Saver).Save())
+	got := newMacros(lgg).Macros()
+	t.Eq("first", got[0].Name)
+	t.Eq(2, len(got[0].Keys))
+	t.Eq("second", got[1].Name)
+}
+
+func (s *Macros) Replace_the_edited_macro_if_recorded_meanwhile(t *T) {
+	lgg := registersFX(t)
+	mm := newMacros(lgg)
+	mm.Recorded(macroFX("first", 'a'))
+	mm.Recorded(macroFX("second", 'b'))
+	b := mm.Buffer(1)
+	mm.Recorded(macroFX("third", 'c'))
+	t.FatalOn(b.Insert(1, 1, "<enter>"))
+	t.FatalOn(b.(view.Saver).Save())
+	t.FatalOn(b.Insert(1, 0, "x"))
+	t.FatalOn(b.(view.Saver).Save())
+	got := newMacros(lgg).Macros()
+	t.FatalIfNot(t.Eq(3, len(got)))
+	t.Eq("first", got[0].Name)
+	t.Eq(3, len(got[0].Keys))
+	t.Eq("third", got[1].Name)
+	t.Eq("second", got[2].Name)
+}
+
+func (s *Macros) Are_not_saved_if_invalid(t *T) {
+	mm := newMacros(registersFX(t))
+	mm.Recorded(macroFX("first", 'a'))
+	b := mm.Buffer(0)
+	t.FatalOn(b.Insert(1, 1, "<bogus>"))
+	t.ErrIs(b.(view.Saver).Save(), view.ErrMacro)
+	t.Eq(1, len(mm.Macros()[0].Keys))
+}
+
+func TestMacros(t *testing.T) {
+	t.Parallel()
+	Run(&Macros{}, t)
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package controller
+
+import (
+	"github.com/slukits/gini/cmd/gini/view"
+	"github.com/slukits/gini/pkg/lg"
+)
+
+// RegistersFile is the file in the config directory which persists the
+// copy and delete rings across sessions.
+const RegistersFile = "registers.json"
+
+// registers implement the registers of the view's editors adding
+// persistence to the view's in-memory rings: they are loaded from
+// RegistersFile in the config directory and saved to it on quitting if
+// they were modified.
+type registers struct {
+	view.Rings
+	log      *lg.Logger
+	modified bool
+}
+
+// persisted is the json representation of registers whose rings are
+// listed oldest first.
+type persisted struct {
+	Copies    []string `json:"copies"`
+	Deletions []string `json:"deletions"`
+	Latest    string   `json:"latest"`
+}
+
+// newRegisters returns registers loaded from the config directory of
+// given logger lgg's environment.  Load errors are logged to lg.ERR.
+func newRegisters(lgg *lg.Logger) *registers {
+	r, p := &registers{log: lgg}, persisted{}
+	if !loadConf(lgg, RegistersFile, &p) {
+		return r
+	}
+	r.Restore(p.Copies, p.Deletions, p.Latest)
+	return r
+}
+
+// Copied adds given text s to the copy ring.
+func (r *registers) Copied(s string) {
+	r.Rings.Copied(s)
+	r.modified = true
+}
+
+// Deleted adds given text s to the delete ring.
+func (r *registers) Deleted(s string) {
+	r.Rings.Deleted(s)
+	r.modified = true
+}
+
+// save writes modified registers to RegistersFile logging errors to
+// lg.ERR.
+func (r *registers) save() {
+	if !r.modified {
+		return
+	}
+	r.modified = false
+	saveConf(r.log, RegistersFile, persisted{Latest: r.Latest(),
+		Copies: oldestFirst(r.Copies()), Deletions: oldestFirst(r.Deletions())})
+}
+
+// oldestFirst returns given entries ss of a ring in reversed order.
+func oldestFirst(ss []string) []string {
+	rr := make([]string, 0, len(ss))
+	for i := len(ss) - 1; i >= 0; i-- {
+		rr = append(rr, ss[i])
+	}
+	return rr
+}
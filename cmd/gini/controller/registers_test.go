@@ -0,0 +1,98 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/slukits/gini/pkg/env"
+	"github.com/slukits/gini/pkg/lg"
+	. "github.com/slukits/gounit"
+)
+
+type Registers struct{ Suite }
+
+func (s *Registers) SetUp(t *T) { t.Parallel() }
+
+func registersFX(t *T) *lg.Logger {
+	return &lg.Logger{Env: (&env.Env{}).SetHome(t.FS().Tmp().Path())}
+}
+
+func (s *Registers) Keep_copies_and_deletions_latest_first(t *T) {
+	r := newRegisters(registersFX(t))
+	r.Copied("gini")
+	r.Copied("is")
+	r.Deleted("not")
+	t.Eq([]string{"is", "gini"}, r.Copies())
+	t.Eq([]string{"not"}, r.Deletions())
+	t.Eq("not", r.Latest())
+}
+
+func (s *Registers) Persist_across_sessions(t *T) {
+	lgg := registersFX(t)
+	r := newRegisters(lgg)
+	r.Copied("gini")
+	r.Copied("is")
+	r.Deleted("not")
+	t.Eq(0, len(newRegisters(lgg).Copies()))
+	r.save()
+	r = newRegisters(lgg)
+	t.Eq([]string{"is", "gini"}, r.Copies())
+	t.Eq([]string{"not"}, r.Deletions())
+	t.Eq("not", r.Latest())
+}
+
+func (s *Registers) Are_saved_privately_replacing_their_file(t *T) {
+	lgg := registersFX(t)
+	r := newRegisters(lgg)
+	r.Copied("gini")
+	r.save()
+	r.Copied("is")
+	r.save()
+	path := filepath.Join(lgg.Env.Conf(), RegistersFile)
+	fi, err := os.Stat(path)
+	t.FatalOn(err)
+	t.Eq(os.FileMode(0600), fi.Mode().Perm())
+	ee, err := os.ReadDir(filepath.Dir(path))
+	t.FatalOn(err)
+	for _, e := range ee {
+		t.True(e.IsDir() || e.Name() == RegistersFile)
+	}
+	t.Eq([]string{"is", "gini"}, newRegisters(lgg).Copies())
+}
+
+func (s *Registers) Log_corrupted_registers_file(t *T) {
+	lgg := registersFX(t)
+	path := filepath.Join(lgg.Env.Conf(), RegistersFile)
+	t.FatalOn(os.MkdirAll(filepath.Dir(path), 0700))
+	t.FatalOn(os.WriteFile(path, []byte("{"), 0600))
+	r := newRegisters(lgg)
+	t.Eq(0, len(r.Copies()))
+	t.Contains(lgg.String(lg.ERR), "gini: controller: registers")
+}
+
+func TestRegisters(t *testing.T) {
+	t.Parallel()
+	Run(&Registers{}, t)
+}
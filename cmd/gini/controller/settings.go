@@ -0,0 +1,184 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/slukits/gini/cmd/gini/view"
+	"github.com/slukits/gini/pkg/file"
+	"github.com/slukits/gini/pkg/ftype"
+	"github.com/slukits/gini/pkg/lg"
+	"github.com/slukits/gini/pkg/settings"
+	"github.com/slukits/gini/pkg/spell"
+)
+
+const (
+
+	// BackupsSetting is the number of backups kept of a saved file.
+	BackupsSetting = "backups"
+
+	// BackupSizeSetting is the size in bytes from which on a saved file
+	// is not backed up.
+	BackupSizeSetting = "backup size"
+)
+
+// errScope is returned by a config for a scope which can't be set.
+var errScope = errors.New("gini: controller: settings: invalid scope")
+
+// config implements the view's Settings by settings which are resolved
+// for the file of a buffer and the view's FileTypes by the file types
+// of buffers (see ftype.go).
+type config struct {
+	ss    *settings.Settings
+	types *ftype.Registry
+
+	// owners are the file types of type specific settings by their
+	// names.
+	owners map[string]*ftype.Type
+
+	// theme styles the tokens of lexed buffers (see theme.go).
+	theme theme
+
+	// dictDirs are searched for the dictionaries of spell-checkers
+	// whose loaded word lists are kept by their paths in dicts (see
+	// spell.go).
+	dictDirs []string
+	dicts    map[string]*spell.Dict
+
+	// pipes are the started external spell-checkers by their command
+	// lines.
+	pipes map[string]*spell.Pipe
+
+	// spellings counts the changes of spelling settings which outdate
+	// the spell-checkers of all buffers.
+	spellings int
+}
+
+// newConfig returns a config registering gini's settings and the
+// settings of the builtin file types whose files are found in the
+// config directory of given logger lgg's environment or in the project
+// of a buffer's file.  The config's theme is loaded from the config
+// directory as well.
+func newConfig(lgg *lg.Logger) *config {
+	c := &config{ss: &settings.Settings{Log: lgg},
+		types: ftype.Builtin(), owners: map[string]*ftype.Type{},
+		theme: newTheme(lgg), dictDirs: spell.DictDirs,
+		dicts: map[string]*spell.Dict{},
+		pipes: map[string]*spell.Pipe{}}
+	for _, s := range []settings.Setting{
+		{Name: BackupsSetting, Kind: settings.Int,
+			Default: file.DefaultBackups, Doc: "number of kept backups",
+			Check: between(1, file.MaxBackups)},
+		{Name: BackupSizeSetting, Kind: settings.Int,
+			Default: file.DefaultMaxSize, Doc: "max size of backed up file",
+			Check: between(1, 1<<30)},
+		{Name: SpellingSetting, Kind: settings.String, Default: "en_US",
+			Doc: "dictionary language or off"},
+		{Name: SpellCheckerSetting, Kind: settings.String, Default: "",
+			Doc: "ispell pipe checker, e.g. aspell -a"},
+	} {
+		c.register(lgg, s, nil)
+	}
+	for _, t := range c.types.Types() {
+		for _, s := range t.Settings {
+			s.Name = t.Setting(s.Name)
+			c.register(lgg, s, t)
+		}
+	}
+	return c
+}
+
+// register registers given setting s of given file type t which is nil
+// for a setting of all files.
+func (c *config) register(lgg *lg.Logger, s settings.Setting, t *ftype.Type) {
+	if err := c.ss.Register(s); err != nil {
+		lgg.Tof(lg.ERR, "gini: controller: settings: %v", err)
+		return
+	}
+	if t != nil {
+		c.owners[s.Name] = t
+	}
+}
+
+// between returns a check of int values which must be in the range from
+// given min to given max.
+func between(min, max int) func(interface{}) error {
+	return func(v interface{}) error {
+		if i := v.(int); i < min || i > max {
+			return fmt.Errorf("must be between %d and %d", min, max)
+		}
+		return nil
+	}
+}
+
+// Settings returns the effective settings of given buffer b's file or
+// the global settings if it has no file.  Settings specific to a file
+// type are only returned for a buffer of this type.
+func (c *config) Settings(b view.Buffer) (ss []view.Setting) {
+	path, t := filePath(b), c.typeOf(b)
+	for _, s := range c.ss.Settings() {
+		if owner, ok := c.owners[s.Name]; ok && owner != t {
+			continue
+		}
+		v, err := c.ss.Value(s.Name, path)
+		if err != nil {
+			continue
+		}
+		ss = append(ss, view.Setting{Name: s.Name, Value: v.String(),
+			Origin: v.Scope.String(), Source: v.Source})
+	}
+	return ss
+}
+
+// Set sets the setting with given name to given value in the scope with
+// given name relative to given buffer b's file.
+func (c *config) Set(b view.Buffer, name, value, scope string) error {
+	s, ok := settings.ParseScope(scope)
+	path := filePath(b)
+	if !ok || path == "" && s != settings.Global {
+		return fmt.Errorf("%w: %s", errScope, scope)
+	}
+	if err := c.ss.Set(name, value, s, path); err != nil {
+		return err
+	}
+	if name == SpellingSetting || name == SpellCheckerSetting {
+		c.spellings++
+	}
+	return nil
+}
+
+// apply applies the settings of given file f's path to f.
+func (c *config) apply(f *file.File) {
+	f.Backups = c.ss.Int(BackupsSetting, f.Path)
+	f.MaxSize = int64(c.ss.Int(BackupSizeSetting, f.Path))
+}
+
+// filePath returns the path of given buffer b's file or an empty string
+// if it has no file.
+func filePath(b view.Buffer) string {
+	if b, ok := b.(*buffer); ok && b.file != nil {
+		return b.file.Path
+	}
+	return ""
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/slukits/gini/cmd/gini/model"
+	"github.com/slukits/gini/cmd/gini/view"
+	"github.com/slukits/gini/pkg/file"
+	"github.com/slukits/gini/pkg/settings"
+	. "github.com/slukits/gounit"
+)
+
+type Settings struct{ Suite }
+
+func (s *Settings) SetUp(t *T) { t.Parallel() }
+
+func (s *Settings) Default_to_the_file_packages_defaults(t *T) {
+	cfg := newConfig(registersFX(t))
+	t.Eq([]view.Setting{
+		{Name: BackupSizeSetting, Value: "1048576", Origin: "default"},
+		{Name: BackupsSetting, Value: "100", Origin: "default"},
+		{Name: SpellCheckerSetting, Value: "", Origin: "default"},
+		{Name: SpellingSetting, Value: "en_US", Origin: "default"},
+	}, cfg.Settings(&buffer{}))
+}
+
+func (s *Settings) Are_set_globally_for_buffers_without_file(t *T) {
+	lgg := registersFX(t)
+	cfg := newConfig(lgg)
+	t.ErrIs(cfg.Set(&buffer{}, BackupsSetting, "5", "file"), errScope)
+	t.FatalOn(cfg.Set(&buffer{}, BackupsSetting, "5", "global"))
+	t.Eq(view.Setting{Name: BackupsSetting, Value: "5",
+		Origin: "global", Source: filepath.Join(lgg.Env.Conf(),
+			settings.GlobalFile)}, newConfig(lgg).Settings(&buffer{})[1])
+}
+
+func (s *Settings) Are_applied_to_a_saved_buffers_file(t *T) {
+	f := fileFX(t)
+	cfg := newConfig(f.Log)
+	b := &buffer{Buffer: model.NewBuffer([]byte("gini")), file: f,
+		config: cfg}
+	t.FatalOn(cfg.Set(b, BackupsSetting, "7", "file"))
+	t.FatalOn(b.Save())
+	t.Eq(7, f.Backups)
+	t.Eq(int64(file.DefaultMaxSize), f.MaxSize)
+}
+
+func (s *Settings) Of_a_project_are_stored_in_the_project(t *T) {
+	f := fileFX(t)
+	t.FatalOn(os.Mkdir(filepath.Join(f.Log.Env.Home(), ".git"), 0700))
+	cfg := newConfig(f.Log)
+	b := &buffer{file: f, config: cfg}
+	t.FatalOn(cfg.Set(b, BackupsSetting, "7", "project"))
+	t.Eq(view.Setting{Name: BackupsSetting, Value: "7",
+		Origin: "project", Source: filepath.Join(f.Log.Env.Home(),
+			settings.ProjectFile)}, cfg.Settings(b)[1])
+	_, err := os.Stat(filepath.Join(f.Log.Env.Home(), ".gini"))
+	t.FatalOn(err)
+	t.ErrMatched(cfg.Set(b, BackupsSetting, "0", "dir"), "between")
+}
+
+func (s *Settings) Are_reloaded_after_saving_a_settings_file(t *T) {
+	f := fileFX(t)
+	cfg := newConfig(f.Log)
+	t.Eq("100", cfg.Settings(&buffer{})[1].Value)
+	f.Path = filepath.Join(f.Log.Env.Conf(), settings.GlobalFile)
+	t.FatalOn(os.MkdirAll(filepath.Dir(f.Path), 0700))
+	b := &buffer{Buffer: model.NewBuffer([]byte(
+		`{"values":{"backups":9}}`)), file: f, config: cfg}
+	t.FatalOn(b.Save())
+	t.Eq("9", cfg.Settings(&buffer{})[1].Value)
+}
+
+func (s *Settings) Are_not_reloaded_after_saving_other_files(t *T) {
+	f := fileFX(t)
+	cfg := newConfig(f.Log)
+	t.Eq("100", cfg.Settings(&buffer{})[1].Value)
+	global := filepath.Join(f.Log.Env.Conf(), settings.GlobalFile)
+	t.FatalOn(os.MkdirAll(filepath.Dir(global), 0700))
+	t.FatalOn(os.WriteFile(global, []byte(`{"values":{"backups":9}}`),
+		0600))
+	b := &buffer{Buffer: model.NewBuffer([]byte("gini")), file: f,
+		config: cfg}
+	t.FatalOn(b.Save())
+	t.Eq("100", cfg.Settings(&buffer{})[1].Value)
+}
+
+func TestSettings(t *testing.T) {
+	t.Parallel()
+	Run(&Settings{}, t)
+}
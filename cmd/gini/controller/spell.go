@@ -0,0 +1,252 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/slukits/gini/cmd/gini/view"
+	"github.com/slukits/gini/pkg/lg"
+	"github.com/slukits/gini/pkg/spell"
+)
+
+const (
+
+	// SpellingSetting is the language of the dictionaries a file is
+	// spell-checked against, e.g. en_US, or "off".
+	SpellingSetting = "spelling"
+
+	// SpellCheckerSetting is the command line of an external checker
+	// speaking the ispell pipe protocol, e.g. "hunspell -a -d en_US",
+	// which replaces the dictionaries of the spelling setting.
+	SpellCheckerSetting = "spell checker"
+
+	// WordsFile is the word list in the config directory taking the
+	// accepted words of files which aren't in a project.
+	WordsFile = "words"
+
+	// Suggestions is the maximal number of suggested corrections of a
+	// misspelled word.
+	Suggestions = 10
+)
+
+// errNoWordList is returned by a buffer accepting a word if there is no
+// word list taking it.
+var errNoWordList = errors.New("gini: controller: spelling: no word list")
+
+// checker returns the spell-checker of given buffer b's file which
+// checks against the external checker of b's spell checker setting or
+// the dictionaries of the language of b's spelling setting and the
+// personal word lists.  The word list of b's project or of the config
+// directory is consulted in any case.  Without external checker and
+// dictionaries or if spelling is off the checker considers every word
+// correct.
+func (c *config) checker(b *buffer) *spell.Checker {
+	path := filePath(b)
+	lang := c.ss.String(SpellingSetting, path)
+	if lang == "" || lang == "off" {
+		return &spell.Checker{}
+	}
+	ck, pp := &spell.Checker{}, []string{}
+	cmd := strings.TrimSpace(c.ss.String(SpellCheckerSetting, path))
+	if cmd != "" {
+		ck.Pipe = c.pipe(cmd)
+	}
+	if ck.Pipe == nil {
+		home := ""
+		if c.ss.Log != nil && c.ss.Log.Env != nil {
+			home = c.ss.Log.Env.Home()
+		}
+		if pp = spell.Find(c.dictDirs, home, lang); len(pp) == 0 {
+			return ck
+		}
+	}
+	for _, p := range append(pp, c.wordList(path)) {
+		if d := c.dict(p); d != nil {
+			ck.Dicts = append(ck.Dicts, d)
+		}
+	}
+	return ck
+}
+
+// pipe returns the pipe to the external checker with given command line
+// cmd which is started once.  A checker which fails to start is logged
+// to lg.ERR and its pipe is nil as is the pipe of a blank command line.
+func (c *config) pipe(cmd string) *spell.Pipe {
+	if p, ok := c.pipes[cmd]; ok {
+		return p
+	}
+	ff := strings.Fields(cmd)
+	if len(ff) == 0 {
+		return nil
+	}
+	p, err := spell.Start(ff[0], ff[1:]...)
+	if err != nil {
+		c.ss.Log.Tof(lg.ERR, "%v", err)
+		p = nil
+	}
+	c.pipes[cmd] = p
+	return p
+}
+
+// failed logs the error of given checker ck's pipe to lg.ERR if it
+// failed and drops the failed pipe from the started pipes.  The
+// spell-checkers of all buffers are outdated so the next checked word
+// is checked by a checker with a pipe which is started anew.
+func (c *config) failed(ck *spell.Checker) {
+	if ck.Pipe == nil || ck.Pipe.Err() == nil {
+		return
+	}
+	for cmd, p := range c.pipes {
+		if p != ck.Pipe {
+			continue
+		}
+		c.ss.Log.Tof(lg.ERR, "%v", p.Err())
+		delete(c.pipes, cmd)
+		p.Close()
+		c.spellings++
+	}
+}
+
+// wordList returns the path of the word list taking the accepted words
+// of the file with given path which is the word list of its project or
+// the word list in the config directory.
+func (c *config) wordList(path string) string {
+	if root := projectRoot(c.ss.Log, path); root != "" {
+		return filepath.Join(root, spell.ProjectFile)
+	}
+	return confPath(c.ss.Log, WordsFile)
+}
+
+// dict returns the dictionary of the word list with given path which is
+// loaded once.  A word list which doesn't exist yet is empty while an
+// other loading error is logged to lg.ERR.
+func (c *config) dict(path string) *spell.Dict {
+	if d, ok := c.dicts[path]; ok {
+		return d
+	}
+	d, err := spell.Load(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			c.ss.Log.Tof(lg.ERR, "%v", err)
+			return nil
+		}
+		d = &spell.Dict{}
+	}
+	c.dicts[path] = d
+	return d
+}
+
+// accept adds given word w to the word list of the project of given
+// buffer b's file or to the word list in the config directory.
+func (c *config) accept(b *buffer, w string) error {
+	path := c.wordList(filePath(b))
+	d := c.dict(path)
+	if d == nil {
+		return fmt.Errorf("%w: %s", errNoWordList, path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("gini: controller: spelling: %w", err)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("gini: controller: spelling: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(w + "\n"); err != nil {
+		return fmt.Errorf("gini: controller: spelling: %w", err)
+	}
+	d.Add(w)
+	return nil
+}
+
+// spelling returns given buffer b's spell-checker which is created on
+// first request and after a spelling setting was changed.  Help pages
+// aren't spell-checked.
+func (b *buffer) spelling() *spell.Checker {
+	if b.checker != nil &&
+		(b.config == nil || b.spellings == b.config.spellings) {
+		return b.checker
+	}
+	b.checker = &spell.Checker{}
+	if b.config != nil {
+		b.spellings = b.config.spellings
+	}
+	if b.config != nil && b.Buffer != nil && b.page == "" {
+		b.checker = b.config.checker(b)
+	}
+	return b.checker
+}
+
+// misspellings returns the misspelled words of the line with given
+// index ln which are the words of the checked tokens of a lexed buffer
+// or all words of a line of plain text.
+func (b *buffer) misspellings(ln int) []spell.Misspelling {
+	c := b.spelling()
+	if len(c.Dicts) == 0 {
+		return nil
+	}
+	defer b.config.failed(c)
+	if x := b.lexedLines(); x != nil {
+		return c.Tokens(x.Tokens(ln))
+	}
+	return c.Line(ln, b.Line(ln))
+}
+
+// misspelledStyles returns the spans of the misspelled words of the
+// line with given index ln styled by the theme's misspelling class.
+func (b *buffer) misspelledStyles(ln int) (ss []view.Span) {
+	for _, m := range b.misspellings(ln) {
+		ss = append(ss, view.Span{Start: m.Cl, End: m.Cl + m.Len(),
+			Style: b.config.theme[MisspellingClass]})
+	}
+	return ss
+}
+
+// Misspelled returns the misspelled word at given position together
+// with its suggested corrections.
+func (b *buffer) Misspelled(ln, cl int) (view.Misspelling, bool) {
+	for _, m := range b.misspellings(ln) {
+		if cl < m.Cl || cl >= m.Cl+m.Len() {
+			continue
+		}
+		c := b.spelling()
+		defer b.config.failed(c)
+		return view.Misspelling{Word: m.Word, Ln: m.Ln, Cl: m.Cl,
+			Suggestions: c.Suggest(m.Word, Suggestions),
+		}, true
+	}
+	return view.Misspelling{}, false
+}
+
+// Accept adds given word w to the word list of given buffer b's project
+// or to the word list in the config directory if b isn't in a project.
+func (b *buffer) Accept(w string) error {
+	if b.config == nil {
+		return errNoWordList
+	}
+	return b.config.accept(b, w)
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/slukits/gini/cmd/gini/model"
+	"github.com/slukits/gini/cmd/gini/view"
+	"github.com/slukits/gini/pkg/lg"
+	"github.com/slukits/gini/pkg/spell"
+	. "github.com/slukits/gounit"
+	"github.com/slukits/lines"
+)
+
+type Spelling struct{ Suite }
+
+func (s *Spelling) SetUp(t *T) { t.Parallel() }
+
+// spelledFX returns a buffer of a file with given name and content src
+// whose config finds an en_US dictionary.
+func spelledFX(t *T, name, src string) *buffer {
+	b := typedFX(t, name, src)
+	dir := t.FS().Tmp().Path()
+	t.FatalOn(os.WriteFile(filepath.Join(dir, "en_US.dic"), []byte(
+		"6\nthe\nword\nline\nlength\nmain\nint\n"), 0600))
+	b.config.dictDirs = []string{dir}
+	return b
+}
+
+const spelledGoFX = "package main\n// teh wrod\nvar lineLenght int\n"
+
+func (s *Spelling) Marks_misspelled_words(t *T) {
+	vw := &view.View{Buffer: spelledFX(t, "gini.go", spelledGoFX)}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	cc := fx.CellsOf(vw.Editor())
+	t.Not.True(cc[0].HasAA(8, lines.Underline))
+	t.True(cc[1].HasFG(0, lines.Gray))
+	t.True(cc[1].HasFG(3, lines.Red))
+	t.True(cc[1].HasAA(3, lines.Underline))
+	t.True(cc[1].HasAA(7, lines.Underline))
+	t.Not.True(cc[2].HasAA(4, lines.Underline))
+	t.True(cc[2].HasAA(8, lines.Underline))
+}
+
+func (s *Spelling) Checks_all_words_of_plain_text(t *T) {
+	b := spelledFX(t, "gini.txt", "the wrod")
+	t.Eq(1, len(b.misspellings(0)))
+	t.Eq(4, b.Styles(0)[0].Start)
+}
+
+func (s *Spelling) Suggests_corrections_of_misspelled_words(t *T) {
+	b := spelledFX(t, "gini.go", spelledGoFX)
+	_, ok := b.Misspelled(1, 0)
+	t.Not.True(ok)
+	m, ok := b.Misspelled(1, 5)
+	t.FatalIfNot(t.True(ok))
+	t.Eq(view.Misspelling{Word: "teh", Ln: 1, Cl: 3,
+		Suggestions: []string{"the"}}, m)
+	m, ok = b.Misspelled(2, 8)
+	t.FatalIfNot(t.True(ok))
+	t.Eq("Lenght", m.Word)
+	t.Eq([]string{"Length"}, m.Suggestions)
+}
+
+func (s *Spelling) Is_turned_off_by_its_setting(t *T) {
+	b := spelledFX(t, "gini.go", spelledGoFX)
+	t.Eq(3, len(b.misspellings(1))+len(b.misspellings(2)))
+	t.FatalOn(b.config.Set(b, SpellingSetting, "off", "file"))
+	t.Eq(0, len(b.misspellings(1)))
+	t.FatalOn(b.config.Set(b, SpellingSetting, "de_DE", "file"))
+	t.Eq(0, len(b.misspellings(1)))
+}
+
+func (s *Spelling) Settings_update_the_checkers_of_all_buffers(t *T) {
+	b := spelledFX(t, "gini.go", spelledGoFX)
+	other := &buffer{Buffer: model.NewBuffer([]byte("teh wrod")),
+		file: b.file, config: b.config}
+	t.Eq(2, len(other.misspellings(0)))
+	t.FatalOn(b.config.Set(b, SpellingSetting, "off", "global"))
+	t.Eq(0, len(other.misspellings(0)))
+}
+
+func (s *Spelling) Does_not_check_help_pages(t *T) {
+	b := spelledFX(t, "gini.txt", "the wrod")
+	b.page = "index.gnh"
+	t.Eq(0, len(b.misspellings(0)))
+}
+
+func (s *Spelling) Accepts_words_into_the_project_word_list(t *T) {
+	b := spelledFX(t, "gini.txt", "the wrod")
+	home := b.file.Log.Env.Home()
+	t.FatalOn(os.Mkdir(filepath.Join(home, ".git"), 0700))
+	t.FatalOn(b.Accept("wrod"))
+	t.Eq(0, len(b.misspellings(0)))
+	bb, err := os.ReadFile(filepath.Join(home, spell.ProjectFile))
+	t.FatalOn(err)
+	t.Eq("wrod\n", string(bb))
+	other := &buffer{Buffer: model.NewBuffer([]byte("wrod")),
+		file: b.file, config: newConfig(b.file.Log)}
+	other.config.dictDirs = b.config.dictDirs
+	t.Eq(0, len(other.misspellings(0)))
+}
+
+func (s *Spelling) Accepts_words_outside_a_project_globally(t *T) {
+	b := spelledFX(t, "gini.txt", "the wrod")
+	t.FatalOn(b.Accept("wrod"))
+	t.Eq(0, len(b.misspellings(0)))
+	bb, err := os.ReadFile(confPath(b.file.Log, WordsFile))
+	t.FatalOn(err)
+	t.Eq("wrod\n", string(bb))
+}
+
+// fakeSpell is the fake spell-checker speaking the ispell pipe protocol
+// which accepts the words given after -a.
+var fakeSpell = filepath.Join("..", "..", "..", "pkg", "spell",
+	"testdata", "fakespell")
+
+func (s *Spelling) Consults_the_external_checker_of_its_setting(t *T) {
+	b := spelledFX(t, "gini.go", spelledGoFX)
+	b.config.dictDirs = nil
+	t.FatalOn(b.config.Set(b, SpellCheckerSetting,
+		fakeSpell+" -a the tea word main line int", "file"))
+	m, ok := b.Misspelled(1, 4)
+	t.FatalIfNot(t.True(ok))
+	t.Eq([]string{"the", "tea"}, m.Suggestions)
+	t.Eq(2, len(b.misspellings(1)))
+	t.Eq(1, len(b.misspellings(2)))
+	t.FatalOn(b.Accept("wrod"))
+	t.Eq(1, len(b.misspellings(1)))
+	t.Eq(1, len(b.config.pipes))
+}
+
+func (s *Spelling) Logs_an_external_checker_failing_to_start(t *T) {
+	b := spelledFX(t, "gini.txt", "the wrod")
+	t.FatalOn(b.config.Set(b, SpellCheckerSetting, fakeSpell, "file"))
+	t.Eq(1, len(b.misspellings(0)))
+	t.Contains(b.file.Log.String(lg.ERR), "protocol")
+}
+
+func (s *Spelling) Drops_a_failing_external_checker(t *T) {
+	b := spelledFX(t, "gini.txt", "hang wrod")
+	b.config.dictDirs = nil
+	t.FatalOn(b.config.Set(b, SpellCheckerSetting,
+		fakeSpell+" -a word", "file"))
+	t.Eq(0, len(b.misspellings(0)))
+	t.Contains(b.file.Log.String(lg.ERR), "timeout")
+	t.Eq(0, len(b.config.pipes))
+}
+
+func (s *Spelling) Restarts_a_failed_external_checker(t *T) {
+	b := spelledFX(t, "gini.txt", "hang\nwrod")
+	b.config.dictDirs = nil
+	t.FatalOn(b.config.Set(b, SpellCheckerSetting,
+		fakeSpell+" -a word", "file"))
+	failed := b.spelling().Pipe
+	t.Eq(0, len(b.misspellings(0)))
+	t.Eq(1, len(b.misspellings(1)))
+	t.FatalIfNot(t.Not.True(b.spelling().Pipe == nil))
+	t.Not.True(b.spelling().Pipe == failed)
+	t.FatalOn(b.spelling().Pipe.Err())
+}
+
+func (s *Spelling) Ignores_a_blank_external_checker(t *T) {
+	b := spelledFX(t, "gini.txt", "the wrod")
+	t.FatalOn(b.config.Set(b, SpellCheckerSetting, " \t ", "file"))
+	t.Eq(1, len(b.misspellings(0)))
+	t.Eq(0, len(b.config.pipes))
+	t.Not.Contains(b.file.Log.String(lg.ERR), "spell")
+}
+
+func TestSpelling(t *testing.T) {
+	t.Parallel()
+	Run(&Spelling{}, t)
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/slukits/gini/pkg/lg"
+	"github.com/slukits/lines"
+)
+
+// ThemeFile is the file in the config directory which maps the token
+// classes of lexed files to their styles, e.g.
+//
+//	{"keyword": {"fg": "navy", "aa": ["bold"]}, "comment": {"fg": "#808080"}}
+//
+// A class of the theme file replaces the class of the default theme.
+const ThemeFile = "theme.json"
+
+// MisspellingClass is the theme's class of misspelled words.
+const MisspellingClass = "misspelling"
+
+// styleConf is the persisted style of a token class.  Colors are given
+// by name or in the hex-format #RRGGBB while the attributes are bold,
+// blink, reverse, underline, dim and strikethrough.
+type styleConf struct {
+	FG string   `json:"fg,omitempty"`
+	BG string   `json:"bg,omitempty"`
+	AA []string `json:"aa,omitempty"`
+}
+
+// defaultTheme styles the token classes of the builtin lexers.
+var defaultTheme = map[string]styleConf{
+	"keyword":  {AA: []string{"bold"}},
+	"comment":  {FG: "gray"},
+	"string":   {FG: "green"},
+	"rune":     {FG: "green"},
+	"number":   {FG: "teal"},
+	"variable": {FG: "purple"},
+
+	MisspellingClass: {FG: "red", AA: []string{"underline"}},
+}
+
+// colors are the colors of a theme by their names.
+var colors = map[string]lines.Color{
+	"black": lines.Black, "maroon": lines.Maroon, "green": lines.Green,
+	"olive": lines.Olive, "navy": lines.Navy, "purple": lines.Purple,
+	"teal": lines.Teal, "silver": lines.Silver, "gray": lines.Gray,
+	"red": lines.Red, "lime": lines.Lime, "yellow": lines.Yellow,
+	"blue": lines.Blue, "fuchsia": lines.Fuchsia, "aqua": lines.Aqua,
+	"white": lines.White,
+}
+
+// attributes are the style attributes of a theme by their names.
+var attributes = map[string]lines.StyleAttributeMask{
+	"bold": lines.Bold, "blink": lines.Blink, "reverse": lines.Reverse,
+	"underline": lines.Underline, "dim": lines.Dim,
+	"strikethrough": lines.StrikeThrough,
+}
+
+// theme maps token classes to the styles of their tokens.
+type theme map[string]lines.Style
+
+// newTheme returns the default theme updated by the theme file in the
+// config directory of given logger lgg's environment.  Invalid styles
+// are logged to lg.ERR and ignored.
+func newTheme(lgg *lg.Logger) theme {
+	cc := map[string]styleConf{}
+	for class, c := range defaultTheme {
+		cc[class] = c
+	}
+	loaded := map[string]styleConf{}
+	if loadConf(lgg, ThemeFile, &loaded) {
+		for class, c := range loaded {
+			cc[class] = c
+		}
+	}
+	th := theme{}
+	for class, c := range cc {
+		sty, err := c.style()
+		if err != nil {
+			lgg.Tof(lg.ERR, "gini: controller: theme: %s: %v", class, err)
+			continue
+		}
+		th[class] = sty
+	}
+	return th
+}
+
+// style returns the lines style of given style configuration c.
+func (c styleConf) style() (lines.Style, error) {
+	sty := lines.DefaultStyle
+	if c.FG != "" {
+		fg, err := color(c.FG)
+		if err != nil {
+			return sty, err
+		}
+		sty = sty.WithFG(fg)
+	}
+	if c.BG != "" {
+		bg, err := color(c.BG)
+		if err != nil {
+			return sty, err
+		}
+		sty = sty.WithBG(bg)
+	}
+	for _, a := range c.AA {
+		aa, ok := attributes[strings.ToLower(a)]
+		if !ok {
+			return sty, fmt.Errorf("unknown attribute '%s'", a)
+		}
+		sty = sty.WithAdded(aa)
+	}
+	return sty, nil
+}
+
+// color returns the color with given name or hex-value #RRGGBB.
+func color(s string) (lines.Color, error) {
+	if c, ok := colors[strings.ToLower(s)]; ok {
+		return c, nil
+	}
+	if len(s) == 7 && s[0] == '#' {
+		if v, err := strconv.ParseUint(s[1:], 16, 32); err == nil {
+			return lines.Color(v), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown color '%s'", s)
+}
@@ -0,0 +1,482 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package model
+
+import (
+	"errors"
+	"io"
+	"sort"
+	"unicode/utf8"
+)
+
+// ErrPosition is returned by Buffer operations which are given a
+// line/column position which is not inside the buffer.
+var ErrPosition = errors.New("gini: model: buffer: invalid position")
+
+// Buffer stores text as a piece table, i.e. its content is never copied
+// on modification but described by a sequence of pieces referencing
+// either the read-only original content or the append-only added
+// content.  The pieces are kept in a balanced tree (treap) whose nodes
+// know the number of bytes and line breaks of their subtrees which
+// makes lookups of lines and byte offsets O(log n) in the number of
+// pieces.  Lines and columns are zero-based while a column counts runes
+// not bytes.  The zero value is an empty buffer ready to use.
+type Buffer struct {
+	orig, add store
+	root      *piece
+	seed      uint32
+	hst       history
+}
+
+// NewBuffer creates a new buffer having given bytes bb as its original
+// content.  Note bb is not copied, i.e. it must not be modified by the
+// caller after it was passed to NewBuffer.
+func NewBuffer(bb []byte) *Buffer {
+	b := &Buffer{}
+	if len(bb) == 0 {
+		return b
+	}
+	b.orig.bb = bb
+	b.orig.index(0)
+	b.root = b.newPiece(false, 0, len(bb))
+	return b
+}
+
+// store holds the bytes a piece refers to along with the sorted
+// offsets of all line breaks in these bytes.
+type store struct {
+	bb []byte
+	nl []int
+}
+
+// index records the offsets of all line breaks in given store's bytes
+// from given offset on.
+func (s *store) index(from int) {
+	for i, b := range s.bb[from:] {
+		if b == '\n' {
+			s.nl = append(s.nl, from+i)
+		}
+	}
+}
+
+// breaks returns the number of line breaks in the byte range
+// [start,end[ of given store s.
+func (s *store) breaks(start, end int) int {
+	return sort.SearchInts(s.nl, end) - sort.SearchInts(s.nl, start)
+}
+
+// piece is a treap node referencing a continuous byte sequence of
+// either the original or the added store.
+type piece struct {
+	add         bool
+	start, len  int
+	nl          int
+	prio        uint32
+	size, lines int
+	left, right *piece
+}
+
+func size(p *piece) int {
+	if p == nil {
+		return 0
+	}
+	return p.size
+}
+
+func lines(p *piece) int {
+	if p == nil {
+		return 0
+	}
+	return p.lines
+}
+
+func (p *piece) update() {
+	p.size = size(p.left) + p.len + size(p.right)
+	p.lines = lines(p.left) + p.nl + lines(p.right)
+}
+
+func (b *Buffer) store(p *piece) *store {
+	if p.add {
+		return &b.add
+	}
+	return &b.orig
+}
+
+// prio returns the next pseudo random treap priority (xorshift).
+func (b *Buffer) prio() uint32 {
+	if b.seed == 0 {
+		b.seed = 2463534242
+	}
+	b.seed ^= b.seed << 13
+	b.seed ^= b.seed >> 17
+	b.seed ^= b.seed << 5
+	return b.seed
+}
+
+func (b *Buffer) newPiece(add bool, start, len int) *piece {
+	p := &piece{add: add, start: start, len: len, prio: b.prio()}
+	p.nl = b.store(p).breaks(start, start+len)
+	p.update()
+	return p
+}
+
+// split splits given piece tree p at given byte offset off into a tree
+// holding the bytes before off and a tree holding the remaining bytes.
+// A piece containing off is cut into two pieces.
+func (b *Buffer) split(p *piece, off int) (*piece, *piece) {
+	if p == nil {
+		return nil, nil
+	}
+	ls := size(p.left)
+	switch {
+	case off <= ls:
+		l, r := b.split(p.left, off)
+		p.left = r
+		p.update()
+		return l, p
+	case off >= ls+p.len:
+		l, r := b.split(p.right, off-ls-p.len)
+		p.right = l
+		p.update()
+		return p, r
+	}
+	cut := off - ls
+	q := b.newPiece(p.add, p.start+cut, p.len-cut)
+	p.len = cut
+	p.nl -= q.nl
+	r := p.right
+	p.right = nil
+	p.update()
+	return p, b.merge(q, r)
+}
+
+// merge concatenates given piece trees l and r whereas all pieces of l
+// precede the pieces of r.
+func (b *Buffer) merge(l, r *piece) *piece {
+	switch {
+	case l == nil:
+		return r
+	case r == nil:
+		return l
+	case l.prio > r.prio:
+		l.right = b.merge(l.right, r)
+		l.update()
+		return l
+	}
+	r.left = b.merge(l, r.left)
+	r.update()
+	return r
+}
+
+// Size returns the number of bytes stored in given buffer b.
+func (b *Buffer) Size() int { return size(b.root) }
+
+// Lines returns the number of lines of given buffer b which is the
+// number of its line breaks plus one, i.e. an empty buffer has one
+// (empty) line.
+func (b *Buffer) Lines() int { return lines(b.root) + 1 }
+
+// newline returns the byte offset of the line break with given
+// zero-based index k or -1 if there is no such line break.
+func (b *Buffer) newline(k int) int {
+	p, off := b.root, 0
+	for p != nil {
+		if k < lines(p.left) {
+			p = p.left
+			continue
+		}
+		k -= lines(p.left)
+		off += size(p.left)
+		if k < p.nl {
+			s := b.store(p)
+			i := sort.SearchInts(s.nl, p.start)
+			return off + s.nl[i+k] - p.start
+		}
+		k -= p.nl
+		off += p.len
+		p = p.right
+	}
+	return -1
+}
+
+// lineRange returns the byte offsets of the first byte of the line
+// with given index ln and of its terminating line break (or the
+// buffer's end).
+func (b *Buffer) lineRange(ln int) (start, end int) {
+	if ln > 0 {
+		start = b.newline(ln-1) + 1
+	}
+	if ln < lines(b.root) {
+		return start, b.newline(ln)
+	}
+	return start, size(b.root)
+}
+
+// bytes returns a copy of the bytes in the range [from,to[ of given
+// buffer b.
+func (b *Buffer) bytes(from, to int) []byte {
+	if from < 0 {
+		from = 0
+	}
+	if to > size(b.root) {
+		to = size(b.root)
+	}
+	if from >= to {
+		return nil
+	}
+	return b.collect(b.root, 0, from, to, make([]byte, 0, to-from))
+}
+
+func (b *Buffer) collect(p *piece, off, from, to int, bb []byte) []byte {
+	if p == nil || from >= off+p.size || to <= off {
+		return bb
+	}
+	bb = b.collect(p.left, off, from, to, bb)
+	s := off + size(p.left)
+	lo, hi := from, to
+	if lo < s {
+		lo = s
+	}
+	if hi > s+p.len {
+		hi = s + p.len
+	}
+	if lo < hi {
+		bb = append(bb, b.store(p).bb[p.start+lo-s:p.start+hi-s]...)
+	}
+	return b.collect(p.right, s+p.len, from, to, bb)
+}
+
+// Line returns the content of the line with given index ln without its
+// line break.  Line returns the zero string if there is no such line.
+func (b *Buffer) Line(ln int) string {
+	if ln < 0 || ln >= b.Lines() {
+		return ""
+	}
+	return string(b.bytes(b.lineRange(ln)))
+}
+
+// LineLen returns the number of runes of the line with given index ln
+// or -1 if there is no such line.
+func (b *Buffer) LineLen(ln int) int {
+	if ln < 0 || ln >= b.Lines() {
+		return -1
+	}
+	return utf8.RuneCount(b.bytes(b.lineRange(ln)))
+}
+
+// Offset returns the byte offset of given position ln/cl whereas cl
+// may be the rune count of ln's line to address the position after
+// the line's last rune.  Offset fails with ErrPosition if given
+// position is not inside given buffer b.
+func (b *Buffer) Offset(ln, cl int) (int, error) {
+	if ln < 0 || ln >= b.Lines() || cl < 0 {
+		return 0, ErrPosition
+	}
+	start, end := b.lineRange(ln)
+	if cl > end-start {
+		return 0, ErrPosition
+	}
+	bb, off := b.bytes(start, end), 0
+	for i := 0; i < cl; i++ {
+		if off >= len(bb) {
+			return 0, ErrPosition
+		}
+		_, w := utf8.DecodeRune(bb[off:])
+		off += w
+	}
+	return start + off, nil
+}
+
+// Position returns the line and column of given byte offset off which
+// is clamped to the buffer's content.
+func (b *Buffer) Position(off int) (ln, cl int) {
+	if off < 0 {
+		off = 0
+	}
+	if off > b.Size() {
+		off = b.Size()
+	}
+	ln = b.lineAt(off)
+	start, _ := b.lineRange(ln)
+	return ln, utf8.RuneCount(b.bytes(start, off))
+}
+
+// lineAt returns the index of the line containing given byte offset
+// off by descending the piece tree.
+func (b *Buffer) lineAt(off int) (ln int) {
+	p := b.root
+	for p != nil {
+		if off < size(p.left) {
+			p = p.left
+			continue
+		}
+		off -= size(p.left)
+		ln += lines(p.left)
+		if off < p.len {
+			return ln + b.store(p).breaks(p.start, p.start+off)
+		}
+		off -= p.len
+		ln += p.nl
+		p = p.right
+	}
+	return ln
+}
+
+// runesEnd returns the byte offset after n runes following given
+// offset off or the buffer's end if there are less than n runes.  n is
+// clamped to the bytes following off to avoid an overflow.
+func (b *Buffer) runesEnd(off, n int) int {
+	if rest := size(b.root) - off; n > rest {
+		n = rest
+	}
+	bb, end := b.bytes(off, off+n*utf8.UTFMax), 0
+	for i := 0; i < n && end < len(bb); i++ {
+		_, w := utf8.DecodeRune(bb[end:])
+		end += w
+	}
+	return off + end
+}
+
+// Insert inserts given string s at given position ln/cl.  Insert fails
+// with ErrPosition if given position is not inside given buffer b.
+// A non-empty insertion is recorded for undo (see Buffer.Undo).
+func (b *Buffer) Insert(ln, cl int, s string) error {
+	off, err := b.Offset(ln, cl)
+	if err != nil || s == "" {
+		return err
+	}
+	b.insert(off, s)
+	b.hst.record(op{off: off, text: s})
+	return nil
+}
+
+func (b *Buffer) insert(off int, s string) {
+	if s == "" {
+		return
+	}
+	start := len(b.add.bb)
+	b.add.bb = append(b.add.bb, s...)
+	b.add.index(start)
+	if b.extend(b.root, off, start, len(s)) {
+		return
+	}
+	l, r := b.split(b.root, off)
+	b.root = b.merge(b.merge(l, b.newPiece(true, start, len(s))), r)
+}
+
+// extend grows the piece ending at given offset off by given length n
+// if it is the piece which was added last, i.e. typing consecutive
+// runes doesn't create a piece for each rune.
+func (b *Buffer) extend(p *piece, off, start, n int) bool {
+	if p == nil {
+		return false
+	}
+	ls, ok := size(p.left), false
+	switch {
+	case off <= ls:
+		ok = b.extend(p.left, off, start, n)
+	case off > ls+p.len:
+		ok = b.extend(p.right, off-ls-p.len, start, n)
+	default:
+		if off != ls+p.len || !p.add || p.start+p.len != start {
+			return false
+		}
+		p.len += n
+		p.nl = b.add.breaks(p.start, p.start+p.len)
+		ok = true
+	}
+	if ok {
+		p.update()
+	}
+	return ok
+}
+
+// Delete removes n runes starting at given position ln/cl whereas a
+// line break counts as one rune.  Are there less than n runes after
+// the position the buffer's remaining content is removed.  Delete
+// returns the removed text and fails with ErrPosition if given
+// position is not inside given buffer b.  The deletion is recorded for
+// undo (see Buffer.Undo).
+func (b *Buffer) Delete(ln, cl, n int) (string, error) {
+	off, err := b.Offset(ln, cl)
+	if err != nil {
+		return "", err
+	}
+	s := b.delete(off, b.runesEnd(off, n))
+	if s != "" {
+		b.hst.record(op{off: off, text: s, del: true})
+	}
+	return s, nil
+}
+
+func (b *Buffer) delete(from, to int) string {
+	if from >= to {
+		return ""
+	}
+	l, r := b.split(b.root, from)
+	m, r := b.split(r, to-from)
+	b.root = b.merge(l, r)
+	return string(b.collect(m, 0, 0, size(m), nil))
+}
+
+// Text returns the n runes starting at given position ln/cl whereas a
+// line break counts as one rune.  Text fails with ErrPosition if given
+// position is not inside given buffer b.
+func (b *Buffer) Text(ln, cl, n int) (string, error) {
+	off, err := b.Offset(ln, cl)
+	if err != nil {
+		return "", err
+	}
+	return string(b.bytes(off, b.runesEnd(off, n))), nil
+}
+
+// String returns given buffer b's content.
+func (b *Buffer) String() string {
+	return string(b.bytes(0, b.Size()))
+}
+
+// WriteTo writes given buffer b's content piece by piece to given
+// writer w.
+func (b *Buffer) WriteTo(w io.Writer) (int64, error) {
+	var n int64
+	var err error
+	b.each(b.root, func(bb []byte) bool {
+		var m int
+		m, err = w.Write(bb)
+		n += int64(m)
+		return err == nil
+	})
+	return n, err
+}
+
+func (b *Buffer) each(p *piece, cb func([]byte) bool) bool {
+	if p == nil {
+		return true
+	}
+	if !b.each(p.left, cb) {
+		return false
+	}
+	if !cb(b.store(p).bb[p.start : p.start+p.len]) {
+		return false
+	}
+	return b.each(p.right, cb)
+}
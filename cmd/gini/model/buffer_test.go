@@ -0,0 +1,244 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package model
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	. "github.com/slukits/gounit"
+)
+
+type ABuffer struct{ Suite }
+
+func (s *ABuffer) SetUp(t *T) { t.Parallel() }
+
+func (s *ABuffer) Has_one_empty_line_if_zero(t *T) {
+	b := &Buffer{}
+	t.Eq(1, b.Lines())
+	t.Eq(0, b.Size())
+	t.Eq("", b.Line(0))
+}
+
+func (s *ABuffer) Provides_lines_of_its_original_content(t *T) {
+	b := NewBuffer([]byte("first\nsecond\n\nfourth"))
+	t.Eq(4, b.Lines())
+	t.Eq("first", b.Line(0))
+	t.Eq("second", b.Line(1))
+	t.Eq("", b.Line(2))
+	t.Eq("fourth", b.Line(3))
+	t.Eq("", b.Line(4))
+}
+
+func (s *ABuffer) Has_an_empty_last_line_if_ending_in_line_break(t *T) {
+	b := NewBuffer([]byte("first\n"))
+	t.Eq(2, b.Lines())
+	t.Eq("", b.Line(1))
+}
+
+func (s *ABuffer) Inserts_runes_at_given_line_and_column(t *T) {
+	b := NewBuffer([]byte("äöü\nxyz"))
+	t.FatalOn(b.Insert(0, 1, "ß"))
+	t.FatalOn(b.Insert(1, 3, "!"))
+	t.Eq("äßöü", b.Line(0))
+	t.Eq("xyz!", b.Line(1))
+}
+
+func (s *ABuffer) Splits_lines_on_inserted_line_breaks(t *T) {
+	b := NewBuffer([]byte("onetwo"))
+	t.FatalOn(b.Insert(0, 3, "\nmiddle\n"))
+	t.Eq(3, b.Lines())
+	t.Eq("one", b.Line(0))
+	t.Eq("middle", b.Line(1))
+	t.Eq("two", b.Line(2))
+}
+
+func (s *ABuffer) Fails_to_insert_outside_its_content(t *T) {
+	b := NewBuffer([]byte("ab\ncd"))
+	t.ErrIs(b.Insert(2, 0, "x"), ErrPosition)
+	t.ErrIs(b.Insert(0, 3, "x"), ErrPosition)
+	t.ErrIs(b.Insert(-1, 0, "x"), ErrPosition)
+	t.Eq("ab\ncd", b.String())
+}
+
+func (s *ABuffer) Deletes_runes_returning_deleted_text(t *T) {
+	b := NewBuffer([]byte("äöü\nxyz"))
+	got, err := b.Delete(0, 1, 2)
+	t.FatalOn(err)
+	t.Eq("öü", got)
+	t.Eq("ä\nxyz", b.String())
+}
+
+func (s *ABuffer) Joins_lines_on_deleted_line_break(t *T) {
+	b := NewBuffer([]byte("one\ntwo\nthree"))
+	got, err := b.Delete(0, 3, 1)
+	t.FatalOn(err)
+	t.Eq("\n", got)
+	t.Eq(2, b.Lines())
+	t.Eq("onetwo", b.Line(0))
+	t.Eq("three", b.Line(1))
+}
+
+func (s *ABuffer) Deletes_up_to_its_end(t *T) {
+	b := NewBuffer([]byte("one\ntwo"))
+	got, err := b.Delete(1, 1, 42)
+	t.FatalOn(err)
+	t.Eq("wo", got)
+	t.Eq("one\nt", b.String())
+	got, err = b.Delete(0, 1, math.MaxInt)
+	t.FatalOn(err)
+	t.Eq("ne\nt", got)
+	t.Eq("o", b.String())
+}
+
+func (s *ABuffer) Provides_text_of_given_rune_range(t *T) {
+	b := NewBuffer([]byte("one\ntwö"))
+	got, err := b.Text(0, 2, 4)
+	t.FatalOn(err)
+	t.Eq("e\ntw", got)
+	got, err = b.Text(1, 2, 5)
+	t.FatalOn(err)
+	t.Eq("ö", got)
+}
+
+func (s *ABuffer) Maps_positions_to_offsets_and_back(t *T) {
+	b := NewBuffer([]byte("ä\nbö\n"))
+	off, err := b.Offset(1, 2)
+	t.FatalOn(err)
+	t.Eq(6, off)
+	ln, cl := b.Position(off)
+	t.Eq(1, ln)
+	t.Eq(2, cl)
+	ln, cl = b.Position(b.Size())
+	t.Eq(2, ln)
+	t.Eq(0, cl)
+}
+
+func (s *ABuffer) Provides_the_rune_count_of_a_line(t *T) {
+	b := NewBuffer([]byte("äöü\n"))
+	t.Eq(3, b.LineLen(0))
+	t.Eq(0, b.LineLen(1))
+	t.Eq(-1, b.LineLen(2))
+}
+
+func (s *ABuffer) Writes_its_content_to_given_writer(t *T) {
+	b := NewBuffer([]byte("one\nthree"))
+	t.FatalOn(b.Insert(0, 3, "\ntwo"))
+	buf := &bytes.Buffer{}
+	n, err := b.WriteTo(buf)
+	t.FatalOn(err)
+	t.Eq(int64(len("one\ntwo\nthree")), n)
+	t.Eq("one\ntwo\nthree", buf.String())
+}
+
+func (s *ABuffer) Reflects_random_edits_like_a_string(t *T) {
+	rnd := rand.New(rand.NewSource(42))
+	exp := "GINI ist kein\nIDE,\naber äöü\n"
+	b := NewBuffer([]byte(exp))
+	for i := 0; i < 2000; i++ {
+		rr := []rune(exp)
+		pos := rnd.Intn(len(rr) + 1)
+		ln, cl := position(rr[:pos])
+		if rnd.Intn(3) > 0 {
+			ins := []string{"x", "ü\n", "\n", "abc", "€"}[rnd.Intn(5)]
+			t.FatalOn(b.Insert(ln, cl, ins))
+			exp = string(rr[:pos]) + ins + string(rr[pos:])
+			continue
+		}
+		n := rnd.Intn(5) + 1
+		got, err := b.Delete(ln, cl, n)
+		t.FatalOn(err)
+		if pos+n > len(rr) {
+			n = len(rr) - pos
+		}
+		t.FatalIfNot(t.Eq(string(rr[pos:pos+n]), got))
+		exp = string(rr[:pos]) + string(rr[pos+n:])
+	}
+	t.Eq(exp, b.String())
+	ll := strings.Split(exp, "\n")
+	t.FatalIfNot(t.Eq(len(ll), b.Lines()))
+	for i, l := range ll {
+		t.Eq(l, b.Line(i))
+	}
+}
+
+// position calculates the line/column position after given runes rr.
+func position(rr []rune) (ln, cl int) {
+	for _, r := range rr {
+		if r == '\n' {
+			ln, cl = ln+1, 0
+			continue
+		}
+		cl++
+	}
+	return ln, cl
+}
+
+func TestABuffer(t *testing.T) {
+	t.Parallel()
+	Run(&ABuffer{}, t)
+}
+
+// fiftyMB creates a buffer with about 50 MB of content.
+func fiftyMB() *Buffer {
+	line := []byte(strings.Repeat("GINI Is Not an IDE äöü ", 3) + "\n")
+	return NewBuffer(bytes.Repeat(line, 50<<20/len(line)))
+}
+
+func BenchmarkBuffer_random_edits_on_50MB(b *testing.B) {
+	buf, rnd := fiftyMB(), rand.New(rand.NewSource(42))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ln := rnd.Intn(buf.Lines())
+		cl := rnd.Intn(buf.LineLen(ln) + 1)
+		if i%2 == 0 {
+			if err := buf.Insert(ln, cl, "gini\n"); err != nil {
+				b.Fatal(err)
+			}
+			continue
+		}
+		if _, err := buf.Delete(ln, cl, 7); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBuffer_random_line_lookup_on_50MB(b *testing.B) {
+	buf, rnd := fiftyMB(), rand.New(rand.NewSource(42))
+	for i := 0; i < 10_000; i++ {
+		ln := rnd.Intn(buf.Lines())
+		if err := buf.Insert(ln, 0, fmt.Sprintf("%d\n", i)); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if l := buf.Line(rnd.Intn(buf.Lines())); !utf8.ValidString(l) {
+			b.Fatal("invalid line")
+		}
+	}
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package model
+
+// op is an invertible modification of a Buffer's content:  an
+// insertion of text at a byte offset or the deletion of text at a byte
+// offset.
+type op struct {
+	off  int
+	text string
+	del  bool
+}
+
+// inverse returns the operation undoing given operation o.
+func (o op) inverse() op { return op{off: o.off, text: o.text, del: !o.del} }
+
+// transaction groups operations to a single undo step.  The
+// transactions of a Buffer form a tree whose root is the unmodified
+// buffer;  undoing a transaction and doing a new modification adds a
+// new branch to the tree instead of discarding the undone transaction.
+type transaction struct {
+	ops      []op
+	parent   *transaction
+	children []*transaction
+
+	// redo is the index of the child which is redone by default, i.e.
+	// the most recently created or undone child.
+	redo int
+}
+
+// history keeps track of the transactions of a Buffer.  The zero value
+// is ready to use.
+type history struct {
+	root    transaction
+	current *transaction
+	open    *transaction
+	depth   int
+}
+
+func (h *history) cur() *transaction {
+	if h.current == nil {
+		h.current = &h.root
+	}
+	return h.current
+}
+
+// record adds given operation o to the open transaction or to a new
+// transaction if no transaction was started.
+func (h *history) record(o op) {
+	if h.open == nil {
+		h.open = &transaction{parent: h.cur()}
+	}
+	if n := len(h.open.ops); n > 0 && h.open.ops[n-1].merges(o) {
+		h.open.ops[n-1] = h.open.ops[n-1].merge(o)
+	} else {
+		h.open.ops = append(h.open.ops, o)
+	}
+	if h.depth == 0 {
+		h.commit()
+	}
+}
+
+// merges returns true if given operation o directly continues
+// receiving operation p, e.g. a typed rune after a typed rune.
+func (p op) merges(o op) bool {
+	if p.del != o.del {
+		return false
+	}
+	if !p.del {
+		return o.off == p.off+len(p.text)
+	}
+	return o.off == p.off || o.off+len(o.text) == p.off
+}
+
+func (p op) merge(o op) op {
+	switch {
+	case !p.del:
+		return op{off: p.off, text: p.text + o.text}
+	case o.off == p.off:
+		return op{off: p.off, text: p.text + o.text, del: true}
+	}
+	return op{off: o.off, text: o.text + p.text, del: true}
+}
+
+// commit adds the open transaction as new current transaction to the
+// history tree if it has operations.
+func (h *history) commit() {
+	t := h.open
+	h.open = nil
+	if t == nil || len(t.ops) == 0 {
+		return
+	}
+	t.parent.children = append(t.parent.children, t)
+	t.parent.redo = len(t.parent.children) - 1
+	h.current = t
+}
+
+// Begin starts a transaction grouping all following modifications of
+// given buffer b to one undo step until the matching End is called.
+// Begin/End pairs may be nested whereas only the outermost pair defines
+// the transaction.  E.g. the modifications of an insert-mode session
+// should be one undo step.
+func (b *Buffer) Begin() {
+	b.hst.depth++
+}
+
+// End ends a transaction started by Begin.
+func (b *Buffer) End() {
+	if b.hst.depth == 0 {
+		return
+	}
+	b.hst.depth--
+	if b.hst.depth == 0 {
+		b.hst.commit()
+	}
+}
+
+// Undo reverts the last modification of given buffer b and returns the
+// position of the reverted modification.  Undo ends a started
+// transaction.  ok is false if there is nothing to undo.
+func (b *Buffer) Undo() (ln, cl int, ok bool) {
+	b.hst.depth = 1
+	b.End()
+	t := b.hst.cur()
+	if t.parent == nil {
+		return 0, 0, false
+	}
+	for i := len(t.ops) - 1; i >= 0; i-- {
+		b.apply(t.ops[i].inverse())
+	}
+	for i, c := range t.parent.children {
+		if c == t {
+			t.parent.redo = i
+		}
+	}
+	b.hst.current = t.parent
+	ln, cl = b.Position(t.ops[0].off)
+	return ln, cl, true
+}
+
+// Redo reapplies the most recently undone modification of given buffer
+// b and returns the position of the reapplied modification.  ok is
+// false if there is nothing to redo.
+func (b *Buffer) Redo() (ln, cl int, ok bool) {
+	return b.RedoBranch(b.hst.cur().redo)
+}
+
+// Branches returns the number of modifications which may be redone at
+// the current state of given buffer b.  There is more than one if an
+// undone modification was followed by a new modification.
+func (b *Buffer) Branches() int {
+	return len(b.hst.cur().children)
+}
+
+// RedoBranch reapplies the undone modification with given index i
+// whereas the most recently created branch has the highest index (see
+// Buffer.Branches).  ok is false if there is no such branch.
+func (b *Buffer) RedoBranch(i int) (ln, cl int, ok bool) {
+	b.hst.depth = 1
+	b.End()
+	cur := b.hst.cur()
+	if i < 0 || i >= len(cur.children) {
+		return 0, 0, false
+	}
+	t := cur.children[i]
+	cur.redo = i
+	for _, o := range t.ops {
+		b.apply(o)
+	}
+	b.hst.current = t
+	ln, cl = b.Position(t.ops[0].off)
+	return ln, cl, true
+}
+
+// apply executes given operation o without recording it.
+func (b *Buffer) apply(o op) {
+	if o.del {
+		b.delete(o.off, o.off+len(o.text))
+		return
+	}
+	b.insert(o.off, o.text)
+}
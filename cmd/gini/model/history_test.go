@@ -0,0 +1,153 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package model
+
+import (
+	"testing"
+
+	. "github.com/slukits/gounit"
+)
+
+type History struct{ Suite }
+
+func (s *History) SetUp(t *T) { t.Parallel() }
+
+func (s *History) Has_nothing_to_undo_or_redo_initially(t *T) {
+	b := NewBuffer([]byte("gini"))
+	_, _, ok := b.Undo()
+	t.Not.True(ok)
+	_, _, ok = b.Redo()
+	t.Not.True(ok)
+}
+
+func (s *History) Undoes_each_modification_outside_a_transaction(t *T) {
+	b := NewBuffer([]byte("gini"))
+	t.FatalOn(b.Insert(0, 4, "!"))
+	_, err := b.Delete(0, 0, 1)
+	t.FatalOn(err)
+	t.Eq("ini!", b.String())
+	ln, cl, ok := b.Undo()
+	t.True(ok)
+	t.Eq("gini!", b.String())
+	t.Eq(0, ln)
+	t.Eq(0, cl)
+	ln, cl, ok = b.Undo()
+	t.True(ok)
+	t.Eq("gini", b.String())
+	t.Eq(0, ln)
+	t.Eq(4, cl)
+}
+
+func (s *History) Ignores_empty_modifications(t *T) {
+	b := NewBuffer([]byte("gini"))
+	t.FatalOn(b.Insert(0, 4, ""))
+	_, err := b.Delete(0, 4, 1)
+	t.FatalOn(err)
+	_, _, ok := b.Undo()
+	t.Not.True(ok)
+	t.ErrIs(b.Insert(1, 0, ""), ErrPosition)
+}
+
+func (s *History) Undoes_a_transaction_in_one_step(t *T) {
+	b := NewBuffer([]byte("one\n"))
+	b.Begin()
+	t.FatalOn(b.Insert(1, 0, "t"))
+	t.FatalOn(b.Insert(1, 1, "w"))
+	t.FatalOn(b.Insert(1, 2, "x"))
+	_, err := b.Delete(1, 2, 1)
+	t.FatalOn(err)
+	t.FatalOn(b.Insert(1, 2, "o"))
+	b.End()
+	t.Eq("one\ntwo", b.String())
+	ln, cl, ok := b.Undo()
+	t.True(ok)
+	t.Eq("one\n", b.String())
+	t.Eq(1, ln)
+	t.Eq(0, cl)
+}
+
+func (s *History) Only_ends_a_transaction_at_its_outermost_end(t *T) {
+	b := NewBuffer(nil)
+	b.Begin()
+	t.FatalOn(b.Insert(0, 0, "a"))
+	b.Begin()
+	t.FatalOn(b.Insert(0, 1, "b"))
+	b.End()
+	t.FatalOn(b.Insert(0, 2, "c"))
+	b.End()
+	b.Undo()
+	t.Eq("", b.String())
+}
+
+func (s *History) Undo_ends_an_open_transaction(t *T) {
+	b := NewBuffer([]byte("gini"))
+	b.Begin()
+	t.FatalOn(b.Insert(0, 0, ">"))
+	_, _, ok := b.Undo()
+	t.True(ok)
+	t.Eq("gini", b.String())
+	t.FatalOn(b.Insert(0, 0, "<"))
+	b.Undo()
+	t.Eq("gini", b.String())
+}
+
+func (s *History) Redoes_undone_modifications(t *T) {
+	b := NewBuffer([]byte("gini"))
+	t.FatalOn(b.Insert(0, 4, " is"))
+	t.FatalOn(b.Insert(0, 7, " not"))
+	b.Undo()
+	b.Undo()
+	t.Eq("gini", b.String())
+	_, cl, ok := b.Redo()
+	t.True(ok)
+	t.Eq(4, cl)
+	t.Eq("gini is", b.String())
+	b.Redo()
+	t.Eq("gini is not", b.String())
+	_, _, ok = b.Redo()
+	t.Not.True(ok)
+}
+
+func (s *History) Keeps_undone_branch_on_new_modification(t *T) {
+	b := NewBuffer([]byte("gini"))
+	t.FatalOn(b.Insert(0, 4, " is"))
+	b.Undo()
+	t.FatalOn(b.Insert(0, 0, "a "))
+	b.Undo()
+	t.Eq(2, b.Branches())
+	b.Redo()
+	t.Eq("a gini", b.String())
+	b.Undo()
+	_, _, ok := b.RedoBranch(0)
+	t.True(ok)
+	t.Eq("gini is", b.String())
+	b.Undo()
+	_, _, ok = b.RedoBranch(2)
+	t.Not.True(ok)
+	b.Redo()
+	t.Eq("gini is", b.String())
+}
+
+func TestHistory(t *testing.T) {
+	t.Parallel()
+	Run(&History{}, t)
+}
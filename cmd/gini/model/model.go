@@ -19,4 +19,10 @@ You should have received a copy of the GNU General Public License
 along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
 */
 
+/*
+Package model provides the data structures gini's controller queries
+and modifies in response to user input, e.g. the Buffer storing the
+text of an edited file.  Like the view the model doesn't access any
+other part of the gini command implementation.
+*/
 package model
@@ -0,0 +1,124 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package view
+
+import (
+	"github.com/slukits/gini/cmd/gini/view/internal/cnt"
+	"github.com/slukits/gini/cmd/gini/view/internal/edt"
+	"github.com/slukits/lines"
+)
+
+// Cmd is a repeatable editor command, e.g. "delete til '42'", which is
+// executed at the cursor position of the current editor.
+type Cmd = edt.Cmd
+
+// Operations of a Cmd.
+const (
+	Move   = edt.Move
+	Copy   = edt.Copy
+	Delete = edt.Delete
+	Paste  = edt.Paste
+)
+
+// Commands keep the repeatable commands executed by the view's editors
+// in a command ring and a movement ring.
+type Commands interface {
+
+	// Executed adds given command to the movement ring if it only
+	// moves the cursor and to the command ring otherwise.
+	Executed(Cmd)
+
+	// Commands returns the command ring's commands latest first.
+	Commands() []Cmd
+
+	// Movements returns the movement ring's commands latest first.
+	Movements() []Cmd
+}
+
+// CommandsSize is the number of commands and movements kept by the
+// default Commands of a View.
+const CommandsSize = 20
+
+// commands are the Commands of a View which wasn't given Commands.
+type commands struct{ cc, mm []Cmd }
+
+func (cc *commands) Executed(c Cmd) {
+	if c.IsMovement() {
+		cc.mm = pushed(cc.mm, c)
+		return
+	}
+	cc.cc = pushed(cc.cc, c)
+}
+
+func pushed(cc []Cmd, c Cmd) []Cmd {
+	cc = append([]Cmd{c}, cc...)
+	if len(cc) > CommandsSize {
+		cc = cc[:CommandsSize]
+	}
+	return cc
+}
+
+func (cc *commands) Commands() []Cmd { return cc.cc }
+
+func (cc *commands) Movements() []Cmd { return cc.mm }
+
+// executed reports given command c to the view's Commands unless it
+// repeats the latest command of its ring.
+func (v *View) executed(c Cmd) {
+	latest := v.Commands.Commands()
+	if c.IsMovement() {
+		latest = v.Commands.Movements()
+	}
+	if len(latest) > 0 && latest[0].String() == c.String() {
+		return
+	}
+	v.Commands.Executed(c)
+}
+
+// repeat executes the latest command of the movement ring or of the
+// command ring at the cursor of the current editor.
+func (v *View) repeat(e *lines.Env, movement bool) {
+	cc := v.Commands.Commands()
+	if movement {
+		cc = v.Commands.Movements()
+	}
+	if len(cc) == 0 {
+		v.message(e, "repeat: no command")
+		return
+	}
+	c := cc[0]
+	v.onEditor(e, func(ed *edt.Editor, e *lines.Env) { ed.Execute(e, c) })
+}
+
+// commandEntries returns the entries of the A and M contexts executing
+// given commands cc paged by cnt.Paged.
+func (v *View) commandEntries(cc []Cmd) (ii []*cnt.Item) {
+	for _, c := range cc {
+		c, label := c, c.String()
+		if c.Op == Paste {
+			label = "paste " + abbreviated(c.Text)
+		}
+		ii = append(ii, v.editorCmd(label, 0, false,
+			func(ed *edt.Editor, e *lines.Env) { ed.Execute(e, c) }))
+	}
+	return cnt.Paged(ii)
+}
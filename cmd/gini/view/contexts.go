@@ -0,0 +1,306 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package view
+
+import (
+	"github.com/slukits/gini/cmd/gini/view/internal/cnt"
+	"github.com/slukits/gini/cmd/gini/view/internal/edt"
+	"github.com/slukits/lines"
+)
+
+// contexts returns the root of the context bar's context tree for given
+// view v.
+func (v *View) contexts() *cnt.Item {
+	return &cnt.Item{Items: []*cnt.Item{
+		{Label: "lines", Key: 'l', Items: []*cnt.Item{
+			v.editorCmd("down", 'd', true, func(
+				ed *edt.Editor, e *lines.Env,
+			) {
+				ed.Page(e, 1)
+			}),
+			v.editorCmd("up", 'u', true, func(
+				ed *edt.Editor, e *lines.Env,
+			) {
+				ed.Page(e, -1)
+			}),
+			{Label: "index", Key: 'i', List: v.indexEntries},
+		}, Help: ContextsHelp + "lines"},
+		{Label: "columns", Key: 'c', Items: []*cnt.Item{
+			v.columnsCmd("next", 'n', false, func(
+				cc *Columns, e *lines.Env,
+			) {
+				cc.Move(e, 1, 0)
+			}),
+			v.columnsCmd("previous", 'p', false, func(
+				cc *Columns, e *lines.Env,
+			) {
+				cc.Move(e, -1, 0)
+			}),
+			v.columnsCmd("down", 'j', false, func(
+				cc *Columns, e *lines.Env,
+			) {
+				cc.Move(e, 0, 1)
+			}),
+			v.columnsCmd("up", 'k', false, func(
+				cc *Columns, e *lines.Env,
+			) {
+				cc.Move(e, 0, -1)
+			}),
+			v.columnsCmd("add", 'a', false, func(
+				cc *Columns, e *lines.Env,
+			) {
+				col, _ := cc.Current()
+				cc.Add(e, col+1, v.newSplit())
+			}),
+			v.columnsCmd("split", 's', false, func(
+				cc *Columns, e *lines.Env,
+			) {
+				cc.Split(e, v.newSplit())
+			}),
+			v.columnsCmd("remove", 'r', false, func(
+				cc *Columns, e *lines.Env,
+			) {
+				cc.Remove(e)
+			}),
+			v.columnsCmd("wider", '+', true, func(
+				cc *Columns, e *lines.Env,
+			) {
+				cc.Resize(e, 1)
+			}),
+			v.columnsCmd("narrower", '-', true, func(
+				cc *Columns, e *lines.Env,
+			) {
+				cc.Resize(e, -1)
+			}),
+		}, Help: ContextsHelp + "columns"},
+		{Label: "mode", Key: 'm', Items: []*cnt.Item{
+			v.modeCmd("insert", 'i', edt.Insert),
+			v.modeCmd("overwrite", 'o', edt.Overwrite),
+			v.modeCmd("command", 'c', edt.Command),
+		}, Help: ContextsHelp + "mode"},
+		v.fileTypeContext(),
+		{Label: "/", Key: '/', Modified: cnt.ModifiedIndicator,
+			Help: ContextsHelp + "directory"},
+		v.settingsContext(),
+		{Label: ".", Key: '.', Items: []*cnt.Item{
+			{Label: "macros", Key: 'a', List: v.macroContexts,
+				Help: ContextsHelp + "macros"},
+			{Label: "messages", Key: 'm', Items: []*cnt.Item{
+				v.contextCmd("older", 'o', (*cnt.Context).Older),
+				v.contextCmd("newer", 'n', (*cnt.Context).Newer),
+			}, Help: ContextsHelp + "messages"},
+			v.helpCmd(),
+			{Label: "quit", Key: 'q', Exec: func(e *lines.Env) {
+				e.Lines.Quit()
+			}, Help: ContextsHelp + "quit"},
+		}, Help: ContextsHelp + "app"},
+	}}
+}
+
+// cmdContexts returns the contexts of the editor commands moving,
+// copying and deleting til the target of a motion, pasting from the
+// registers and repeating from the command and movement rings by their
+// keys.
+func (v *View) cmdContexts() map[rune]*cnt.Item {
+	return map[rune]*cnt.Item{
+		'P': v.pasteContext(),
+		'A': {Label: "commands", List: func() []*cnt.Item {
+			return v.commandEntries(v.Commands.Commands())
+		}, Help: ContextsHelp + "repeat"},
+		'M': {Label: "movements", List: func() []*cnt.Item {
+			return v.commandEntries(v.Commands.Movements())
+		}, Help: ContextsHelp + "repeat"},
+		'e': v.editContext(),
+		'g': v.grepContext(FileScope),
+		'G': v.grepContext(DirScope),
+		'*': {Label: "saved grep", List: v.savedEntries,
+			Help: ContextsHelp + "saved greps"},
+		't': v.tilContext("til", edt.Move, false),
+		'T': v.tilContext("til back", edt.Move, true),
+		'c': v.tilContext("copy til", edt.Copy, false),
+		'C': v.tilContext("copy back til", edt.Copy, true),
+		'd': v.tilContext("delete til", edt.Delete, false),
+		'D': v.tilContext("delete back til", edt.Delete, true),
+	}
+}
+
+// keyContexts returns the contexts of the editor commands bound to
+// keys, i.e. ctrl+g grepping the project.
+func (v *View) keyContexts() map[lines.Key]*cnt.Item {
+	return map[lines.Key]*cnt.Item{
+		lines.CtrlG: v.grepContext(ProjectScope),
+	}
+}
+
+// tilContext returns the context applying given operation op til the
+// target of a motion in forward or backward direction respectively.
+// Its input box takes the searched text while its entries provide the
+// motions to the next space, word and line boundaries and toggle if
+// the searched text is included.
+func (v *View) tilContext(
+	label string, op edt.Operation, back bool,
+) *cnt.Item {
+	including, prefix := false, "til "
+	if back {
+		prefix = "back til "
+	}
+	til := func(
+		target string, m edt.Motion,
+	) func(*edt.Editor, *lines.Env) {
+		return func(ed *edt.Editor, e *lines.Env) {
+			ed.Execute(e, edt.Cmd{Op: op, Motion: m,
+				Target: prefix + target, Including: including})
+		}
+	}
+	text := edt.Til
+	if back {
+		text = edt.TilBack
+	}
+	incl := &cnt.Item{Label: "excluding", Key: 'u', Keep: true}
+	incl.Exec = func(*lines.Env) {
+		including = !including
+		incl.Label = "excluding"
+		if including {
+			incl.Label = "including"
+		}
+	}
+	it := &cnt.Item{Label: label, Input: &cnt.Input{
+		Apply: func(e *lines.Env, s string) {
+			v.onEditor(e, til("'"+s+"'", text(s)))
+		},
+	}}
+	it.Help = ContextsHelp + "til"
+	if op != edt.Move {
+		it.Help = ContextsHelp + "copy and delete"
+	}
+	motion := func(label string, k rune, m edt.Motion) *cnt.Item {
+		return v.editorCmd(label, k, false, til(label, m))
+	}
+	it.Items = append(it.Items, motion("space", 's', text(" ")))
+	if back {
+		it.Items = append(it.Items,
+			motion("word beginning", 'b', edt.WordBeginning),
+			motion("previous word", 'r', edt.PreviousWord),
+			motion("line beginning", 'g', edt.LineBeginning),
+		)
+	} else {
+		it.Items = append(it.Items,
+			motion("word end", 'w', edt.WordEnd),
+			motion("next word", 'n', edt.NextWord),
+			motion("line end", 'e', edt.LineEnd),
+		)
+	}
+	it.Items = append(it.Items, incl)
+	return it
+}
+
+// editContext returns the context of the e command opening something
+// for editing, e.g. the latest keyboard macro or the saved greps.
+func (v *View) editContext() *cnt.Item {
+	return &cnt.Item{Label: "edit", Items: []*cnt.Item{
+		{Label: "macro recording", Key: 'r', Exec: func(e *lines.Env) {
+			v.editMacro(e, 0)
+		}},
+		{Label: "grep", Key: 'g', Exec: v.editGreps},
+	}, Help: ContextsHelp + "edit"}
+}
+
+// pasteContext returns the context pasting an entry of the copy or
+// delete ring.
+func (v *View) pasteContext() *cnt.Item {
+	return &cnt.Item{Label: "paste", Items: []*cnt.Item{
+		{Label: "copy ring", Key: 'c', List: func() []*cnt.Item {
+			return v.ringEntries(v.Registers.Copies())
+		}},
+		{Label: "delete ring", Key: 'd', List: func() []*cnt.Item {
+			return v.ringEntries(v.Registers.Deletions())
+		}},
+	}, Help: ContextsHelp + "paste"}
+}
+
+// RingLabelWidth is the maximal width of a ring entry's label in the
+// context bar.
+const RingLabelWidth = 12
+
+// ringEntries returns the commands pasting given entries ee of a ring
+// paged by cnt.Paged.
+func (v *View) ringEntries(ee []string) (ii []*cnt.Item) {
+	for _, s := range ee {
+		s := s
+		ii = append(ii, v.editorCmd(abbreviated(s), 0, false,
+			func(ed *edt.Editor, e *lines.Env) { ed.Paste(e, s) }))
+	}
+	return cnt.Paged(ii)
+}
+
+// editorCmd returns a context bar command with given label and key k
+// executing given function exec on the current split if it is an
+// editor.
+func (v *View) editorCmd(
+	label string, k rune, keep bool, exec func(*edt.Editor, *lines.Env),
+) *cnt.Item {
+	return &cnt.Item{Label: label, Key: k, Keep: keep,
+		Exec: func(e *lines.Env) { v.onEditor(e, exec) }}
+}
+
+// onEditor executes given function exec on the current split if it is
+// an editor.
+func (v *View) onEditor(e *lines.Env, exec func(*edt.Editor, *lines.Env)) {
+	ed, ok := v.Columns().CurrentSplit().(*edt.Editor)
+	if !ok {
+		return
+	}
+	e.Lines.Update(ed, nil, func(e *lines.Env) { exec(ed, e) })
+}
+
+// columnsCmd returns a context bar command with given label and key k
+// executing given function exec on the view's columns.
+func (v *View) columnsCmd(
+	label string, k rune, keep bool, exec func(*Columns, *lines.Env),
+) *cnt.Item {
+	return &cnt.Item{Label: label, Key: k, Keep: keep,
+		Exec: func(e *lines.Env) {
+			cc := v.Columns()
+			e.Lines.Update(cc, nil, func(e *lines.Env) { exec(cc, e) })
+		}}
+}
+
+// contextCmd returns a context bar command with given label and key k
+// executing given function exec on the context bar which stays active.
+func (v *View) contextCmd(
+	label string, k rune, exec func(*cnt.Context, *lines.Env),
+) *cnt.Item {
+	return &cnt.Item{Label: label, Key: k, Keep: true,
+		Exec: func(e *lines.Env) {
+			exec(v.Context().(*cnt.Context), e)
+		}}
+}
+
+// modeCmd returns a context bar command switching the view's editor
+// into given mode m.
+func (v *View) modeCmd(label string, k rune, m edt.Mode) *cnt.Item {
+	return v.editorCmd(label, k, false, func(
+		ed *edt.Editor, e *lines.Env,
+	) {
+		ed.Switch(e, m)
+	})
+}
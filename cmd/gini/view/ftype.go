@@ -0,0 +1,89 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package view
+
+import (
+	"github.com/slukits/gini/cmd/gini/view/internal/cnt"
+	"github.com/slukits/gini/cmd/gini/view/internal/edt"
+	"github.com/slukits/lines"
+)
+
+// FileTypeCmd is a command of the file-type context of a buffer.
+type FileTypeCmd struct {
+
+	// Label is the command's label in the file-type context.
+	Label string
+
+	// Key selects the command in the file-type context.
+	Key rune
+
+	// Exec executes the command on the buffer it was provided for and
+	// returns a message reporting its outcome.
+	Exec func() (msg string, err error)
+}
+
+// FileTypes provide the file types of buffers.
+type FileTypes interface {
+
+	// Commands returns the commands of given buffer b's file type.
+	Commands(b Buffer) []FileTypeCmd
+}
+
+// noFileTypes are the FileTypes of a View which wasn't given
+// FileTypes; its buffers have no file-type commands.
+type noFileTypes struct{}
+
+func (noFileTypes) Commands(Buffer) []FileTypeCmd { return nil }
+
+// fileTypeContext returns the context listing the commands of the file
+// type of the buffer of the current editor or of the origin of the
+// current grep results.
+func (v *View) fileTypeContext() *cnt.Item {
+	return &cnt.Item{Label: "*", Key: '*', List: func() (ii []*cnt.Item) {
+		ed := v.origin()
+		if ed == nil {
+			return nil
+		}
+		for _, c := range v.FileTypes.Commands(ed.Buffer) {
+			c := c
+			ii = append(ii, &cnt.Item{Label: c.Label, Key: c.Key,
+				Exec: func(e *lines.Env) { v.fileTypeCmd(e, ed, c) }})
+		}
+		return ii
+	}, Help: ContextsHelp + "file type"}
+}
+
+// fileTypeCmd executes given file-type command c on the buffer of given
+// editor ed, reports its outcome and updates ed to a possibly replaced
+// content.
+func (v *View) fileTypeCmd(e *lines.Env, ed *edt.Editor, c FileTypeCmd) {
+	e.Lines.Update(ed, nil, func(e *lines.Env) {
+		msg, err := c.Exec()
+		if err != nil {
+			v.message(e, c.Label+": "+err.Error())
+		} else if msg != "" {
+			v.message(e, c.Label+": "+msg)
+		}
+		ln, cl := ed.Cursor()
+		ed.Goto(e, ln, cl)
+	})
+}
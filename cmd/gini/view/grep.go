@@ -0,0 +1,420 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package view
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/slukits/gini/cmd/gini/view/internal/cnt"
+	"github.com/slukits/gini/cmd/gini/view/internal/edt"
+	"github.com/slukits/lines"
+)
+
+// Scope determines the files searched by a grep.
+type Scope int
+
+const (
+
+	// FileScope greps the file of the current editor.
+	FileScope Scope = iota
+
+	// DirScope greps the files of the directory containing the
+	// current editor's file.
+	DirScope
+
+	// ProjectScope greps recursively the files of the project, i.e.
+	// the repository, containing the current editor's file.
+	ProjectScope
+)
+
+// String returns the name of given scope s.
+func (s Scope) String() string {
+	switch s {
+	case DirScope:
+		return "dir"
+	case ProjectScope:
+		return "project"
+	}
+	return "file"
+}
+
+// Search describes a grep.
+type Search struct {
+
+	// Text is the searched text.
+	Text string
+
+	// Regexp is true if Text is a regular expression.
+	Regexp bool
+
+	// Scope determines the searched files.
+	Scope Scope
+}
+
+// String describes given search s, e.g. "grep dir re 'g.*i'".
+func (s Search) String() string {
+	str := "grep " + s.Scope.String()
+	if s.Regexp {
+		str += " re"
+	}
+	return str + " '" + s.Text + "'"
+}
+
+// Compile returns the regular expression matching given search s's
+// text.
+func (s Search) Compile() (*regexp.Regexp, error) {
+	if s.Regexp {
+		return regexp.Compile(s.Text)
+	}
+	return regexp.Compile(regexp.QuoteMeta(s.Text))
+}
+
+// Replacer returns a function replacing all matches of given search s
+// in a line by given replacement.  The replacement of a regular
+// expression search expands $-references to submatches (see
+// regexp.Regexp.Expand).
+func (s Search) Replacer(replacement string) (func(string) string, error) {
+	re, err := s.Compile()
+	if err != nil {
+		return nil, err
+	}
+	if s.Regexp {
+		return func(l string) string {
+			return re.ReplaceAllString(l, replacement)
+		}, nil
+	}
+	return func(l string) string {
+		return re.ReplaceAllLiteralString(l, replacement)
+	}, nil
+}
+
+// Match is a match of a grep in a line of a file.  Lines and columns
+// are zero-based while columns count runes.
+type Match struct {
+
+	// Path identifies the matching file for the view's Grepper.
+	Path string
+
+	// File is the displayed path of the matching file.
+	File string
+
+	// Ln is the index of the matching line.
+	Ln int
+
+	// Cl is the column of the match's first rune.
+	Cl int
+
+	// Len is the number of runes of the match.
+	Len int
+
+	// Line is the matching line.
+	Line string
+}
+
+// Grepper greps the files of the view's editors and replaces matches.
+type Grepper interface {
+
+	// Grep sends the matches of given search s in the scope of given
+	// buffer b to the returned channel which is closed once the search
+	// is done or given context ctx is canceled.
+	Grep(ctx context.Context, b Buffer, s Search) (<-chan Match, error)
+
+	// Open returns the buffer of the file containing given match m.
+	Open(m Match) (Buffer, error)
+
+	// Replace replaces in the lines of given matches mm all matches of
+	// given search s by given replacement and returns the number of
+	// replaced lines.
+	Replace(s Search, mm []Match, replacement string) (int, error)
+}
+
+// ReplaceIn replaces the lines of given matches mm in given buffer b by
+// the lines' replacements returned by given function r.  A line which
+// doesn't equal its match's line anymore is left untouched.  ReplaceIn
+// returns the number of replaced lines.
+func ReplaceIn(b Buffer, mm []Match, r func(string) string) (int, error) {
+	sorted := append([]Match{}, mm...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Ln > sorted[j].Ln
+	})
+	n, done := 0, map[int]bool{}
+	for _, m := range sorted {
+		if done[m.Ln] || m.Ln >= b.Lines() || b.Line(m.Ln) != m.Line {
+			continue
+		}
+		done[m.Ln] = true
+		s := r(m.Line)
+		if s == m.Line {
+			continue
+		}
+		_, err := b.Delete(m.Ln, 0, utf8.RuneCountInString(m.Line))
+		if err != nil {
+			return n, err
+		}
+		if err := b.Insert(m.Ln, 0, s); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// errNoBuffer is returned by the default Grepper of a View if a match
+// is opened before a buffer was grepped.
+var errNoBuffer = errors.New("gini: view: grep: no grepped buffer")
+
+// bufferGrep is the Grepper of a View which wasn't given a Grepper.  It
+// greps the current editor's buffer regardless of the search's scope.
+type bufferGrep struct{ b Buffer }
+
+func (g *bufferGrep) Grep(
+	_ context.Context, b Buffer, s Search,
+) (<-chan Match, error) {
+	re, err := s.Compile()
+	if err != nil {
+		return nil, err
+	}
+	g.b = b
+	mm := []Match{}
+	for ln := 0; ln < b.Lines(); ln++ {
+		line := b.Line(ln)
+		for _, loc := range re.FindAllStringIndex(line, -1) {
+			mm = append(mm, Match{Ln: ln, Line: line,
+				Cl:  utf8.RuneCountInString(line[:loc[0]]),
+				Len: utf8.RuneCountInString(line[loc[0]:loc[1]]),
+			})
+		}
+	}
+	ch := make(chan Match, len(mm))
+	for _, m := range mm {
+		ch <- m
+	}
+	close(ch)
+	return ch, nil
+}
+
+func (g *bufferGrep) Open(Match) (Buffer, error) {
+	if g.b == nil {
+		return nil, errNoBuffer
+	}
+	return g.b, nil
+}
+
+func (g *bufferGrep) Replace(
+	s Search, mm []Match, replacement string,
+) (int, error) {
+	if g.b == nil {
+		return 0, errNoBuffer
+	}
+	r, err := s.Replacer(replacement)
+	if err != nil {
+		return 0, err
+	}
+	return ReplaceIn(g.b, mm, r)
+}
+
+// SearchesSize is the number of greps kept in the grep ring.
+const SearchesSize = 20
+
+// grepContext returns the context of the g, G and ctrl+g commands
+// grepping in given scope.  Its input box takes the searched text while
+// its entries grep the word under the cursor, toggle between a text and
+// a regular expression search, replace the matches of the latest grep,
+// save the latest grep under a hotkey and list the previous and the
+// saved greps.
+func (v *View) grepContext(scope Scope) *cnt.Item {
+	re := false
+	toggle := &cnt.Item{Label: "text", Key: 'e', Keep: true}
+	toggle.Exec = func(*lines.Env) {
+		re = !re
+		toggle.Label = "text"
+		if re {
+			toggle.Label = "re"
+		}
+	}
+	return &cnt.Item{Label: "grep " + scope.String(),
+		Input: &cnt.Input{Apply: func(e *lines.Env, s string) {
+			v.Grep(e, Search{Text: s, Regexp: re, Scope: scope})
+		}},
+		Items: []*cnt.Item{
+			{Label: "under", Key: 'u', Exec: func(e *lines.Env) {
+				v.grepWord(e, scope)
+			}},
+			toggle,
+			v.replaceContext(),
+			v.saveContext(),
+			{Label: "ring", Key: 'r', List: v.searchEntries},
+		},
+		Help: ContextsHelp + "grep",
+	}
+}
+
+// replaceContext returns the context replacing the matches of the
+// latest grep.  Its input box takes the replacement whose effect is
+// previewed in the results split.
+func (v *View) replaceContext() *cnt.Item {
+	preview := func(e *lines.Env, s *string) {
+		if v.results != nil {
+			e.Lines.Update(v.results, previewed{s}, nil)
+		}
+	}
+	return &cnt.Item{Label: "replace", Key: 'a', Input: &cnt.Input{
+		Change:  func(e *lines.Env, s string) { preview(e, &s) },
+		Discard: func(e *lines.Env) { preview(e, nil) },
+		Apply: func(e *lines.Env, s string) {
+			preview(e, nil)
+			v.replace(e, s)
+		},
+	}}
+}
+
+// searchEntries returns the entries of the grep ring repeating a
+// previous grep paged by cnt.Paged followed by the contexts listing and
+// editing the saved greps.
+func (v *View) searchEntries() (ii []*cnt.Item) {
+	for _, s := range v.searches {
+		s := s
+		ii = append(ii, &cnt.Item{Label: abbreviated(s.String()),
+			Exec: func(e *lines.Env) { v.Grep(e, s) }})
+	}
+	return append(cnt.Paged(ii),
+		&cnt.Item{Label: "saved", Key: 's', List: v.savedEntries},
+		&cnt.Item{Label: "edit saved", Key: 'e', Exec: v.editGreps},
+	)
+}
+
+// origin returns the editor a grep is relative to, i.e. the current
+// split if it is an editor or the origin of current grep results.
+func (v *View) origin() *edt.Editor {
+	switch split := v.Columns().CurrentSplit().(type) {
+	case *edt.Editor:
+		return split
+	case *Results:
+		return split.origin
+	}
+	return nil
+}
+
+// grepWord greps in given scope for the word under the cursor of the
+// current editor.
+func (v *View) grepWord(e *lines.Env, scope Scope) {
+	ed := v.origin()
+	if ed == nil || ed.Word() == "" {
+		v.message(e, "grep: no word under cursor")
+		return
+	}
+	v.Grep(e, Search{Text: ed.Word(), Scope: scope})
+}
+
+// Grep greps for given search s relative to the current editor and
+// streams the matches into the results split which is added below the
+// current split unless it is already shown.  The search is added to
+// the grep ring.
+func (v *View) Grep(e *lines.Env, s Search) {
+	ed := v.origin()
+	if ed == nil || s.Text == "" {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	mm, err := v.Grepper.Grep(ctx, ed.Buffer, s)
+	if err != nil {
+		cancel()
+		v.message(e, "grep: "+err.Error())
+		return
+	}
+	v.searched(s)
+	cc := v.Columns()
+	if col, split, ok := cc.Find(v.results); ok {
+		cc.Focus(e, col, split)
+		r := v.results
+		r.start(e.Lines, s, ed, mm, cancel)
+		e.Lines.Update(r, nil, r.print)
+		return
+	}
+	v.results = &Results{Search: s, Typed: v.typed,
+		origin: ed, pending: mm, cancel: cancel, jump: v.jump}
+	cc.Split(e, v.results)
+}
+
+// searched adds given search s to the grep ring unless it is the latest
+// search.
+func (v *View) searched(s Search) {
+	if len(v.searches) > 0 && v.searches[0] == s {
+		return
+	}
+	v.searches = append([]Search{s}, v.searches...)
+	if len(v.searches) > SearchesSize {
+		v.searches = v.searches[:SearchesSize]
+	}
+}
+
+// replace replaces the matches of the latest grep by given replacement
+// and greps again to show the replaced lines.
+func (v *View) replace(e *lines.Env, replacement string) {
+	r := v.results
+	if r == nil || len(r.mm) == 0 {
+		v.message(e, "replace: no grep results")
+		return
+	}
+	n, err := v.Grepper.Replace(r.Search, r.mm, replacement)
+	if err != nil {
+		v.message(e, "replace: "+err.Error())
+		return
+	}
+	v.message(e, fmt.Sprintf("replaced %d lines", n))
+	if ed := r.origin; ed != nil {
+		e.Lines.Update(ed, nil, func(e *lines.Env) {
+			ln, cl := ed.Cursor()
+			ed.Goto(e, ln, cl)
+		})
+	}
+	v.Grep(e, r.Search)
+}
+
+// jump moves the cursor to given match m.  If the editor a grep was
+// started from shows the match's buffer it is focused otherwise the
+// match's buffer is opened in a new split below the results.
+func (v *View) jump(e *lines.Env, r *Results, m Match) {
+	b, err := v.Grepper.Open(m)
+	if err != nil {
+		v.message(e, "grep: "+err.Error())
+		return
+	}
+	cc, ed := v.Columns(), r.origin
+	if col, split, ok := cc.Find(ed); ok && ed.Buffer == b {
+		cc.Focus(e, col, split)
+	} else {
+		ed = v.wired(&edt.Editor{Buffer: b})
+		r.origin = ed
+		cc.Split(e, ed)
+	}
+	e.Lines.Update(cc, nil, func(e *lines.Env) {
+		e.Lines.Update(ed, nil, func(e *lines.Env) {
+			ed.Goto(e, m.Ln, m.Cl)
+		})
+	})
+}
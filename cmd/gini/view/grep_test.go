@@ -0,0 +1,289 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package view
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/slukits/gini/cmd/gini/view/internal/cnt"
+	"github.com/slukits/gini/cmd/gini/view/internal/edt"
+	. "github.com/slukits/gounit"
+	"github.com/slukits/lines"
+)
+
+type AGrep struct{ Suite }
+
+func (s *AGrep) SetUp(t *T) { t.Parallel() }
+
+// grepFX greps in a view of given lines for given text from the grep
+// context of given command rune and waits for the results.
+func grepFX(t *T, text string, ll ...string) (*lines.Fixture, *View) {
+	vw := &View{Buffer: &textFX{ll: ll}}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fire(fx, "g"+text)
+	fx.FireKey(lines.Enter)
+	waitFX(t, fx, vw)
+	return fx, vw
+}
+
+// waitFX waits until the matches of given view vw's latest grep are
+// streamed into its results.
+func waitFX(t *T, fx *lines.Fixture, vw *View) {
+	timeout := t.Timeout(0)
+	for {
+		done := false
+		fx.Lines.Update(vw, nil, func(*lines.Env) {
+			done = vw.results != nil && vw.results.grep > 0 &&
+				!vw.results.searching
+		})
+		if done {
+			fx.Lines.Update(vw.results, nil, func(*lines.Env) {})
+			return
+		}
+		select {
+		case <-timeout:
+			t.Fatal("grep results timed out")
+		default:
+		}
+	}
+}
+
+func (s *AGrep) Lists_matches_in_a_results_split(t *T) {
+	fx, vw := grepFX(t, "gini", "gini is gini", "not an ide", "gini")
+	t.Eq(3, len(vw.results.Matches()))
+	scr := fx.ScreenOf(vw.results).Trimmed().String()
+	t.Contains(scr, "grep file 'gini': 3 matches")
+	t.Contains(scr, "0:8: gini is gini")
+	t.Contains(scr, "2:0: gini")
+	t.Eq(vw.results, vw.Columns().CurrentSplit())
+}
+
+func (s *AGrep) Jumps_to_selected_match(t *T) {
+	fx, vw := grepFX(t, "gini", "gini is gini", "not an ide", "gini")
+	fx.FireRune('j')
+	t.Eq(1, vw.results.Selected())
+	t.True(fx.CellsOf(vw.results).HasAA(0, 2, lines.Reverse))
+	fx.FireKey(lines.Enter)
+	ln, cl := vw.Editor().(*edt.Editor).Cursor()
+	t.Eq(0, ln)
+	t.Eq(8, cl)
+	t.Eq(vw.Editor(), vw.Columns().CurrentSplit())
+}
+
+func (s *AGrep) Searches_word_under_cursor(t *T) {
+	vw := &View{Buffer: &textFX{ll: []string{"gini is gini", "is it"}}}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fire(fx, "lllllgjku")
+	waitFX(t, fx, vw)
+	t.Eq("grep file 'is'", vw.results.Search.String())
+	t.Eq(2, len(vw.results.Matches()))
+}
+
+func (s *AGrep) Searches_regular_expressions(t *T) {
+	vw := &View{Buffer: &textFX{ll: []string{"gini is gini", "42"}}}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fire(fx, "Gjke")
+	t.Contains(fx.ScreenOf(vw.Context()), "re")
+	fire(fx, "i[0-9]+")
+	fx.FireKey(lines.Enter)
+	waitFX(t, fx, vw)
+	t.Eq("grep dir re '[0-9]+'", vw.results.Search.String())
+	t.Eq(1, len(vw.results.Matches()))
+}
+
+func (s *AGrep) Replaces_matches_with_preview(t *T) {
+	fx, vw := grepFX(t, "gini", "gini is gini", "not an ide", "gini")
+	fire(fx, "gjkaGINI")
+	t.Contains(fx.ScreenOf(vw.results), "0:0: GINI is GINI")
+	fx.FireKey(lines.Esc)
+	t.Contains(fx.ScreenOf(vw.results), "0:0: gini is gini")
+	fire(fx, "gjkaGINI")
+	fx.FireKey(lines.Enter)
+	waitFX(t, fx, vw)
+	b := vw.Buffer.(*textFX)
+	t.Eq("GINI is GINI\nnot an ide\nGINI", strings.Join(b.ll, "\n"))
+	t.Eq(cnt.Message("replaced 2 lines"),
+		vw.Context().(*cnt.Context).Messages()[0])
+	t.Eq(0, len(vw.results.Matches()))
+}
+
+func (s *AGrep) Enters_project_grep_on_ctrl_g(t *T) {
+	vw := &View{Buffer: &textFX{ll: []string{"gini"}}}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fx.FireKey(lines.CtrlG)
+	t.Contains(fx.ScreenOf(vw.Context()), "grep project:")
+}
+
+func (s *AGrep) Repeats_previous_greps_from_the_ring(t *T) {
+	fx, vw := grepFX(t, "is", "gini is gini", "not an ide")
+	fire(fx, "gide")
+	fx.FireKey(lines.Enter)
+	waitFX(t, fx, vw)
+	fire(fx, "gjkr")
+	t.Contains(fx.ScreenOf(vw.Context()), "0:grep file '…")
+	fx.FireRune('1')
+	waitFX(t, fx, vw)
+	t.Eq("grep file 'is'", vw.results.Search.String())
+	t.Eq(1, len(vw.Columns().Column(0).CC)-1)
+}
+
+func (s *AGrep) Search_has_a_parsable_text_representation(t *T) {
+	for _, search := range []Search{
+		{Text: "gini"},
+		{Text: "'g.*i'", Regexp: true, Scope: DirScope},
+		{Text: "re ", Scope: ProjectScope},
+	} {
+		got, err := ParseSearch(search.String())
+		t.FatalOn(err)
+		t.Eq(search, got)
+	}
+	gg, err := ParseSavedGreps("x grep dir re 'g.*i'\n\nö grep file 'a b'")
+	t.FatalOn(err)
+	t.Eq([]SavedGrep{
+		{Key: 'x', Search: Search{Text: "g.*i", Regexp: true,
+			Scope: DirScope}},
+		{Key: 'ö', Search: Search{Text: "a b"}},
+	}, gg)
+	for _, invalid := range []string{"x grep dir", "xgrep file 'a'",
+		"x grep module 'a'", "x grep file a", "x find file 'a'"} {
+		_, err := ParseSavedGreps(invalid)
+		t.ErrIs(err, ErrSearch)
+	}
+}
+
+func (s *AGrep) Saves_latest_grep_under_a_hotkey(t *T) {
+	fx, vw := grepFX(t, "gini", "gini is gini", "not an ide")
+	fire(fx, "gjksx")
+	fx.FireKey(lines.Enter)
+	t.Eq(cnt.Message("saved grep file 'gini' under x"),
+		vw.Context().(*cnt.Context).Message())
+	fire(fx, "gide")
+	fx.FireKey(lines.Enter)
+	waitFX(t, fx, vw)
+	fire(fx, "gjkrs")
+	t.Contains(fx.ScreenOf(vw.Context()), "x:grep file '…")
+	for vw.Context().(*cnt.Context).Active() {
+		fx.FireKey(lines.Esc)
+	}
+	fire(fx, "*x")
+	waitFX(t, fx, vw)
+	t.Eq("grep file 'gini'", vw.results.Search.String())
+	t.Eq(2, len(vw.results.Matches()))
+}
+
+func (s *AGrep) Rejects_invalid_hotkeys(t *T) {
+	fx, vw := grepFX(t, "gini", "gini is gini")
+	fire(fx, "gjksxy")
+	fx.FireKey(lines.Enter)
+	t.Eq(cnt.Message("save: hotkey must be a single rune"),
+		vw.Context().(*cnt.Context).Message())
+	t.Eq(0, len(vw.SavedGreps.Greps()))
+	fx.FireRune('e')
+	fx.FireRune('g')
+	t.Eq(cnt.Message("saved greps can't be edited"),
+		vw.Context().(*cnt.Context).Message())
+}
+
+// textFX is a modifiable Buffer implementation.
+type textFX struct{ ll []string }
+
+func (b *textFX) Lines() int          { return len(b.ll) }
+func (b *textFX) Line(idx int) string { return b.ll[idx] }
+
+func (b *textFX) Insert(ln, cl int, s string) error {
+	rr := []rune(b.ll[ln])
+	b.ll[ln] = string(rr[:cl]) + s + string(rr[cl:])
+	return nil
+}
+
+func (b *textFX) Delete(ln, cl, n int) (string, error) {
+	rr := []rune(b.ll[ln])
+	b.ll[ln] = string(rr[:cl]) + string(rr[cl+n:])
+	return string(rr[cl : cl+n]), nil
+}
+
+// ctxGrepper greps the current buffer and records the contexts of the
+// greps.
+type ctxGrepper struct {
+	bufferGrep
+	cc []context.Context
+}
+
+func (g *ctxGrepper) Grep(
+	ctx context.Context, b Buffer, s Search,
+) (<-chan Match, error) {
+	g.cc = append(g.cc, ctx)
+	return g.bufferGrep.Grep(ctx, b, s)
+}
+
+func (s *AGrep) Cancels_a_replaced_grep(t *T) {
+	g := &ctxGrepper{}
+	vw := &View{Buffer: &textFX{ll: []string{"gini"}}, Grepper: g}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fire(fx, "ggini")
+	fx.FireKey(lines.Enter)
+	waitFX(t, fx, vw)
+	fx.Lines.Update(vw, nil, func(e *lines.Env) {
+		vw.Grep(e, Search{Text: "gini"})
+	})
+	t.FatalIfNot(t.Eq(2, len(g.cc)))
+	t.ErrIs(g.cc[0].Err(), context.Canceled)
+	t.FatalOn(g.cc[1].Err())
+}
+
+func (s *AGrep) Cancels_its_grep_when_the_results_are_removed(t *T) {
+	g := &ctxGrepper{}
+	vw := &View{Buffer: &textFX{ll: []string{"gini"}}, Grepper: g}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fire(fx, "ggini")
+	fx.FireKey(lines.Enter)
+	waitFX(t, fx, vw)
+	t.FatalIfNot(t.Eq(1, len(g.cc)))
+	t.FatalOn(g.cc[0].Err())
+	fx.Lines.Update(vw, nil, func(e *lines.Env) {
+		t.Eq(vw.results, vw.Columns().CurrentSplit())
+		vw.Columns().Remove(e)
+	})
+	t.ErrIs(g.cc[0].Err(), context.Canceled)
+}
+
+func (s *AGrep) Streams_matches_in_batches(t *T) {
+	mm := make(chan Match, 2*StreamBatch)
+	for i := 0; i < 2*StreamBatch-1; i++ {
+		mm <- Match{Ln: i}
+	}
+	t.Eq(StreamBatch, len(batch(<-mm, mm)))
+	t.Eq(StreamBatch-1, len(batch(<-mm, mm)))
+	ll := make([]string, 2*StreamBatch)
+	for i := range ll {
+		ll[i] = "gini"
+	}
+	_, vw := grepFX(t, "gini", ll...)
+	t.Eq(2*StreamBatch, len(vw.results.Matches()))
+}
+
+func TestAGrep(t *testing.T) {
+	t.Parallel()
+	Run(&AGrep{}, t)
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package view
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/slukits/gini/cmd/gini/view/internal/cnt"
+	"github.com/slukits/gini/cmd/gini/view/internal/edt"
+	"github.com/slukits/lines"
+)
+
+// SavedGrep is a grep saved under a hotkey.
+type SavedGrep struct {
+	Key    rune
+	Search Search
+}
+
+// String returns given saved grep g's text representation: its hotkey
+// followed by a space and its search, e.g. "x grep dir re 'g.*i'".
+func (g SavedGrep) String() string {
+	return string(g.Key) + " " + g.Search.String()
+}
+
+// SavedGreps keep greps saved under hotkeys.
+type SavedGreps interface {
+
+	// Saved saves given search s under given hotkey replacing a grep
+	// which was saved under the same hotkey.
+	Saved(hotkey rune, s Search)
+
+	// Greps returns the saved greps sorted by their hotkeys.
+	Greps() []SavedGrep
+
+	// Buffer returns the saved greps as editable text, one grep per
+	// line (see SavedGrep.String), whose saving replaces the saved greps
+	// by the parsed text (see ParseSavedGreps).  Buffer returns nil if
+	// saved greps can't be edited.
+	Buffer() Buffer
+}
+
+// ErrSearch is returned by ParseSearch and ParseSavedGreps for an
+// invalid search text.
+var ErrSearch = errors.New("gini: view: grep: invalid search")
+
+// ParseSearch parses given search text s (see Search.String).
+func ParseSearch(s string) (Search, error) {
+	if !strings.HasPrefix(s, "grep ") {
+		return Search{}, fmt.Errorf("%w: %s", ErrSearch, s)
+	}
+	scope, text, _ := strings.Cut(strings.TrimPrefix(s, "grep "), " ")
+	search := Search{}
+	switch scope {
+	case FileScope.String():
+	case DirScope.String():
+		search.Scope = DirScope
+	case ProjectScope.String():
+		search.Scope = ProjectScope
+	default:
+		return Search{}, fmt.Errorf("%w: %s", ErrSearch, s)
+	}
+	if strings.HasPrefix(text, "re ") {
+		search.Regexp, text = true, strings.TrimPrefix(text, "re ")
+	}
+	if len(text) < 3 || text[0] != '\'' || text[len(text)-1] != '\'' {
+		return Search{}, fmt.Errorf("%w: %s", ErrSearch, s)
+	}
+	search.Text = text[1 : len(text)-1]
+	return search, nil
+}
+
+// ParseSavedGreps parses given text s of saved greps (see
+// SavedGreps.Buffer) ignoring empty lines.
+func ParseSavedGreps(s string) (gg []SavedGrep, err error) {
+	for _, l := range strings.Split(s, "\n") {
+		if strings.TrimSpace(l) == "" {
+			continue
+		}
+		k, size := utf8.DecodeRuneInString(l)
+		if k == ' ' || !strings.HasPrefix(l[size:], " ") {
+			return nil, fmt.Errorf("%w: %s", ErrSearch, l)
+		}
+		search, err := ParseSearch(l[size+1:])
+		if err != nil {
+			return nil, err
+		}
+		gg = append(gg, SavedGrep{Key: k, Search: search})
+	}
+	return gg, nil
+}
+
+// SortedGreps returns the greps of given map of searches by hotkeys
+// sorted by their hotkeys.
+func SortedGreps(ss map[rune]Search) (gg []SavedGrep) {
+	for k, s := range ss {
+		gg = append(gg, SavedGrep{Key: k, Search: s})
+	}
+	sort.Slice(gg, func(i, j int) bool { return gg[i].Key < gg[j].Key })
+	return gg
+}
+
+// savedGreps are the SavedGreps of a View which wasn't given
+// SavedGreps.  They are kept in memory and can't be edited.
+type savedGreps map[rune]Search
+
+func (gg savedGreps) Saved(k rune, s Search) { gg[k] = s }
+
+func (gg savedGreps) Greps() []SavedGrep { return SortedGreps(gg) }
+
+func (gg savedGreps) Buffer() Buffer { return nil }
+
+// saveContext returns the context saving the latest grep under the
+// hotkey typed into its input box.
+func (v *View) saveContext() *cnt.Item {
+	return &cnt.Item{Label: "save", Key: 's', Input: &cnt.Input{
+		Apply: func(e *lines.Env, k string) {
+			if len(v.searches) == 0 {
+				v.message(e, "save: no grep")
+				return
+			}
+			if utf8.RuneCountInString(k) != 1 || k == " " {
+				v.message(e, "save: hotkey must be a single rune")
+				return
+			}
+			r, _ := utf8.DecodeRuneInString(k)
+			v.SavedGreps.Saved(r, v.searches[0])
+			v.message(e, fmt.Sprintf("saved %s under %s",
+				v.searches[0], k))
+		},
+	}}
+}
+
+// savedEntries returns the entries grepping the saved greps by their
+// hotkeys.
+func (v *View) savedEntries() (ii []*cnt.Item) {
+	for _, g := range v.SavedGreps.Greps() {
+		s := g.Search
+		ii = append(ii, &cnt.Item{Label: abbreviated(s.String()),
+			Key: g.Key, Exec: func(e *lines.Env) { v.Grep(e, s) }})
+	}
+	return ii
+}
+
+// editGreps opens the saved greps for editing in a new split.
+func (v *View) editGreps(e *lines.Env) {
+	b := v.SavedGreps.Buffer()
+	if b == nil {
+		v.message(e, "saved greps can't be edited")
+		return
+	}
+	ed, cc := v.wired(&edt.Editor{Buffer: b}), v.Columns()
+	e.Lines.Update(cc, nil, func(e *lines.Env) { cc.Split(e, ed) })
+}
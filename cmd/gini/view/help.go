@@ -0,0 +1,142 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package view
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/slukits/gini/cmd/gini/view/internal/cnt"
+	"github.com/slukits/gini/cmd/gini/view/internal/edt"
+	"github.com/slukits/lines"
+)
+
+// ContextsHelp prefixes the anchors of the sections of the help page
+// explaining the context bar's entries (see cnt.Item.Help).
+const ContextsHelp = "contexts.gnh#"
+
+// Helper provides the help pages explaining the context bar's entries.
+type Helper interface {
+
+	// Help returns the buffer of the help page containing the section
+	// with given anchor, e.g. "contexts.gnh#lines", and the index of
+	// the section's first line.
+	Help(anchor string) (b Buffer, ln int, err error)
+}
+
+// errNoHelp is returned by the helper of a View which wasn't given a
+// Helper.
+var errNoHelp = errors.New("gini: view: help: no help pages")
+
+// noHelp is the Helper of a View which wasn't given a Helper.
+type noHelp struct{}
+
+func (noHelp) Help(anchor string) (Buffer, int, error) {
+	return nil, 0, fmt.Errorf("%w: %s", errNoHelp, anchor)
+}
+
+// helpCmd returns the command of the app context switching the context
+// bar into the help context.
+func (v *View) helpCmd() *cnt.Item {
+	return &cnt.Item{Label: "help", Key: 'h', Help: ContextsHelp + "help",
+		Exec: func(e *lines.Env) {
+			v.Context().(*cnt.Context).SetIndicator(e, cnt.HelpIndicator)
+		}}
+}
+
+// help shows the help page section with given anchor in the help split
+// which is added below the current split unless it is already shown.
+// The help split is focused unless the context bar stays active, i.e.
+// a context was selected in the help context.
+func (v *View) help(e *lines.Env, anchor string) {
+	b, ln, err := v.Helper.Help(anchor)
+	if err != nil {
+		v.message(e, "help: "+err.Error())
+		return
+	}
+	cc, ed := v.Columns(), v.helping
+	if _, _, ok := cc.Find(ed); !ok {
+		ed = v.wired(&edt.Editor{Buffer: b})
+		v.helping = ed
+		cc.Show(e, ed)
+	}
+	if !v.Context().(*cnt.Context).Active() {
+		col, split, _ := cc.Find(ed)
+		cc.Focus(e, col, split)
+	}
+	e.Lines.Update(cc, nil, func(e *lines.Env) {
+		e.Lines.Update(ed, nil, func(e *lines.Env) {
+			ed.Buffer = b
+			ed.Top(e, ln)
+		})
+	})
+}
+
+// Heading is an entry of the index of a buffer.
+type Heading struct {
+
+	// Title is the heading's text.
+	Title string
+
+	// Level is the heading's depth starting at one.
+	Level int
+
+	// Ln is the index of the heading's line.
+	Ln int
+}
+
+// Indexed is implemented by a Buffer whose content is structured by
+// headings, e.g. a help page.
+type Indexed interface {
+
+	// Index returns the buffer's headings in the order of their lines.
+	Index() []Heading
+}
+
+// indexKeys are the keys of the index entries in the lines context.
+const indexKeys = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// indexEntries returns the commands scrolling the current editor to the
+// headings of its buffer if it is Indexed.
+func (v *View) indexEntries() (ii []*cnt.Item) {
+	ed, ok := v.Columns().CurrentSplit().(*edt.Editor)
+	if !ok {
+		return nil
+	}
+	idx, ok := ed.Buffer.(Indexed)
+	if !ok {
+		return nil
+	}
+	for i, h := range idx.Index() {
+		if i == len(indexKeys) {
+			break
+		}
+		ln := h.Ln
+		ii = append(ii, v.editorCmd(abbreviated(h.Title),
+			rune(indexKeys[i]), false, func(
+				ed *edt.Editor, e *lines.Env,
+			) {
+				ed.Top(e, ln)
+			}))
+	}
+	return ii
+}
@@ -26,15 +26,358 @@ around it.
 package cnt
 
 import (
-	"fmt"
+	"strings"
 
+	"github.com/slukits/gini/cmd/gini/view/internal/edt"
 	"github.com/slukits/lines"
 )
 
+// DefaultContent is displayed by an inactive context bar as long as no
+// status was reported.
 const DefaultContent = "hlp/index.gnh"
 
-type Context struct{ lines.Component }
+// Context is gini's context bar.  An active context bar shows the
+// entries of the current context with highlighted hotkeys.  Typing a
+// hotkey or clicking an entry either enters a nested context or
+// executes a command.  Esc leaves the current context returning to
+// its parent context; Space or Esc in the root context deactivates
+// the context bar.
+type Context struct {
+	lines.Component
+
+	// Root holds the entries of the root context.
+	Root *Item
+
+	// Back returns the component which is focused if the context bar
+	// is deactivated.
+	Back func() lines.Componenter
+
+	// Typed is informed about each rune or key typed into the context
+	// bar, e.g. to record a keyboard macro.
+	Typed func(r rune, k lines.Key, mm lines.ModifierMask)
+
+	// Help shows the help page section with given anchor (see
+	// Item.Help) of an item selected in the help context.
+	Help func(e *lines.Env, anchor string)
+
+	// Registers are given to the input boxes of entered contexts which
+	// have none, e.g. the registers of the editors.
+	Registers edt.Registers
+
+	// stack of entered contexts; the context bar is active iff the
+	// stack is not empty.
+	stack []*Item
+
+	// hits are the x-ranges of the labels of the current context's
+	// entries for mouse selection.
+	hits [][2]int
+
+	// status of the focused split
+	status Status
+
+	// mm is the message history and shown the index of the displayed
+	// message.
+	mm    []Message
+	shown int
+
+	// indicator of the current context; AppIndicator if zero.
+	indicator rune
+}
 
 func (c *Context) OnInit(e *lines.Env) {
-	fmt.Fprint(e, DefaultContent)
+	c.Dim().SetHeight(1)
+	c.FF.Set(lines.Focusable)
+	c.print(e)
+}
+
+// Active returns true if the context bar is active.
+func (c *Context) Active() bool { return len(c.stack) > 0 }
+
+// Current returns the current context of an active context bar or nil.
+func (c *Context) Current() *Item {
+	if len(c.stack) == 0 {
+		return nil
+	}
+	return c.stack[len(c.stack)-1]
+}
+
+// Activate focuses and activates the context bar showing the root
+// context's entries.
+func (c *Context) Activate(e *lines.Env) {
+	if c.Root == nil {
+		return
+	}
+	c.stack = []*Item{c.Root}
+	e.Lines.Focus(c)
+	e.Lines.Update(c, nil, c.print)
+}
+
+// Enter focuses and activates the context bar showing the entries of
+// given context item it which needn't be an entry of the root context,
+// e.g. the context of an editor command.  Esc returns to the root
+// context.  In the help context the item's help is shown as well.
+func (c *Context) Enter(e *lines.Env, it *Item) {
+	if c.Root == nil {
+		return
+	}
+	c.enter(it)
+	c.stack = []*Item{c.Root, it}
+	e.Lines.Focus(c)
+	e.Lines.Update(c, nil, c.print)
+	c.help(e, it)
+}
+
+// OnRune selects the current context's entry with the typed hotkey r.
+func (c *Context) OnRune(e *lines.Env, r rune, mm lines.ModifierMask) {
+	if c.Typed != nil {
+		c.Typed(r, 0, mm)
+	}
+	if c.Type(e, r, 0, mm) {
+		e.StopBubbling()
+	}
+}
+
+// OnKey leaves the current context on Esc discarding its input while
+// Enter applies the input of the current context.
+func (c *Context) OnKey(e *lines.Env, k lines.Key, mm lines.ModifierMask) {
+	if c.Typed != nil {
+		c.Typed(0, k, mm)
+	}
+	if c.Type(e, 0, k, mm) {
+		e.StopBubbling()
+	}
+}
+
+// Type processes given rune r or given key k if r is zero as if it was
+// typed by the user and returns false if the input isn't consumed by
+// the context bar, i.e. it bubbles to the enclosing components.
+func (c *Context) Type(
+	e *lines.Env, r rune, k lines.Key, _ lines.ModifierMask,
+) bool {
+	if !c.Active() {
+		return false
+	}
+	if r != 0 {
+		c.typedRune(e, r)
+		return true
+	}
+	return c.typedKey(e, k)
+}
+
+func (c *Context) typedRune(e *lines.Env, r rune) {
+	if in := c.Current().Input; in != nil {
+		before := in.String()
+		if (r != ' ' || !in.Command()) && in.rune(r) {
+			c.changed(e, in, before)
+			return
+		}
+	}
+	if r == ' ' {
+		c.deactivate(e)
+		return
+	}
+	if r == HelpIndicator && c.Helping() && len(c.stack) == 1 {
+		c.indicator = 0
+		c.deactivate(e)
+		return
+	}
+	if it := c.Current().item(r, c.status.Modified); it != nil {
+		c.selected(e, it)
+	}
+}
+
+func (c *Context) typedKey(e *lines.Env, k lines.Key) bool {
+	in := c.Current().Input
+	switch k {
+	case lines.Esc:
+	case lines.Enter:
+		if in == nil {
+			return false
+		}
+		c.deactivate(e)
+		if in.Apply != nil {
+			in.Apply(e, in.String())
+		}
+		return true
+	default:
+		if in == nil {
+			return false
+		}
+		before := in.String()
+		if in.key(k) {
+			c.changed(e, in, before)
+		}
+		return true
+	}
+	if in != nil && in.Discard != nil {
+		in.Discard(e)
+	}
+	c.stack = c.stack[:len(c.stack)-1]
+	if !c.Active() {
+		if c.Helping() {
+			c.indicator = 0
+		}
+		c.deactivate(e)
+		return true
+	}
+	c.print(e)
+	return true
+}
+
+// OnClick activates an inactive context bar or selects the clicked
+// entry of an active one.
+func (c *Context) OnClick(e *lines.Env, x, _ int) {
+	e.StopBubbling()
+	if !c.Active() {
+		c.Activate(e)
+		return
+	}
+	for i, h := range c.hits {
+		if x >= h[0] && x < h[1] {
+			c.selected(e, c.Current().Items[i])
+			return
+		}
+	}
+}
+
+// OnFocusLost deactivates the context bar without refocusing.
+func (c *Context) OnFocusLost(e *lines.Env) {
+	if !c.Active() {
+		return
+	}
+	c.stack = nil
+	c.print(e)
+}
+
+// changed reports a modification of given input box in to its Change
+// listener and prints it.
+func (c *Context) changed(e *lines.Env, in *Input, before string) {
+	if in.Change != nil && in.String() != before {
+		in.Change(e, in.String())
+	}
+	c.print(e)
+}
+
+// selected enters the context of given item it or executes its command.
+// In the help context a command's help is shown instead of executing it
+// while a context's help is shown next to entering it.
+func (c *Context) selected(e *lines.Env, it *Item) {
+	if it.Exec == nil {
+		c.enter(it)
+		c.stack = append(c.stack, it)
+		c.print(e)
+		c.help(e, it)
+		return
+	}
+	if c.Helping() && c.Help != nil {
+		anchor := c.anchor(it)
+		c.deactivate(e)
+		c.Help(e, anchor)
+		return
+	}
+	if !it.Keep {
+		c.deactivate(e)
+		it.Exec(e)
+		return
+	}
+	it.Exec(e)
+	c.print(e)
+}
+
+// enter prepares given context item it for being entered providing its
+// input box with the context bar's Registers if it has none.
+func (c *Context) enter(it *Item) {
+	it.enter()
+	if it.Input != nil && it.Input.Registers == nil {
+		it.Input.Registers = c.Registers
+	}
+}
+
+// Helping returns true if the context bar is in the help context, i.e.
+// its indicator was set to the HelpIndicator.
+func (c *Context) Helping() bool { return c.indicator == HelpIndicator }
+
+// help shows the help of given context item it in the help context if
+// it has an anchor.
+func (c *Context) help(e *lines.Env, it *Item) {
+	if !c.Helping() || c.Help == nil || c.anchor(it) == "" {
+		return
+	}
+	c.Help(e, c.anchor(it))
+}
+
+// anchor returns the help anchor of given item it which defaults to the
+// anchor of the innermost entered context providing one.
+func (c *Context) anchor(it *Item) string {
+	anchor := it.Help
+	for i := len(c.stack) - 1; anchor == "" && i >= 0; i-- {
+		anchor = c.stack[i].Help
+	}
+	return anchor
+}
+
+// deactivate deactivates the context bar and gives the focus back.
+func (c *Context) deactivate(e *lines.Env) {
+	c.stack = nil
+	c.print(e)
+	if c.Back == nil {
+		return
+	}
+	if back := c.Back(); back != nil {
+		e.Lines.Focus(back)
+	}
+}
+
+// print prints the status of an inactive context bar or the path of
+// entered contexts followed by the current context's input box, entries
+// with highlighted hotkeys and the shown message.  The indicator of an
+// entered context providing one or of the help context is shown at the
+// right end.
+func (c *Context) print(e *lines.Env) {
+	c.hits = nil
+	if e.Lines.CursorComponent() == c &&
+		(!c.Active() || c.Current().Input == nil) {
+		e.Lines.RemoveCursor()
+	}
+	c.Reset(0)
+	if !c.Active() {
+		c.printStatus(e)
+		return
+	}
+	var path []string
+	for _, it := range c.stack[1:] {
+		path = append(path, it.Label)
+	}
+	x := 0
+	if len(path) > 0 {
+		rr := []rune(strings.Join(path, ">") + ":")
+		e.LL(0).At(x).WriteAt(rr)
+		x += len(rr) + 1
+	}
+	if in := c.Current().Input; in != nil {
+		e.LL(0).At(x).WriteAt([]rune("'" + in.String() + "'"))
+		style := lines.BarCursorSteady
+		if in.Command() {
+			style = lines.BlockCursorSteady
+		}
+		c.SetCursor(0, x+1+in.Cursor(), style)
+		x += len(in.rr) + 3
+	}
+	for _, it := range c.Current().Items {
+		if len(c.hits) > 0 {
+			x++
+		}
+		rr, hl := it.label(c.status.Modified)
+		e.LL(0).At(x).WriteAt(rr[:hl])
+		e.LL(0).At(x + hl).AA(lines.Reverse).WriteAt(rr[hl : hl+1])
+		e.LL(0).At(x + hl + 1).WriteAt(rr[hl+1:])
+		c.hits = append(c.hits, [2]int{x, x + len(rr)})
+		x += len(rr)
+	}
+	width := c.Dim().Width()
+	if c.entered() != 0 || c.Helping() {
+		e.LL(0).At(width - 1).WriteAt([]rune{c.Indicator()})
+		width -= 2
+	}
+	c.printMessage(e, x+1, width)
 }
@@ -22,7 +22,10 @@ along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
 package cnt
 
 import (
+	"fmt"
+	"strings"
 	"testing"
+	"time"
 
 	. "github.com/slukits/gounit"
 	"github.com/slukits/lines"
@@ -38,6 +41,398 @@ func (s *AContext) Has_initially_default_content(t *T) {
 	t.Contains(fx.Screen(), DefaultContent)
 }
 
+// bar stacks a context bar and a back component activating the bar
+// on space.
+type bar struct {
+	lines.Component
+	lines.Stacking
+	cnt  *Context
+	back *back
+}
+
+func (b *bar) OnInit(e *lines.Env) {
+	b.CC = append(b.CC, b.cnt, b.back)
+	b.cnt.Back = func() lines.Componenter { return b.back }
+}
+
+func (b *bar) OnAfterInit(e *lines.Env) { e.Lines.Focus(b.back) }
+
+type back struct {
+	lines.Component
+	cnt     *Context
+	focused int
+}
+
+func (b *back) OnFocus(e *lines.Env) { b.focused++ }
+
+func (b *back) OnRune(e *lines.Env, r rune, _ lines.ModifierMask) {
+	if r == ' ' {
+		b.cnt.Activate(e)
+	}
+}
+
+// barFX returns a fixture with an active context bar having the
+// contexts "lines" with commands "down" and "up" and "app" with the
+// command "quit".  Executed commands are recorded.
+func barFX(t *T) (*lines.Fixture, *bar, *[]string) {
+	exec := []string{}
+	cmd := func(name string, keep bool, k rune) *Item {
+		return &Item{Label: name, Key: k, Keep: keep, Exec: func(
+			*lines.Env,
+		) {
+			exec = append(exec, name)
+		}}
+	}
+	c := &Context{Root: &Item{Items: []*Item{
+		{Label: "lines", Key: 'l', Items: []*Item{
+			cmd("down", true, 'd'), cmd("up", true, 'u')}},
+		{Label: "app", Key: '.', Items: []*Item{cmd("quit", false, 'q')}},
+	}}}
+	b := &bar{cnt: c, back: &back{cnt: c}}
+	fx := lines.TermFixture(t.GoT(), 0, b)
+	fx.Lines.Quitting.DelRune('q')
+	fx.FireRune(' ')
+	return fx, b, &exec
+}
+
+func (s *AContext) Highlights_hotkeys_of_root_entries_if_active(t *T) {
+	fx, b, _ := barFX(t)
+	t.Eq("lines .:app", fx.ScreenOf(b.cnt).Trimmed().String())
+	t.True(fx.CellsOf(b.cnt).HasAA(0, 0, lines.Reverse))
+	t.Not.True(fx.CellsOf(b.cnt).HasAA(1, 0, lines.Reverse))
+	t.True(fx.CellsOf(b.cnt).HasAA(6, 0, lines.Reverse))
+}
+
+func (s *AContext) Enters_nested_context_on_hotkey(t *T) {
+	fx, b, _ := barFX(t)
+	fx.FireRune('l')
+	t.Eq("lines: down up", fx.ScreenOf(b.cnt).Trimmed().String())
+	t.True(fx.CellsOf(b.cnt).HasAA(7, 0, lines.Reverse))
+	t.True(fx.CellsOf(b.cnt).HasAA(12, 0, lines.Reverse))
+}
+
+func (s *AContext) Leaves_one_context_level_on_esc(t *T) {
+	fx, b, _ := barFX(t)
+	fx.FireRune('l')
+	fx.FireKey(lines.Esc)
+	t.Eq("lines .:app", fx.ScreenOf(b.cnt).Trimmed().String())
+	t.True(b.cnt.Active())
+	fx.FireKey(lines.Esc)
+	t.Not.True(b.cnt.Active())
+	t.Contains(fx.ScreenOf(b.cnt), DefaultContent)
+	t.Eq(2, b.back.focused)
+}
+
+func (s *AContext) Is_deactivated_by_space_in_any_context(t *T) {
+	fx, b, _ := barFX(t)
+	fx.FireRune('l')
+	fx.FireRune(' ')
+	t.Not.True(b.cnt.Active())
+	t.Contains(fx.ScreenOf(b.cnt), DefaultContent)
+	t.Eq(2, b.back.focused)
+}
+
+func (s *AContext) Executes_commands_keeping_it_active_if_requested(
+	t *T,
+) {
+	fx, b, exec := barFX(t)
+	for _, r := range "ldd" {
+		fx.FireRune(r)
+	}
+	t.Eq([]string{"down", "down"}, *exec)
+	t.True(b.cnt.Active())
+	fx.FireKey(lines.Esc)
+	fx.FireRune('.')
+	fx.FireRune('q')
+	t.Eq([]string{"down", "down", "quit"}, *exec)
+	t.Not.True(b.cnt.Active())
+}
+
+func (s *AContext) Selects_clicked_entries(t *T) {
+	fx, b, exec := barFX(t)
+	fx.FireComponentClick(b.cnt, 1, 0)
+	t.Eq("lines: down up", fx.ScreenOf(b.cnt).Trimmed().String())
+	fx.FireComponentClick(b.cnt, 12, 0)
+	t.Eq([]string{"up"}, *exec)
+}
+
+func (s *AContext) Is_activated_by_a_click(t *T) {
+	fx, b, _ := barFX(t)
+	fx.FireRune(' ')
+	t.Not.True(b.cnt.Active())
+	fx.FireComponentClick(b.cnt, 0, 0)
+	t.True(b.cnt.Active())
+	t.Eq("lines .:app", fx.ScreenOf(b.cnt).Trimmed().String())
+}
+
+// inputBarFX returns a fixture with an active context bar having the
+// context "find" with an input box and the command "word".  Applied,
+// changed and discarded input as well as executed commands are
+// recorded.
+func inputBarFX(t *T) (*lines.Fixture, *bar, *[]string) {
+	rec, now := []string{}, time.Now()
+	in := &Input{
+		Apply:   func(_ *lines.Env, s string) { rec = append(rec, "a:"+s) },
+		Change:  func(_ *lines.Env, s string) { rec = append(rec, "c:"+s) },
+		Discard: func(*lines.Env) { rec = append(rec, "discard") },
+		Lib:     Lib{Now: func() time.Time { return now }},
+	}
+	c := &Context{Root: &Item{Items: []*Item{
+		{Label: "find", Key: 'f', Input: in, Items: []*Item{
+			{Label: "word", Key: 'w', Exec: func(*lines.Env) {
+				rec = append(rec, "word")
+			}},
+		}},
+	}}}
+	b := &bar{cnt: c, back: &back{cnt: c}}
+	fx := lines.TermFixture(t.GoT(), 0, b)
+	fx.Lines.Quitting.DelRune('q')
+	fx.FireRune(' ')
+	fx.FireRune('f')
+	return fx, b, &rec
+}
+
+func (s *AContext) Shows_input_box_of_entered_context(t *T) {
+	fx, b, _ := inputBarFX(t)
+	t.Eq("find: '' word", fx.ScreenOf(b.cnt).Trimmed().String())
+	for _, r := range "4 2" {
+		fx.FireRune(r)
+	}
+	t.Eq("find: '4 2' word", fx.ScreenOf(b.cnt).Trimmed().String())
+	x, y, ok := fx.Lines.CursorPosition()
+	t.True(ok)
+	t.Eq(10, x)
+	t.Eq(0, y)
+}
+
+func (s *AContext) Applies_input_on_enter(t *T) {
+	fx, b, rec := inputBarFX(t)
+	fx.FireRune('4')
+	fx.FireRune('2')
+	fx.FireKey(lines.Enter)
+	t.Eq([]string{"c:4", "c:42", "a:42"}, *rec)
+	t.Not.True(b.cnt.Active())
+	_, _, ok := fx.Lines.CursorPosition()
+	t.Not.True(ok)
+}
+
+func (s *AContext) Discards_input_on_esc(t *T) {
+	fx, b, rec := inputBarFX(t)
+	fx.FireRune('4')
+	fx.FireKey(lines.Esc)
+	t.Eq([]string{"c:4", "discard"}, *rec)
+	t.True(b.cnt.Active())
+	t.Eq("find", fx.ScreenOf(b.cnt).Trimmed().String())
+}
+
+func (s *AContext) Selects_entries_from_input_command_mode(t *T) {
+	fx, b, rec := inputBarFX(t)
+	for _, r := range "4jkw" {
+		fx.FireRune(r)
+	}
+	t.Eq([]string{"c:4", "c:4j", "c:4", "word"}, *rec)
+	t.Not.True(b.cnt.Active())
+}
+
+func (s *AContext) Shows_reported_status_with_indicator(t *T) {
+	c := &Context{}
+	fx := lines.TermFixture(t.GoT(), 0, c)
+	fx.Lines.Update(c, Status{Ln: 3, Cl: 7, Mode: "ins",
+		File: "hlp/index.gnh"}, nil)
+	str := fx.ScreenOf(c).String()
+	t.True(strings.HasPrefix(str, "ln: 3  cl: 7  mod:ins  hlp/index.gnh"))
+	t.True(strings.HasSuffix(str, string(AppIndicator)))
+	fx.Lines.Update(c, Status{File: "a.go", Modified: true}, nil)
+	t.True(strings.HasSuffix(
+		fx.ScreenOf(c).String(), string(ModifiedIndicator)))
+	fx.Lines.Update(c, nil, func(e *lines.Env) {
+		c.SetIndicator(e, HelpIndicator)
+	})
+	t.True(strings.HasSuffix(
+		fx.ScreenOf(c).String(), string(HelpIndicator)))
+}
+
+func (s *AContext) Omits_the_file_of_an_unfiled_buffer(t *T) {
+	c := &Context{}
+	fx := lines.TermFixture(t.GoT(), 0, c)
+	fx.Lines.Update(c, Status{Ln: 3, Cl: 7, Mode: "cmd"}, nil)
+	str := fx.ScreenOf(c).String()
+	t.True(strings.HasPrefix(str, "ln: 3  cl: 7  mod:cmd "))
+	t.Not.Contains(str, DefaultContent)
+}
+
+func (s *AContext) Shows_messages_and_browses_their_history(t *T) {
+	c := &Context{}
+	fx := lines.TermFixture(t.GoT(), 0, c)
+	fx.Lines.Update(c, Status{File: "a.go"}, nil)
+	fx.Lines.Update(c, Message("first"), nil)
+	fx.Lines.Update(c, Message("second"), nil)
+	t.Contains(fx.ScreenOf(c), "[second]")
+	t.Eq(2, len(c.Messages()))
+	fx.Lines.Update(c, nil, func(e *lines.Env) { c.Older(e) })
+	t.Contains(fx.ScreenOf(c), "[first]")
+	fx.Lines.Update(c, nil, func(e *lines.Env) { c.Older(e) })
+	t.Eq(Message("first"), c.Message())
+	fx.Lines.Update(c, nil, func(e *lines.Env) { c.Newer(e) })
+	t.Contains(fx.ScreenOf(c), "[second]")
+}
+
+func (s *AContext) Truncates_messages_which_dont_fit(t *T) {
+	c := &Context{}
+	fx := lines.TermFixture(t.GoT(), 0, c)
+	fx.Lines.Update(c, Status{File: "a.go"}, nil)
+	fx.Lines.Update(c, Message(strings.Repeat("x", 100)), nil)
+	str := fx.ScreenOf(c).String()
+	t.True(strings.HasPrefix(str, "ln: 0  cl: 0  mod:  a.go  [xx"))
+	t.True(strings.HasSuffix(str, "xx…] "+string(AppIndicator)))
+}
+
+func (s *AContext) Replaces_entries_keys_if_file_is_modified(t *T) {
+	fx, b, _ := barFX(t)
+	b.cnt.Root.Items = append(b.cnt.Root.Items, &Item{
+		Label: "/", Key: '/', Modified: '!', Items: []*Item{
+			{Label: "x", Key: 'x', Exec: func(*lines.Env) {}}}})
+	fx.Lines.Update(b.cnt, Status{File: "a.go", Modified: true}, nil)
+	t.Eq("lines .:app !", fx.ScreenOf(b.cnt).Trimmed().String())
+	fx.FireRune('!')
+	t.Eq("/: x", fx.ScreenOf(b.cnt).Trimmed().String())
+}
+
+func (s *AContext) Enters_given_context_and_returns_to_root_on_esc(
+	t *T,
+) {
+	fx, b, _ := barFX(t)
+	fx.FireRune(' ')
+	fx.Lines.Update(b.back, nil, func(e *lines.Env) {
+		b.cnt.Enter(e, &Item{Label: "til", Items: []*Item{
+			{Label: "word", Key: 'w', Exec: func(*lines.Env) {}}}})
+	})
+	t.True(b.cnt.Active())
+	t.Eq("til: word", fx.ScreenOf(b.cnt).Trimmed().String())
+	fx.FireKey(lines.Esc)
+	t.Eq("lines .:app", fx.ScreenOf(b.cnt).Trimmed().String())
+}
+
+func (s *AContext) Lists_entries_of_entered_context(t *T) {
+	fx, b, exec := barFX(t)
+	ring := []string{"gini"}
+	b.cnt.Root.Items = append(b.cnt.Root.Items, &Item{
+		Label: "ring", Key: 'r', List: func() (ii []*Item) {
+			for i, s := range ring {
+				s := s
+				ii = append(ii, &Item{Label: s, Key: rune('0' + i),
+					Exec: func(*lines.Env) {
+						*exec = append(*exec, s)
+					}})
+			}
+			return ii
+		}})
+	fx.FireRune('r')
+	t.Eq("ring: 0:gini", fx.ScreenOf(b.cnt).Trimmed().String())
+	fx.FireKey(lines.Esc)
+	ring = append(ring, "ide")
+	fx.FireRune('r')
+	t.Eq("ring: 0:gini 1:ide", fx.ScreenOf(b.cnt).Trimmed().String())
+	fx.FireRune('1')
+	t.Eq([]string{"ide"}, *exec)
+}
+
+func (s *AContext) Pages_entries_of_a_list(t *T) {
+	var ii []*Item
+	for i := 0; i < 2*PageSize+1; i++ {
+		ii = append(ii, &Item{Label: fmt.Sprint(i)})
+	}
+	ii = Paged(ii)
+	t.Eq(PageSize+1, len(ii))
+	t.Eq('9', ii[PageSize-1].Key)
+	t.Eq('>', ii[PageSize].Key)
+	next := ii[PageSize].Items
+	t.Eq("10", next[0].Label)
+	t.Eq('0', next[0].Key)
+	t.Eq("20", next[PageSize].Items[0].Label)
+	t.Eq(1, len(next[PageSize].Items))
+}
+
+func (s *AContext) Labels_settings_with_their_origin(t *T) {
+	t.Eq("backups=3(default)", Setting{Name: "backups", Value: "3",
+		Origin: "default"}.String())
+	t.Eq("backups=5(project /repo/.gini/settings.json)", Setting{
+		Name: "backups", Value: "5", Origin: "project",
+		Source: "/repo/.gini/settings.json"}.String())
+}
+
+func (s *AContext) Sets_values_of_listed_settings_in_chosen_scope(
+	t *T,
+) {
+	fx, b, exec := barFX(t)
+	b.cnt.Root.Items = append(b.cnt.Root.Items, Settings("settings", '#',
+		func() []Setting {
+			return []Setting{{Name: "backups", Value: "3",
+				Origin: "default"}}
+		},
+		func(_ *lines.Env, name, value, scope string) {
+			*exec = append(*exec, name+"="+value+"@"+scope)
+		}))
+	fx.FireRune('#')
+	str := fx.ScreenOf(b.cnt).Trimmed().String()
+	t.True(strings.HasPrefix(str, "settings: 0:backups=3(default)"))
+	t.True(strings.HasSuffix(str, string(SettingsIndicator)))
+	t.Eq(SettingsIndicator, b.cnt.Indicator())
+	fx.FireRune('0')
+	t.Contains(fx.ScreenOf(b.cnt), "file dir project global")
+	fx.FireRune('p')
+	fx.FireRune('5')
+	fx.FireKey(lines.Enter)
+	t.Eq([]string{"backups=5@project"}, *exec)
+	t.Not.True(b.cnt.Active())
+	t.Eq(AppIndicator, b.cnt.Indicator())
+}
+
+func (s *AContext) Shows_help_of_selected_entries_in_help_context(
+	t *T,
+) {
+	fx, b, exec := barFX(t)
+	anchors := []string{}
+	b.cnt.Help = func(_ *lines.Env, anchor string) {
+		anchors = append(anchors, anchor)
+	}
+	b.cnt.Root.Items[0].Help = "#lines"
+	b.cnt.Root.Items[1].Items[0].Help = "#quit"
+	fx.Lines.Update(b.cnt, nil, func(e *lines.Env) {
+		b.cnt.SetIndicator(e, HelpIndicator)
+	})
+	t.True(b.cnt.Helping())
+	fx.FireRune('l')
+	t.True(b.cnt.Active())
+	fx.FireRune('d')
+	t.Not.True(b.cnt.Active())
+	for _, r := range " .q" {
+		fx.FireRune(r)
+	}
+	t.Eq([]string{"#lines", "#lines", "#quit"}, anchors)
+	t.Eq(0, len(*exec))
+	fx.FireRune(' ')
+	fx.FireRune('?')
+	t.Not.True(b.cnt.Helping())
+	t.Not.True(b.cnt.Active())
+	for _, r := range " ld" {
+		fx.FireRune(r)
+	}
+	t.Eq([]string{"down"}, *exec)
+}
+
+func (s *AContext) Leaves_help_context_on_esc_in_root_context(t *T) {
+	fx, b, _ := barFX(t)
+	fx.Lines.Update(b.cnt, nil, func(e *lines.Env) {
+		b.cnt.SetIndicator(e, HelpIndicator)
+	})
+	t.True(strings.HasSuffix(
+		fx.ScreenOf(b.cnt).String(), string(HelpIndicator)))
+	fx.FireKey(lines.Esc)
+	t.Not.True(b.cnt.Helping())
+	t.Eq(AppIndicator, b.cnt.Indicator())
+}
+
 func TestAContext(t *testing.T) {
 	t.Parallel()
 	Run(&AContext{}, t)
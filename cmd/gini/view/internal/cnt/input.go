@@ -0,0 +1,287 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package cnt
+
+import (
+	"time"
+
+	"github.com/slukits/gini/cmd/gini/view/internal/edt"
+	"github.com/slukits/lines"
+)
+
+// Input is a single-line input box which is shown in the context bar
+// if its context is entered.  Typed runes are inserted at the cursor
+// until a "jk"-chord switches to the mini command mode which supports:
+//
+//	i: switch back to insert mode
+//	h, l: move the cursor one rune left or right
+//	H, L: move the cursor left or right to the next input rune
+//	c, C: copy to the right or left until the next input rune
+//	d, D: delete to the right or left until the next input rune
+//	p, P: paste the last copy/deletion after or before the cursor
+//
+// whereas ^ and $ as next input rune target the input's beginning and
+// end respectively.  Copies and deletions are shared with the input's
+// Registers if it has any, i.e. p and P paste their latest text.
+// Runes without mini command select the entries of the input's
+// context.  Enter applies the input and leaves the context bar while
+// Esc discards it and leaves the input's context.  An Input's zero
+// value is ready to use.
+type Input struct {
+
+	// Apply is called with the input if the user hits Enter.
+	Apply func(e *lines.Env, input string)
+
+	// Change is called with the input after each modification, e.g.
+	// to preview the effect of the input.
+	Change func(e *lines.Env, input string)
+
+	// Discard is called if the user hits Esc, e.g. to undo the effect
+	// of previewed input.
+	Discard func(e *lines.Env)
+
+	// Registers receive the copies and deletions of the mini command
+	// mode and provide the pasted text, e.g. the registers of the
+	// editors.  Without Registers an Input pastes its own latest copy
+	// or deletion.
+	Registers edt.Registers
+
+	// Lib provides std-lib functions an Input needs.
+	Lib Lib
+
+	rr      []rune
+	cl      int
+	cmd     bool
+	pending func(rune)
+	yanked  []rune
+	chord   time.Time
+	initLib bool
+}
+
+// Lib provides std-lib functions which are replaced for testing.
+type Lib struct {
+
+	// Now defaults to time.Now and is used for the "jk"-chord
+	// detection.
+	Now func() time.Time
+}
+
+func (in *Input) lib() Lib {
+	if !in.initLib {
+		in.initLib = true
+		if in.Lib.Now == nil {
+			in.Lib.Now = time.Now
+		}
+	}
+	return in.Lib
+}
+
+// String returns given input box in's content.
+func (in *Input) String() string { return string(in.rr) }
+
+// Cursor returns the rune index of given input box in's cursor.
+func (in *Input) Cursor() int { return in.cl }
+
+// Command returns true if given input box in is in mini command mode.
+func (in *Input) Command() bool { return in.cmd }
+
+// Set replaces given input box in's content with given string s and
+// switches to insert mode with the cursor at the end.
+func (in *Input) Set(s string) {
+	in.rr, in.cmd, in.pending = []rune(s), false, nil
+	in.cl, in.chord = len(in.rr), time.Time{}
+}
+
+// rune processes given rune r and returns false if r is neither input
+// nor a mini command.
+func (in *Input) rune(r rune) bool {
+	if in.pending != nil {
+		pending := in.pending
+		in.pending = nil
+		pending(r)
+		return true
+	}
+	if in.cmd {
+		return in.command(r)
+	}
+	now := in.lib().Now()
+	if r == 'k' && !in.chord.IsZero() &&
+		now.Sub(in.chord) <= edt.ChordTimeout && in.cl > 0 &&
+		in.rr[in.cl-1] == 'j' {
+		in.cl--
+		in.delete(in.cl, in.cl+1)
+		in.cmd, in.chord = true, time.Time{}
+		return true
+	}
+	in.insert(in.cl, []rune{r})
+	in.cl++
+	in.chord = time.Time{}
+	if r == 'j' {
+		in.chord = now
+	}
+	return true
+}
+
+// key processes given key k and returns false if k has no effect.
+func (in *Input) key(k lines.Key) bool {
+	switch k {
+	case lines.Left:
+		in.move(in.cl - 1)
+	case lines.Right:
+		in.move(in.cl + 1)
+	case lines.Home:
+		in.move(0)
+	case lines.End:
+		in.move(len(in.rr))
+	case lines.Backspace, lines.DEL:
+		if in.cl == 0 {
+			return true
+		}
+		in.cl--
+		in.delete(in.cl, in.cl+1)
+	case lines.Delete:
+		if in.cl < len(in.rr) {
+			in.delete(in.cl, in.cl+1)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// command executes given mini command r.
+func (in *Input) command(r rune) bool {
+	switch r {
+	case 'i':
+		in.cmd = false
+	case 'h':
+		in.move(in.cl - 1)
+	case 'l':
+		in.move(in.cl + 1)
+	case 'H':
+		in.til(-1, false, in.move)
+	case 'L':
+		in.til(1, false, in.move)
+	case 'c', 'C', 'd', 'D':
+		dir := 1
+		if r == 'C' || r == 'D' {
+			dir = -1
+		}
+		in.til(dir, true, func(to int) {
+			from := in.cl
+			if to < from {
+				from, to = to, from
+			}
+			in.yanked = append([]rune{}, in.rr[from:to]...)
+			if r == 'c' || r == 'C' {
+				in.copied(string(in.yanked))
+				return
+			}
+			in.deleted(string(in.yanked))
+			in.delete(from, to)
+			in.cl = from
+		})
+	case 'p':
+		in.paste(in.cl + 1)
+	case 'P':
+		in.paste(in.cl)
+	default:
+		return false
+	}
+	return true
+}
+
+// til makes the next typed rune the target of given function exec
+// which is called with the index of the target's first occurrence in
+// given direction dir from the cursor.  An exclusive backward target
+// is passed as the index following the occurrence.  ^ and $ target the
+// input's beginning and end.  exec isn't called if there is no target.
+func (in *Input) til(dir int, exclusive bool, exec func(int)) {
+	in.pending = func(r rune) {
+		switch r {
+		case '^':
+			exec(0)
+			return
+		case '$':
+			exec(len(in.rr))
+			return
+		}
+		for i := in.cl + dir; i >= 0 && i < len(in.rr); i += dir {
+			if in.rr[i] != r {
+				continue
+			}
+			if exclusive && dir < 0 {
+				i++
+			}
+			exec(i)
+			return
+		}
+	}
+}
+
+func (in *Input) copied(s string) {
+	if in.Registers != nil {
+		in.Registers.Copied(s)
+	}
+}
+
+func (in *Input) deleted(s string) {
+	if in.Registers != nil {
+		in.Registers.Deleted(s)
+	}
+}
+
+// paste inserts the latest text of the input's Registers or its own
+// latest copy or deletion if it has no Registers at given index.
+func (in *Input) paste(at int) {
+	yanked := in.yanked
+	if in.Registers != nil {
+		yanked = []rune(in.Registers.Latest())
+	}
+	if len(yanked) == 0 {
+		return
+	}
+	if at > len(in.rr) {
+		at = len(in.rr)
+	}
+	in.insert(at, yanked)
+	in.cl = at + len(yanked)
+}
+
+func (in *Input) move(cl int) {
+	if cl < 0 {
+		cl = 0
+	}
+	if cl > len(in.rr) {
+		cl = len(in.rr)
+	}
+	in.cl = cl
+}
+
+func (in *Input) insert(at int, rr []rune) {
+	in.rr = append(in.rr[:at], append(append([]rune{}, rr...),
+		in.rr[at:]...)...)
+}
+
+func (in *Input) delete(from, to int) {
+	in.rr = append(in.rr[:from], in.rr[to:]...)
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package cnt
+
+import (
+	"testing"
+	"time"
+
+	"github.com/slukits/gini/cmd/gini/view/internal/edt"
+	. "github.com/slukits/gounit"
+	"github.com/slukits/lines"
+)
+
+type AnInput struct{ Suite }
+
+func (s *AnInput) SetUp(t *T) { t.Parallel() }
+
+// inputFX returns an input box with given content in mini command mode
+// whose cursor is at given column cl.
+func inputFX(content string, cl int) *Input {
+	in := &Input{}
+	in.Set(content)
+	in.cmd, in.cl = true, cl
+	return in
+}
+
+func typ(in *Input, rr string) {
+	for _, r := range rr {
+		in.rune(r)
+	}
+}
+
+func (s *AnInput) Inserts_typed_runes_at_the_cursor(t *T) {
+	in := &Input{}
+	typ(in, "gni")
+	in.key(lines.Left)
+	in.key(lines.Left)
+	typ(in, "i")
+	t.Eq("gini", in.String())
+	t.Eq(2, in.Cursor())
+}
+
+func (s *AnInput) Switches_to_command_mode_on_jk_chord(t *T) {
+	now := time.Now()
+	in := &Input{Lib: Lib{Now: func() time.Time { return now }}}
+	typ(in, "gjk")
+	t.Eq("g", in.String())
+	t.True(in.Command())
+	typ(in, "i")
+	t.Not.True(in.Command())
+	typ(in, "j")
+	now = now.Add(time.Second)
+	typ(in, "k")
+	t.Eq("gjk", in.String())
+}
+
+func (s *AnInput) Moves_in_command_mode(t *T) {
+	in := inputFX("forty two", 0)
+	typ(in, "l")
+	t.Eq(1, in.Cursor())
+	typ(in, "Lt")
+	t.Eq(3, in.Cursor())
+	typ(in, "L$")
+	t.Eq(9, in.Cursor())
+	typ(in, "Ho")
+	t.Eq(8, in.Cursor())
+	typ(in, "H^")
+	t.Eq(0, in.Cursor())
+	typ(in, "h")
+	t.Eq(0, in.Cursor())
+	typ(in, "Lx")
+	t.Eq(0, in.Cursor())
+}
+
+func (s *AnInput) Deletes_until_target(t *T) {
+	in := inputFX("forty two", 0)
+	typ(in, "d ")
+	t.Eq(" two", in.String())
+	in = inputFX("forty two", 8)
+	typ(in, "D ")
+	t.Eq("forty o", in.String())
+	t.Eq(6, in.Cursor())
+	in = inputFX("forty two", 5)
+	typ(in, "d$")
+	t.Eq("forty", in.String())
+	in = inputFX("forty two", 5)
+	typ(in, "D^")
+	t.Eq(" two", in.String())
+}
+
+func (s *AnInput) Pastes_last_copy_or_deletion(t *T) {
+	in := inputFX("forty two", 0)
+	typ(in, "c ")
+	t.Eq("forty two", in.String())
+	typ(in, "L$P")
+	t.Eq("forty twoforty", in.String())
+	in = inputFX("forty two", 5)
+	typ(in, "D^hp")
+	t.Eq(" fortytwo", in.String())
+	in = inputFX("forty two", 9)
+	typ(in, "C P")
+	t.Eq("forty twotwo", in.String())
+}
+
+func (s *AnInput) Shares_copies_and_deletions_with_its_registers(
+	t *T,
+) {
+	in := inputFX("forty two", 0)
+	in.Registers = &edt.Rings{}
+	typ(in, "c ")
+	t.Eq([]string{"forty"}, in.Registers.Copies())
+	typ(in, "L$D^")
+	t.Eq([]string{"forty two"}, in.Registers.Deletions())
+	in.Registers.Copied("gini")
+	typ(in, "p")
+	t.Eq("gini", in.String())
+}
+
+func (s *AnInput) Edits_with_backspace_and_delete(t *T) {
+	in := &Input{}
+	in.Set("gini")
+	in.key(lines.Backspace)
+	in.key(lines.Home)
+	in.key(lines.Delete)
+	t.Eq("in", in.String())
+	in.key(lines.Backspace)
+	t.Eq("in", in.String())
+}
+
+func TestAnInput(t *testing.T) {
+	t.Parallel()
+	Run(&AnInput{}, t)
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package cnt
+
+import "github.com/slukits/lines"
+
+// Item is an entry of the context bar which is either a command, i.e.
+// it has Exec, or a context holding Items.  An Item is selected by
+// typing its Key while the context bar is active or by clicking on its
+// Label.
+type Item struct {
+
+	// Label is displayed in the context bar; the first occurrence of
+	// Key in Label is highlighted.
+	Label string
+
+	// Key selects the item in an active context bar.
+	Key rune
+
+	// Items are the entries of the context which is entered if the
+	// item is selected.
+	Items []*Item
+
+	// List provides the Items of a context each time it is entered,
+	// e.g. the entries of a ring.
+	List func() []*Item
+
+	// Exec is executed if the item is selected.  The context bar is
+	// left afterwards unless Keep is set.
+	Exec func(*lines.Env)
+
+	// Input is shown in the context bar if the item's context is
+	// entered and receives the typed runes.
+	Input *Input
+
+	// Keep keeps a context bar active after Exec was executed so the
+	// command may be repeated, e.g. scrolling down several pages.
+	Keep bool
+
+	// Modified replaces Key and Label while the focused file is
+	// modified, e.g. the directory context '/' is shown as '!'.
+	Modified rune
+
+	// Indicator replaces the current context's indicator while the
+	// item's context or one of its nested contexts is entered, e.g.
+	// the SettingsIndicator.
+	Indicator rune
+
+	// Help is the anchor of the help page section explaining the item,
+	// e.g. "contexts.gnh#lines"; it defaults to the anchor of the
+	// enclosing context.
+	Help string
+}
+
+// PageSize is the number of entries of a list shown at once, i.e. the
+// number of index keys.
+const PageSize = 10
+
+// Paged keys given entries ii of a list by their indices on a page of
+// PageSize entries.  The entries not fitting on a page are listed in
+// the context '>' of the page which shows the next page; Esc returns to
+// the previous page.
+func Paged(ii []*Item) []*Item {
+	for i, it := range ii {
+		if i == PageSize {
+			return append(ii[:i:i], &Item{Label: "more", Key: '>',
+				Items: Paged(ii[i:])})
+		}
+		it.Key = rune('0' + i)
+	}
+	return ii
+}
+
+// Item returns given item i's entry with given key k or nil if there
+// is no such entry.
+func (i *Item) Item(k rune) *Item { return i.item(k, false) }
+
+// item returns given item i's entry with given key k whereas the
+// Modified key replaces the Key of entries providing one if given
+// modified flag is set.
+func (i *Item) item(k rune, modified bool) *Item {
+	for _, it := range i.Items {
+		if it.key(modified) == k {
+			return it
+		}
+	}
+	return nil
+}
+
+func (i *Item) key(modified bool) rune {
+	if modified && i.Modified != 0 {
+		return i.Modified
+	}
+	return i.Key
+}
+
+// enter prepares given context item i for being entered.
+func (i *Item) enter() {
+	if i.Input != nil {
+		i.Input.Set("")
+	}
+	if i.List != nil {
+		i.Items = i.List()
+	}
+}
+
+// label returns the displayed label of given item i and the index of
+// its highlighted key.  A Modified key replaces the label if given
+// modified flag is set.
+func (i *Item) label(modified bool) (rr []rune, hl int) {
+	if modified && i.Modified != 0 {
+		return []rune{i.Modified}, 0
+	}
+	rr = []rune(i.Label)
+	for j, r := range rr {
+		if r == i.Key {
+			return rr, j
+		}
+	}
+	return append([]rune{i.Key, ':'}, rr...), 0
+}
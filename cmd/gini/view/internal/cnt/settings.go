@@ -0,0 +1,98 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package cnt
+
+import (
+	"fmt"
+
+	"github.com/slukits/lines"
+)
+
+// SettingsScopes are the names of the scopes a setting may be set in
+// from the most specific to the most general.
+var SettingsScopes = []string{"file", "dir", "project", "global"}
+
+// Setting is the effective value of a setting and the scope it
+// originates from as it is shown in a settings context.
+type Setting struct {
+
+	// Name identifies the setting.
+	Name string
+
+	// Value is the setting's effective value.
+	Value string
+
+	// Origin is the scope the value is set in, e.g. "project" or
+	// "default".
+	Origin string
+
+	// Source is the path of the settings file the value is set in or
+	// empty for a default value.
+	Source string
+}
+
+// String returns given setting s as it is labeled in a settings
+// context, e.g. "backups=3(default)" or
+// "backups=5(project /repo/.gini/settings.json)".
+func (s Setting) String() string {
+	if s.Source == "" {
+		return fmt.Sprintf("%s=%s(%s)", s.Name, s.Value, s.Origin)
+	}
+	return fmt.Sprintf("%s=%s(%s %s)", s.Name, s.Value, s.Origin,
+		s.Source)
+}
+
+// Settings returns a context item with given label and key listing the
+// settings provided by given function ss whose entries are Paged.  A
+// setting's entry holds the SettingsScopes each of which has an input
+// box applying its input as the setting's value in this scope by
+// calling given set function.  The context bar shows the
+// SettingsIndicator while a settings context is entered.
+func Settings(
+	label string, k rune,
+	ss func() []Setting,
+	set func(e *lines.Env, name, value, scope string),
+) *Item {
+	return &Item{Label: label, Key: k, Indicator: SettingsIndicator,
+		List: func() (ii []*Item) {
+			for _, s := range ss() {
+				ii = append(ii, &Item{Label: s.String(),
+					Items: scopes(s.Name, set)})
+			}
+			return Paged(ii)
+		}}
+}
+
+// scopes returns the entries of a setting's context setting the value
+// of the setting with given name in the respective scope.
+func scopes(
+	name string, set func(e *lines.Env, name, value, scope string),
+) (ii []*Item) {
+	for _, scope := range SettingsScopes {
+		scope := scope
+		ii = append(ii, &Item{Label: scope, Key: rune(scope[0]),
+			Input: &Input{Apply: func(e *lines.Env, value string) {
+				set(e, name, value, scope)
+			}}})
+	}
+	return ii
+}
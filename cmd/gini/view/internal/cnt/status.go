@@ -0,0 +1,199 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package cnt
+
+import (
+	"fmt"
+
+	"github.com/slukits/lines"
+)
+
+// Indicators are displayed at the right end of an inactive context bar
+// telling in which context selected elements are interpreted.
+const (
+
+	// AppIndicator is the indicator of the default context in which
+	// selected elements are executed.
+	AppIndicator = '.'
+
+	// HelpIndicator is the indicator of the help context in which the
+	// help of selected elements is shown.
+	HelpIndicator = '?'
+
+	// SettingsIndicator is the indicator of the settings context in
+	// which the settings of selected elements are shown.
+	SettingsIndicator = '#'
+
+	// ModifiedIndicator replaces the AppIndicator and the directory
+	// context's key '/' if the focused file was modified.
+	ModifiedIndicator = '!'
+)
+
+// MaxMessages is the number of messages a context bar's history keeps.
+const MaxMessages = 100
+
+// Status is the state of the focused split which is displayed by an
+// inactive context bar.  It is reported by posting it as update data
+// to the context bar.
+type Status struct {
+
+	// Ln and Cl are the cursor position.
+	Ln, Cl int
+
+	// Mode is the abbreviated editing mode, e.g. "cmd".
+	Mode string
+
+	// File is the displayed path of the focused file.
+	File string
+
+	// Modified is true if the focused file has unsaved modifications.
+	Modified bool
+}
+
+// Message is shown in the message area of a context bar and recorded
+// in its history.  It is reported by posting it as update data to the
+// context bar.
+type Message string
+
+// OnUpdate sets given update data if it is a Status or adds it to the
+// message history if it is a Message.
+func (c *Context) OnUpdate(e *lines.Env, data interface{}) {
+	switch d := data.(type) {
+	case Status:
+		c.status = d
+	case Message:
+		c.mm = append(c.mm, d)
+		if len(c.mm) > MaxMessages {
+			c.mm = c.mm[len(c.mm)-MaxMessages:]
+		}
+		c.shown = len(c.mm) - 1
+	default:
+		return
+	}
+	c.print(e)
+}
+
+// Status returns the last reported status.
+func (c *Context) Status() Status { return c.status }
+
+// Messages returns the message history from the oldest to the latest
+// message.
+func (c *Context) Messages() []Message { return c.mm }
+
+// Message returns the currently shown message.
+func (c *Context) Message() Message {
+	if len(c.mm) == 0 {
+		return ""
+	}
+	return c.mm[c.shown]
+}
+
+// Older shows the message preceding the currently shown message.
+func (c *Context) Older(e *lines.Env) { c.browse(e, -1) }
+
+// Newer shows the message following the currently shown message.
+func (c *Context) Newer(e *lines.Env) { c.browse(e, 1) }
+
+func (c *Context) browse(e *lines.Env, d int) {
+	if len(c.mm) == 0 {
+		return
+	}
+	c.shown = clamp(c.shown+d, len(c.mm))
+	e.Lines.Update(c, nil, c.print)
+}
+
+// Indicator returns the indicator of the current context which is the
+// indicator of the innermost entered context providing one.
+func (c *Context) Indicator() rune {
+	switch {
+	case c.entered() != 0:
+		return c.entered()
+	case c.indicator != 0:
+		return c.indicator
+	case c.status.Modified:
+		return ModifiedIndicator
+	}
+	return AppIndicator
+}
+
+// entered returns the indicator of the innermost entered context
+// providing one or zero.
+func (c *Context) entered() rune {
+	for i := len(c.stack) - 1; i >= 0; i-- {
+		if c.stack[i].Indicator != 0 {
+			return c.stack[i].Indicator
+		}
+	}
+	return 0
+}
+
+// SetIndicator sets the indicator of the current context to given rune
+// r, e.g. HelpIndicator.
+func (c *Context) SetIndicator(e *lines.Env, r rune) {
+	c.indicator = r
+	e.Lines.Update(c, nil, c.print)
+}
+
+// printStatus prints the status line of an inactive context bar which
+// is the default content as long as no status was reported.  The file
+// is omitted for a buffer without file.
+func (c *Context) printStatus(e *lines.Env) {
+	if c.status == (Status{}) {
+		e.LL(0).At(0).WriteAt([]rune(DefaultContent))
+		return
+	}
+	status := fmt.Sprintf("ln: %d  cl: %d  mod:%s",
+		c.status.Ln, c.status.Cl, c.status.Mode)
+	if c.status.File != "" {
+		status += "  " + c.status.File
+	}
+	rr := []rune(status)
+	e.LL(0).At(0).WriteAt(rr)
+	width := c.Dim().Width()
+	c.printMessage(e, len(rr)+2, width-2)
+	e.LL(0).At(width - 1).WriteAt([]rune{c.Indicator()})
+}
+
+// printMessage prints the shown message right aligned ending before
+// given end and starting at given start at the earliest.  A message
+// which doesn't fit is truncated by an ellipsis.
+func (c *Context) printMessage(e *lines.Env, start, end int) {
+	if len(c.mm) == 0 || end-start < 3 {
+		return
+	}
+	rr := []rune(string(c.mm[c.shown]))
+	if len(rr)+2 > end-start {
+		rr = append(rr[:end-start-3], '…')
+	}
+	rr = append(append([]rune{'['}, rr...), ']')
+	e.LL(0).At(end - len(rr)).WriteAt(rr)
+}
+
+func clamp(i, n int) int {
+	if i >= n {
+		i = n - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	return i
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package edt
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/slukits/lines"
+)
+
+// Buffer provides an Editor's content and receives its modifications.
+// Lines and columns are zero-based while columns count runes.
+type Buffer interface {
+
+	// Lines returns the number of lines of the buffer.
+	Lines() int
+
+	// Line returns the content of the line with given index.
+	Line(int) string
+
+	// Insert inserts given string at given line and column.
+	Insert(ln, cl int, s string) error
+
+	// Delete deletes given number of runes at given line and column
+	// whereas a line break counts as one rune.
+	Delete(ln, cl, n int) (string, error)
+}
+
+// Historian is optionally implemented by an Editor's Buffer.  It is
+// informed about entering and leaving an editing session, i.e. the
+// insert or overwrite mode, and gets the u (undo) and U (redo) commands
+// of the command mode reported.  Undo and Redo return the position of
+// the undone/redone modification which becomes the new cursor position.
+type Historian interface {
+	Editing(bool)
+	Undo() (ln, cl int, ok bool)
+	Redo() (ln, cl int, ok bool)
+}
+
+// Saver is optionally implemented by an Editor's Buffer to have the s
+// command of the command mode save the buffer's content.
+type Saver interface {
+	Save() error
+}
+
+// Filed is optionally implemented by an Editor's Buffer which is backed
+// by a file.
+type Filed interface {
+
+	// File returns the path of the buffer's file as it is displayed.
+	File() string
+
+	// Modified returns true if the buffer has unsaved modifications.
+	Modified() bool
+}
+
+// Span is a styled range of a line's runes from Start to the rune
+// before End.
+type Span struct {
+	Start, End int
+	Style      lines.Style
+}
+
+// Styler is optionally implemented by an Editor's Buffer to style the
+// content of its lines, e.g. the headings of a help page.
+type Styler interface {
+
+	// Styles returns the styled spans of the line with given index.
+	Styles(ln int) []Span
+}
+
+// Linker is optionally implemented by an Editor's Buffer whose content
+// links to other content, e.g. a help page linking to a section of an
+// other help page.
+type Linker interface {
+
+	// Link returns the target of the link at given position and false
+	// if there is no link.
+	Link(ln, cl int) (target string, ok bool)
+}
+
+// lineBuffer is the Buffer of an Editor which wasn't given a Buffer.
+type lineBuffer struct{ ll []string }
+
+// errPosition is returned by a lineBuffer for invalid positions.
+var errPosition = errors.New("gini: view: edt: invalid position")
+
+func (b *lineBuffer) Lines() int {
+	if len(b.ll) == 0 {
+		return 1
+	}
+	return len(b.ll)
+}
+
+func (b *lineBuffer) Line(ln int) string {
+	if ln < 0 || ln >= len(b.ll) {
+		return ""
+	}
+	return b.ll[ln]
+}
+
+func (b *lineBuffer) Insert(ln, cl int, s string) error {
+	rr := []rune(b.Line(ln))
+	if ln < 0 || ln >= b.Lines() || cl < 0 || cl > len(rr) {
+		return errPosition
+	}
+	if len(b.ll) == 0 {
+		b.ll = []string{""}
+	}
+	ll := strings.Split(string(rr[:cl])+s+string(rr[cl:]), "\n")
+	b.ll = append(b.ll[:ln], append(ll, b.ll[ln+1:]...)...)
+	return nil
+}
+
+func (b *lineBuffer) Delete(ln, cl, n int) (string, error) {
+	rr := []rune(b.Line(ln))
+	if ln < 0 || ln >= b.Lines() || cl < 0 || cl > len(rr) {
+		return "", errPosition
+	}
+	if len(b.ll) == 0 {
+		return "", nil
+	}
+	tail := []rune(strings.Join(b.ll[ln:], "\n"))
+	if cl+n > len(tail) {
+		n = len(tail) - cl
+	}
+	deleted := string(tail[cl : cl+n])
+	b.ll = append(b.ll[:ln], strings.Split(
+		string(tail[:cl])+string(tail[cl+n:]), "\n")...)
+	return deleted, nil
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package edt
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/slukits/lines"
+)
+
+// Operation is applied by an Editor to the text between its cursor and
+// the target of a Motion or inserts a text.
+type Operation int
+
+const (
+
+	// Move moves the cursor to the target.
+	Move Operation = iota
+
+	// Copy copies the text between cursor and target.
+	Copy
+
+	// Delete deletes the text between cursor and target.
+	Delete
+
+	// Paste inserts a text at the cursor.
+	Paste
+)
+
+// String returns the name of given operation o.
+func (o Operation) String() string {
+	switch o {
+	case Copy:
+		return "copy"
+	case Delete:
+		return "delete"
+	case Paste:
+		return "paste"
+	}
+	return "go"
+}
+
+// Cmd is a repeatable editor command which applies an operation
+// til the target of a motion or pastes a text.  A Cmd is executed
+// at the cursor position of the executing Editor.
+type Cmd struct {
+
+	// Op is the command's operation.
+	Op Operation
+
+	// Motion determines the target of a Move, Copy or Delete.
+	Motion Motion
+
+	// Target describes the motion, e.g. "til '42'".
+	Target string
+
+	// Including is passed on to the motion.
+	Including bool
+
+	// Text is the pasted text of a Paste.
+	Text string
+}
+
+// String describes given command c, e.g. "delete til '42'".
+func (c Cmd) String() string {
+	if c.Op == Paste {
+		return c.Op.String() + " '" + c.Text + "'"
+	}
+	s := c.Op.String() + " " + c.Target
+	if c.Including {
+		s += " including"
+	}
+	return s
+}
+
+// IsMovement returns true if given command c only moves the cursor.
+func (c Cmd) IsMovement() bool { return c.Op == Move }
+
+// Execute executes given command c at given editor e's cursor position
+// and informs the Executed listener.
+func (e *Editor) Execute(env *lines.Env, c Cmd) {
+	e.execute(c)
+	e.print(env)
+}
+
+func (e *Editor) execute(c Cmd) {
+	switch c.Op {
+	case Paste:
+		if !e.paste(c.Text) {
+			return
+		}
+	default:
+		if !e.til(c.Op, c.Motion, c.Including) {
+			return
+		}
+	}
+	if e.Executed != nil {
+		e.Executed(c)
+	}
+}
+
+// Til applies given operation op to the text between given editor e's
+// cursor and the target of given motion m.  Copied or deleted text is
+// added to the copy or delete ring of the editor's Registers.
+func (e *Editor) Til(env *lines.Env, op Operation, m Motion, including bool) {
+	e.Execute(env, Cmd{Op: op, Motion: m, Including: including})
+}
+
+func (e *Editor) til(op Operation, m Motion, including bool) bool {
+	b, at := e.buffer(), Pos{Ln: e.ln, Cl: e.cl}
+	target, ok := m(b, at, including)
+	if !ok {
+		e.msg = "not found"
+		return false
+	}
+	from, to := at, target
+	if to.before(from) {
+		from, to = to, from
+	}
+	switch op {
+	case Move:
+		e.setCursor(target.Ln, target.Cl, true)
+	case Copy:
+		e.registers().Copied(text(b, from, to))
+	case Delete:
+		s, err := b.Delete(from.Ln, from.Cl, span(b, from, to))
+		if err != nil {
+			e.msg = err.Error()
+			return false
+		}
+		e.registers().Deleted(s)
+		e.setCursor(from.Ln, from.Cl, true)
+	}
+	return true
+}
+
+// Paste inserts given text s at given editor e's cursor position and
+// moves the cursor behind the inserted text.
+func (e *Editor) Paste(env *lines.Env, s string) {
+	e.Execute(env, Cmd{Op: Paste, Text: s})
+}
+
+// Replace replaces given number n of runes at given position by given
+// string s in one undo step and moves given editor e's cursor to the
+// beginning of the replacement, e.g. to correct a misspelled word.
+func (e *Editor) Replace(env *lines.Env, ln, cl, n int, s string) {
+	h, ok := e.buffer().(Historian)
+	if ok && e.mode == Command {
+		h.Editing(true)
+	}
+	if _, err := e.buffer().Delete(ln, cl, n); err != nil {
+		e.msg = err.Error()
+	} else if err := e.buffer().Insert(ln, cl, s); err != nil {
+		e.msg = err.Error()
+	}
+	if ok && e.mode == Command {
+		h.Editing(false)
+	}
+	e.setCursor(ln, cl, true)
+	e.print(env)
+}
+
+func (e *Editor) paste(s string) bool {
+	if s == "" {
+		return false
+	}
+	if err := e.buffer().Insert(e.ln, e.cl, s); err != nil {
+		e.msg = err.Error()
+		return false
+	}
+	ll := strings.Split(s, "\n")
+	ln, cl := e.ln+len(ll)-1, utf8.RuneCountInString(ll[len(ll)-1])
+	if len(ll) == 1 {
+		cl += e.cl
+	}
+	e.setCursor(ln, cl, true)
+	return true
+}
@@ -25,3 +25,615 @@ it.  An Editor component is used to display editable text in gini's
 view.
 */
 package edt
+
+import (
+	"fmt"
+	"time"
+	"unicode"
+
+	"github.com/slukits/lines"
+)
+
+// ChordTimeout is the maximal duration between the 'j' and the 'k' of
+// the "jk"-chord switching from insert or overwrite mode back to
+// command mode.
+const ChordTimeout = 300 * time.Millisecond
+
+// Mode is the editing mode of an Editor.
+type Mode int
+
+const (
+
+	// Command mode interprets runes as commands, e.g. h/j/k/l move the
+	// cursor.  It is an Editor's initial mode.
+	Command Mode = iota
+
+	// Insert mode inserts runes at the cursor position.
+	Insert
+
+	// Overwrite mode replaces the rune under the cursor.
+	Overwrite
+)
+
+// String returns the abbreviation of given mode m which is shown in the
+// context bar.
+func (m Mode) String() string {
+	switch m {
+	case Insert:
+		return "ins"
+	case Overwrite:
+		return "ovw"
+	}
+	return "cmd"
+}
+
+// Editor displays and modifies the content of its Buffer.  It is modal:
+// in command mode runes are interpreted as commands while in insert or
+// overwrite mode runes modify the buffer at the cursor position.  An
+// Editor's zero value is ready to use and edits an empty buffer.
+type Editor struct {
+	lines.Component
+
+	// Buffer provides the edited content.
+	Buffer Buffer
+
+	// Lib provides std-lib functions an Editor needs.
+	Lib Lib
+
+	// Registers keep copied and deleted text which is pasted by the p
+	// command; they default to registers of this editor only.
+	Registers Registers
+
+	// Typed is informed about each rune or key typed into the editor,
+	// e.g. to record a keyboard macro.
+	Typed func(r rune, k lines.Key, mm lines.ModifierMask)
+
+	// Executed is informed about each executed Cmd, e.g. to keep
+	// it in a command ring.
+	Executed func(Cmd)
+
+	// Changed is called after the editor printed its content, e.g. to
+	// report its cursor position and mode.  msg is a message for the
+	// user, e.g. the result of a save command, or empty.
+	Changed func(env *lines.Env, msg string)
+
+	// Follow follows the target of a link of a Linker buffer if Enter
+	// is typed on it in command mode.
+	Follow func(env *lines.Env, target string)
+
+	mode    Mode
+	ln, cl  int
+	top     int
+	want    int
+	pending func(*lines.Env, rune, lines.Key) (done bool)
+	chord   chord
+	msg     string
+	initLib bool
+}
+
+// chord keeps track of a 'j' typed in insert or overwrite mode which
+// together with a following 'k' leaves the editing mode.
+type chord struct {
+	at          time.Time
+	ln, cl      int
+	overwritten string
+}
+
+// Lib provides std-lib functions which are replaced for testing.
+type Lib struct {
+
+	// Now defaults to time.Now and is used for the "jk"-chord
+	// detection.
+	Now func() time.Time
+}
+
+func (e *Editor) lib() Lib {
+	if !e.initLib {
+		e.initLib = true
+		if e.Lib.Now == nil {
+			e.Lib.Now = time.Now
+		}
+	}
+	return e.Lib
+}
+
+func (e *Editor) registers() Registers {
+	if e.Registers == nil {
+		e.Registers = &Rings{}
+	}
+	return e.Registers
+}
+
+func (e *Editor) buffer() Buffer {
+	if e.Buffer == nil {
+		e.Buffer = &lineBuffer{}
+	}
+	return e.Buffer
+}
+
+// Mode returns given editor e's current mode.
+func (e *Editor) Mode() Mode { return e.mode }
+
+// File returns the path of given editor e's file if its Buffer is
+// Filed and true if the buffer has unsaved modifications.
+func (e *Editor) File() (path string, modified bool) {
+	f, ok := e.buffer().(Filed)
+	if !ok {
+		return "", false
+	}
+	return f.File(), f.Modified()
+}
+
+// Cursor returns the buffer position of given editor e's cursor.
+func (e *Editor) Cursor() (ln, cl int) { return e.ln, e.cl }
+
+// OnLayout prints the editor's content to the screen.
+func (e *Editor) OnLayout(env *lines.Env) (reflow bool) {
+	e.print(env)
+	return false
+}
+
+// OnFocus shows the editor's cursor.
+func (e *Editor) OnFocus(env *lines.Env) { e.print(env) }
+
+// OnRune interprets given rune r according to the editor's mode.  A
+// rune which isn't bound to a command in command mode bubbles to the
+// editor's enclosing components.
+func (e *Editor) OnRune(env *lines.Env, r rune, mm lines.ModifierMask) {
+	if e.Typed != nil {
+		e.Typed(r, 0, mm)
+	}
+	if e.Type(env, r, 0, mm) {
+		env.StopBubbling()
+	}
+}
+
+// OnKey moves the cursor on cursor keys, switches modes on insert and
+// escape and modifies the buffer on backspace, delete and enter.  Note
+// these keys work the same in all modes.
+func (e *Editor) OnKey(env *lines.Env, k lines.Key, mm lines.ModifierMask) {
+	if e.Typed != nil {
+		e.Typed(0, k, mm)
+	}
+	if e.Type(env, 0, k, mm) {
+		env.StopBubbling()
+	}
+}
+
+// Type processes given rune r or given key k if r is zero as if it was
+// typed by the user and returns false if the input isn't consumed by
+// the editor, i.e. it bubbles to the editor's enclosing components.
+func (e *Editor) Type(
+	env *lines.Env, r rune, k lines.Key, mm lines.ModifierMask,
+) bool {
+	defer e.print(env)
+	if e.pending != nil {
+		pending := e.pending
+		e.pending = nil
+		if pending(env, r, k) {
+			return true
+		}
+	}
+	if r == 0 {
+		return e.key(env, k, mm)
+	}
+	if e.mode == Command {
+		return e.command(r)
+	}
+	e.typed(r)
+	return true
+}
+
+// key executes the command associated with given key k and modifiers
+// mm and returns false if there is no such command.
+func (e *Editor) key(
+	env *lines.Env, k lines.Key, mm lines.ModifierMask,
+) bool {
+	switch k {
+	case lines.Left:
+		e.moveColumn(-1)
+	case lines.Right:
+		e.moveColumn(1)
+	case lines.Up:
+		e.moveLine(-1)
+	case lines.Down:
+		e.moveLine(1)
+	case lines.Insert:
+		switch e.mode {
+		case Insert:
+			e.mode = Overwrite
+		case Overwrite:
+			e.mode = Insert
+		default:
+			e.setMode(Insert)
+		}
+	case lines.Esc:
+		if e.mode == Command {
+			return false
+		}
+		e.setMode(Command)
+	case lines.Enter:
+		if e.follow(env) {
+			break
+		}
+		if mm&lines.Shift != 0 {
+			e.newLine(e.ln)
+			break
+		}
+		e.newLine(e.ln + 1)
+	case lines.Backspace, lines.DEL:
+		e.backspace()
+	case lines.Delete:
+		e.delete()
+	default:
+		return false
+	}
+	return true
+}
+
+// follow follows the link under the cursor in command mode and returns
+// false if there is no such link.
+func (e *Editor) follow(env *lines.Env) bool {
+	l, ok := e.buffer().(Linker)
+	if !ok || e.mode != Command || e.Follow == nil {
+		return false
+	}
+	target, ok := l.Link(e.ln, e.cl)
+	if !ok {
+		return false
+	}
+	e.Follow(env, target)
+	return true
+}
+
+// command executes the command associated with given rune r and
+// returns false if there is no such command.
+func (e *Editor) command(r rune) bool {
+	switch r {
+	case 'h':
+		e.moveColumn(-1)
+	case 'l':
+		e.moveColumn(1)
+	case 'j':
+		e.moveLine(1)
+	case 'k':
+		e.moveLine(-1)
+	case 'H':
+		e.pending = e.findRune(-1)
+	case 'L':
+		e.pending = e.findRune(1)
+	case 'J':
+		e.pending = e.countLines(1, 0)
+	case 'K':
+		e.pending = e.countLines(-1, 0)
+	case 'i':
+		e.setMode(Insert)
+	case 'u':
+		if h, ok := e.buffer().(Historian); ok {
+			e.historyCursor(h.Undo())
+		}
+	case 'U':
+		if h, ok := e.buffer().(Historian); ok {
+			e.historyCursor(h.Redo())
+		}
+	case 's':
+		e.save()
+	case 'p':
+		e.execute(Cmd{Op: Paste, Text: e.registers().Latest()})
+	default:
+		return false
+	}
+	return true
+}
+
+// save saves a Saver buffer and reports the outcome.
+func (e *Editor) save() {
+	s, ok := e.buffer().(Saver)
+	if !ok {
+		return
+	}
+	if err := s.Save(); err != nil {
+		e.msg = err.Error()
+		return
+	}
+	e.msg = "saved"
+	if path, _ := e.File(); path != "" {
+		e.msg = "saved " + path
+	}
+}
+
+// Page moves given editor e's cursor given number n of pages down or up
+// if n is negative.
+func (e *Editor) Page(env *lines.Env, n int) {
+	_, _, _, height := e.ContentArea()
+	e.top += n * height
+	if e.top > e.buffer().Lines()-height {
+		e.top = e.buffer().Lines() - height
+	}
+	if e.top < 0 {
+		e.top = 0
+	}
+	e.moveLine(n * height)
+	e.print(env)
+}
+
+// Goto moves given editor e's cursor to given position which is
+// clamped to the buffer's content.
+func (e *Editor) Goto(env *lines.Env, ln, cl int) {
+	e.setCursor(ln, cl, true)
+	e.print(env)
+}
+
+// Top scrolls given editor e to show the line with given index ln as
+// its first line and moves the cursor to this line's beginning, e.g. to
+// show a section of a help page.
+func (e *Editor) Top(env *lines.Env, ln int) {
+	e.setCursor(ln, 0, true)
+	e.top = e.ln
+	e.print(env)
+}
+
+// Word returns the word under given editor e's cursor or an empty
+// string if the rune under the cursor isn't part of a word.
+func (e *Editor) Word() string {
+	rr := []rune(e.buffer().Line(e.ln))
+	if e.cl >= len(rr) || !isWord(rr[e.cl]) {
+		return ""
+	}
+	start, end := e.cl, e.cl
+	for start > 0 && isWord(rr[start-1]) {
+		start--
+	}
+	for end < len(rr) && isWord(rr[end]) {
+		end++
+	}
+	return string(rr[start:end])
+}
+
+// Switch switches given editor e into given mode m.
+func (e *Editor) Switch(env *lines.Env, m Mode) {
+	e.setMode(m)
+	e.print(env)
+}
+
+// historyCursor moves the cursor to the position of an undone or
+// redone modification.
+func (e *Editor) historyCursor(ln, cl int, ok bool) {
+	if !ok {
+		return
+	}
+	e.setCursor(ln, cl, true)
+}
+
+// findRune returns the pending input handler moving the cursor in given
+// direction dir to the next occurrence of the subsequently typed rune
+// in the current line.
+func (e *Editor) findRune(dir int) func(*lines.Env, rune, lines.Key) bool {
+	return func(_ *lines.Env, r rune, k lines.Key) bool {
+		if k != 0 {
+			return k == lines.Esc
+		}
+		rr := []rune(e.buffer().Line(e.ln))
+		for i := e.cl + dir; i >= 0 && i < len(rr); i += dir {
+			if rr[i] == r {
+				e.cl, e.want = i, i
+				break
+			}
+		}
+		return true
+	}
+}
+
+// countLines returns the pending input handler collecting the digits of
+// the number of lines the cursor is moved in given direction dir.  The
+// first non-digit input executes the movement whereas an enter is
+// consumed and an escape cancels the movement.
+func (e *Editor) countLines(dir, n int) func(*lines.Env, rune, lines.Key) bool {
+	return func(_ *lines.Env, r rune, k lines.Key) bool {
+		if k == 0 && unicode.IsDigit(r) {
+			e.pending = e.countLines(dir, n*10+int(r-'0'))
+			return true
+		}
+		if k == lines.Esc {
+			return true
+		}
+		e.moveLine(dir * n)
+		return k == lines.Enter
+	}
+}
+
+// typed inserts or overwrites given rune r at the cursor position
+// respectively whereas a 'k' following a 'j' within ChordTimeout
+// switches back to the command mode.
+func (e *Editor) typed(r rune) {
+	now := e.lib().Now()
+	if r == 'k' && e.isChord(now) {
+		e.undoChord()
+		e.setMode(Command)
+		return
+	}
+	overwritten := ""
+	if e.mode == Overwrite && e.cl < e.lineLen(e.ln) {
+		overwritten, _ = e.buffer().Delete(e.ln, e.cl, 1)
+	}
+	if err := e.buffer().Insert(e.ln, e.cl, string(r)); err != nil {
+		return
+	}
+	e.chord = chord{}
+	if r == 'j' {
+		e.chord = chord{
+			at: now, ln: e.ln, cl: e.cl, overwritten: overwritten}
+	}
+	e.cl++
+	e.want = e.cl
+}
+
+func (e *Editor) isChord(now time.Time) bool {
+	return !e.chord.at.IsZero() && now.Sub(e.chord.at) <= ChordTimeout &&
+		e.chord.ln == e.ln && e.chord.cl+1 == e.cl
+}
+
+// undoChord removes the 'j' of a "jk"-chord restoring a rune it has
+// overwritten.
+func (e *Editor) undoChord() {
+	e.cl--
+	e.buffer().Delete(e.ln, e.cl, 1)
+	if e.chord.overwritten != "" {
+		e.buffer().Insert(e.ln, e.cl, e.chord.overwritten)
+	}
+	e.want, e.chord = e.cl, chord{}
+}
+
+// setMode switches to given mode m informing a Historian buffer about
+// starting or ending an editing session.
+func (e *Editor) setMode(m Mode) {
+	if e.mode == m {
+		return
+	}
+	e.mode, e.chord = m, chord{}
+	if h, ok := e.buffer().(Historian); ok {
+		h.Editing(m != Command)
+	}
+	e.setCursor(e.ln, e.cl, true)
+}
+
+// newLine inserts an empty line at given line index ln, moves the
+// cursor to it and switches to insert mode.
+func (e *Editor) newLine(ln int) {
+	e.setMode(Insert)
+	if ln > e.ln {
+		if err := e.buffer().Insert(e.ln, e.lineLen(e.ln), "\n"); err != nil {
+			return
+		}
+	} else if err := e.buffer().Insert(ln, 0, "\n"); err != nil {
+		return
+	}
+	e.setCursor(ln, 0, true)
+}
+
+// backspace deletes the rune left to the cursor or joins the current
+// line with the previous line if the cursor is at the line's start.
+func (e *Editor) backspace() {
+	if e.cl > 0 {
+		e.buffer().Delete(e.ln, e.cl-1, 1)
+		e.setCursor(e.ln, e.cl-1, true)
+		return
+	}
+	if e.ln == 0 {
+		return
+	}
+	cl := e.lineLen(e.ln - 1)
+	e.buffer().Delete(e.ln-1, cl, 1)
+	e.setCursor(e.ln-1, cl, true)
+}
+
+// delete deletes the rune under the cursor or joins the current line
+// with the next line if the cursor is at the line's end.
+func (e *Editor) delete() {
+	if e.cl >= e.lineLen(e.ln) && e.ln+1 >= e.buffer().Lines() {
+		return
+	}
+	e.buffer().Delete(e.ln, e.cl, 1)
+	e.setCursor(e.ln, e.cl, true)
+}
+
+func (e *Editor) lineLen(ln int) int {
+	return len([]rune(e.buffer().Line(ln)))
+}
+
+// maxColumn returns the maximal cursor column of given line ln which
+// is its last rune in command mode and the position after it otherwise.
+func (e *Editor) maxColumn(ln int) int {
+	n := e.lineLen(ln)
+	if e.mode == Command && n > 0 {
+		return n - 1
+	}
+	return n
+}
+
+func (e *Editor) moveColumn(d int) {
+	e.setCursor(e.ln, e.cl+d, true)
+}
+
+func (e *Editor) moveLine(d int) {
+	e.setCursor(e.ln+d, e.want, false)
+}
+
+// setCursor sets the cursor to given position clamped to the buffer's
+// content.  If sticky is true the column becomes the wanted column
+// vertical movements try to keep.
+func (e *Editor) setCursor(ln, cl int, sticky bool) {
+	if ln >= e.buffer().Lines() {
+		ln = e.buffer().Lines() - 1
+	}
+	if ln < 0 {
+		ln = 0
+	}
+	if cl > e.maxColumn(ln) {
+		cl = e.maxColumn(ln)
+	}
+	if cl < 0 {
+		cl = 0
+	}
+	e.ln, e.cl = ln, cl
+	if sticky {
+		e.want = cl
+	}
+}
+
+// print writes the buffer's lines which fit on the screen and sets the
+// cursor scrolling the content if the cursor is not visible.
+func (e *Editor) print(env *lines.Env) {
+	_, _, _, height := e.ContentArea()
+	if height <= 0 {
+		return
+	}
+	e.setCursor(e.ln, e.cl, false)
+	if e.ln < e.top {
+		e.top = e.ln
+	}
+	if e.ln >= e.top+height {
+		e.top = e.ln - height + 1
+	}
+	for i := 0; i < height; i++ {
+		if e.top+i >= e.buffer().Lines() {
+			e.Reset(i)
+			continue
+		}
+		fmt.Fprint(env.LL(i), e.buffer().Line(e.top+i))
+		e.style(env, i)
+	}
+	e.SetCursor(e.ln-e.top, e.cl, e.cursorStyle())
+	if e.Changed != nil {
+		msg := e.msg
+		e.msg = ""
+		e.Changed(env, msg)
+	}
+}
+
+// style styles the spans of the displayed line with given index i if
+// the buffer is a Styler.
+func (e *Editor) style(env *lines.Env, i int) {
+	s, ok := e.buffer().(Styler)
+	if !ok {
+		return
+	}
+	rr := []rune(e.buffer().Line(e.top + i))
+	for _, sp := range s.Styles(e.top + i) {
+		if sp.Start < 0 || sp.End > len(rr) || sp.Start >= sp.End {
+			continue
+		}
+		env.LL(i).At(sp.Start).Sty(sp.Style).WriteAt(
+			rr[sp.Start:sp.End])
+	}
+}
+
+func (e *Editor) cursorStyle() lines.CursorStyle {
+	switch e.mode {
+	case Insert:
+		return lines.BarCursorSteady
+	case Overwrite:
+		return lines.UnderlineCursorSteady
+	}
+	return lines.BlockCursorSteady
+}
@@ -20,3 +20,581 @@ along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
 */
 
 package edt
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/slukits/gounit"
+	"github.com/slukits/lines"
+)
+
+type AnEditor struct{ Suite }
+
+func (s *AnEditor) SetUp(t *T) { t.Parallel() }
+
+// fx creates an editor with given content in a terminal fixture whose
+// clock is controlled by the returned now-pointer.
+func fx(t *T, content string) (*lines.Fixture, *Editor, *time.Time) {
+	now := time.Now()
+	ed := &Editor{Buffer: &lineBuffer{ll: strings.Split(content, "\n")}}
+	ed.Lib.Now = func() time.Time { return now }
+	return lines.TermFixture(t.GoT(), 0, ed), ed, &now
+}
+
+// screen returns the content of given editor's screen area having
+// trailing blanks removed.
+func screen(fx *lines.Fixture, ed *Editor) string {
+	ll := fx.ScreenOf(ed).Trimmed()
+	for i, l := range ll {
+		ll[i] = strings.TrimRight(l, " ")
+	}
+	return strings.Join(ll, "\n")
+}
+
+func (s *AnEditor) Shows_its_buffer_s_content(t *T) {
+	fx, ed, _ := fx(t, "first\nsecond")
+	t.Eq("first\nsecond", screen(fx, ed))
+}
+
+func (s *AnEditor) Is_initially_in_command_mode(t *T) {
+	_, ed, _ := fx(t, "")
+	t.Eq(Command, ed.Mode())
+	t.Eq("cmd", ed.Mode().String())
+}
+
+func (s *AnEditor) Moves_cursor_with_h_j_k_l(t *T) {
+	fx, ed, _ := fx(t, "first\nsecond")
+	fx.FireRune('l')
+	fx.FireRune('l')
+	fx.FireRune('j')
+	ln, cl := ed.Cursor()
+	t.Eq(1, ln)
+	t.Eq(2, cl)
+	fx.FireRune('h')
+	fx.FireRune('k')
+	ln, cl = ed.Cursor()
+	t.Eq(0, ln)
+	t.Eq(1, cl)
+	x, y, ok := fx.Lines.CursorPosition()
+	t.True(ok)
+	t.Eq(1, x)
+	t.Eq(0, y)
+}
+
+func (s *AnEditor) Moves_cursor_with_cursor_keys(t *T) {
+	fx, ed, _ := fx(t, "first\nsecond")
+	fx.FireKeys(lines.Right, lines.Down, lines.Right, lines.Left,
+		lines.Up)
+	ln, cl := ed.Cursor()
+	t.Eq(0, ln)
+	t.Eq(1, cl)
+}
+
+func (s *AnEditor) Keeps_cursor_on_the_last_rune_in_command_mode(t *T) {
+	fx, ed, _ := fx(t, "longer\nab")
+	for i := 0; i < 10; i++ {
+		fx.FireRune('l')
+	}
+	_, cl := ed.Cursor()
+	t.Eq(5, cl)
+	fx.FireRune('j')
+	_, cl = ed.Cursor()
+	t.Eq(1, cl)
+	fx.FireRune('k')
+	_, cl = ed.Cursor()
+	t.Eq(5, cl)
+}
+
+func (s *AnEditor) Moves_to_next_occurrence_of_rune_with_L(t *T) {
+	fx, ed, _ := fx(t, "a-b-c")
+	fx.FireRune('L')
+	fx.FireRune('-')
+	_, cl := ed.Cursor()
+	t.Eq(1, cl)
+	fx.FireRune('L')
+	fx.FireRune('-')
+	_, cl = ed.Cursor()
+	t.Eq(3, cl)
+	fx.FireRune('L')
+	fx.FireRune('x')
+	_, cl = ed.Cursor()
+	t.Eq(3, cl)
+}
+
+func (s *AnEditor) Moves_to_previous_occurrence_of_rune_with_H(t *T) {
+	fx, ed, _ := fx(t, "a-b-c")
+	fx.FireRune('L')
+	fx.FireRune('c')
+	fx.FireRune('H')
+	fx.FireRune('a')
+	_, cl := ed.Cursor()
+	t.Eq(0, cl)
+}
+
+func (s *AnEditor) Moves_given_number_of_lines_with_J_and_K(t *T) {
+	fx, ed, _ := fx(t, strings.Repeat("line\n", 20))
+	fx.FireRune('J')
+	fx.FireRune('1')
+	fx.FireRune('2')
+	fx.FireKey(lines.Enter)
+	ln, _ := ed.Cursor()
+	t.Eq(12, ln)
+	t.Eq(Command, ed.Mode())
+	fx.FireRune('K')
+	fx.FireRune('3')
+	fx.FireRune('l')
+	ln, cl := ed.Cursor()
+	t.Eq(9, ln)
+	t.Eq(1, cl)
+}
+
+func (s *AnEditor) Switches_to_insert_mode_with_i(t *T) {
+	fx, ed, _ := fx(t, "")
+	fx.FireRune('i')
+	t.Eq(Insert, ed.Mode())
+}
+
+func (s *AnEditor) Switches_to_insert_mode_with_insert_key(t *T) {
+	fx, ed, _ := fx(t, "")
+	fx.FireKey(lines.Insert)
+	t.Eq(Insert, ed.Mode())
+}
+
+func (s *AnEditor) Toggles_insert_and_overwrite_with_insert_key(t *T) {
+	fx, ed, _ := fx(t, "")
+	fx.FireKeys(lines.Insert, lines.Insert)
+	t.Eq(Overwrite, ed.Mode())
+	fx.FireKey(lines.Insert)
+	t.Eq(Insert, ed.Mode())
+}
+
+func (s *AnEditor) Switches_back_to_command_mode_with_esc(t *T) {
+	fx, ed, _ := fx(t, "")
+	fx.FireKey(lines.Insert)
+	fx.FireKey(lines.Esc)
+	t.Eq(Command, ed.Mode())
+	fx.FireKeys(lines.Insert, lines.Insert, lines.Esc)
+	t.Eq(Command, ed.Mode())
+}
+
+func (s *AnEditor) Inserts_typed_runes_in_insert_mode(t *T) {
+	fx, ed, _ := fx(t, "gini")
+	fx.FireRune('l')
+	fx.FireRune('i')
+	for _, r := range "äö" {
+		fx.FireRune(r)
+	}
+	t.Eq("gäöini", screen(fx, ed))
+	_, cl := ed.Cursor()
+	t.Eq(3, cl)
+}
+
+func (s *AnEditor) Overwrites_typed_runes_in_overwrite_mode(t *T) {
+	fx, ed, _ := fx(t, "gini")
+	fx.FireKeys(lines.Insert, lines.Insert)
+	for _, r := range "GIN" {
+		fx.FireRune(r)
+	}
+	t.Eq("GINi", screen(fx, ed))
+}
+
+func (s *AnEditor) Leaves_insert_mode_on_jk_chord(t *T) {
+	fx, ed, _ := fx(t, "gini")
+	fx.FireRune('i')
+	fx.FireRune('j')
+	fx.FireRune('k')
+	t.Eq(Command, ed.Mode())
+	t.Eq("gini", screen(fx, ed))
+}
+
+func (s *AnEditor) Inserts_j_and_k_if_typed_slower_than_timeout(t *T) {
+	fx, ed, now := fx(t, "")
+	fx.FireRune('i')
+	fx.FireRune('j')
+	*now = now.Add(ChordTimeout + time.Millisecond)
+	fx.FireRune('k')
+	t.Eq(Insert, ed.Mode())
+	t.Eq("jk", screen(fx, ed))
+}
+
+func (s *AnEditor) Inserts_k_if_not_directly_following_a_j(t *T) {
+	fx, ed, _ := fx(t, "")
+	fx.FireRune('i')
+	fx.FireRune('j')
+	fx.FireKey(lines.Left)
+	fx.FireRune('k')
+	t.Eq(Insert, ed.Mode())
+	t.Eq("kj", screen(fx, ed))
+}
+
+func (s *AnEditor) Restores_overwritten_rune_on_jk_chord(t *T) {
+	fx, ed, _ := fx(t, "gini")
+	fx.FireKeys(lines.Insert, lines.Insert)
+	fx.FireRune('j')
+	fx.FireRune('k')
+	t.Eq(Command, ed.Mode())
+	t.Eq("gini", screen(fx, ed))
+}
+
+func (s *AnEditor) Adds_line_below_and_inserts_on_enter(t *T) {
+	fx, ed, _ := fx(t, "first\nthird")
+	fx.FireRune('l')
+	fx.FireKey(lines.Enter)
+	t.Eq(Insert, ed.Mode())
+	fx.FireRune('2')
+	t.Eq("first\n2\nthird", screen(fx, ed))
+}
+
+func (s *AnEditor) Adds_line_above_and_inserts_on_shift_enter(t *T) {
+	fx, ed, _ := fx(t, "second")
+	fx.FireKey(lines.Enter, lines.Shift)
+	t.Eq(Insert, ed.Mode())
+	fx.FireRune('1')
+	t.Eq("1\nsecond", screen(fx, ed))
+}
+
+func (s *AnEditor) Deletes_rune_before_cursor_on_backspace(t *T) {
+	fx, ed, _ := fx(t, "gini")
+	fx.FireRune('l')
+	fx.FireKey(lines.Backspace)
+	t.Eq("ini", screen(fx, ed))
+	_, cl := ed.Cursor()
+	t.Eq(0, cl)
+}
+
+func (s *AnEditor) Joins_with_previous_line_on_backspace(t *T) {
+	fx, ed, _ := fx(t, "one\ntwo")
+	fx.FireRune('j')
+	fx.FireKey(lines.Backspace)
+	t.Eq("onetwo", screen(fx, ed))
+	ln, cl := ed.Cursor()
+	t.Eq(0, ln)
+	t.Eq(3, cl)
+}
+
+func (s *AnEditor) Deletes_rune_under_cursor_on_delete(t *T) {
+	fx, ed, _ := fx(t, "gini")
+	fx.FireKey(lines.Delete)
+	t.Eq("ini", screen(fx, ed))
+}
+
+func (s *AnEditor) Joins_with_next_line_on_delete_at_line_end(t *T) {
+	fx, ed, _ := fx(t, "one\ntwo")
+	fx.FireRune('i')
+	fx.FireKey(lines.End)
+	for i := 0; i < 3; i++ {
+		fx.FireKey(lines.Right)
+	}
+	fx.FireKey(lines.Delete)
+	t.Eq("onetwo", screen(fx, ed))
+}
+
+func (s *AnEditor) Scrolls_to_keep_cursor_visible(t *T) {
+	fx, ed, _ := fx(t, strings.Repeat("line\n", 100)+"last")
+	fx.FireRune('J')
+	fx.FireRune('9')
+	fx.FireRune('9')
+	fx.FireRune('j')
+	scr := fx.ScreenOf(ed).Trimmed()
+	t.Eq("last", scr[len(scr)-1])
+	_, y, _ := fx.Lines.CursorPosition()
+	t.Eq(len(fx.ScreenOf(ed))-1, y)
+}
+
+// historian is a lineBuffer with an undo history mock.
+type historian struct {
+	lineBuffer
+	editing      []bool
+	undos, redos int
+}
+
+func (h *historian) Editing(e bool) { h.editing = append(h.editing, e) }
+
+func (h *historian) Undo() (int, int, bool) {
+	h.undos++
+	return 0, 2, true
+}
+
+func (h *historian) Redo() (int, int, bool) {
+	h.redos++
+	return 0, 0, false
+}
+
+func (s *AnEditor) Reports_editing_sessions_to_a_historian(t *T) {
+	h := &historian{lineBuffer: lineBuffer{ll: []string{"gini"}}}
+	fx := lines.TermFixture(t.GoT(), 0, &Editor{Buffer: h})
+	fx.FireRune('i')
+	fx.FireKey(lines.Insert)
+	fx.FireKey(lines.Esc)
+	t.Eq([]bool{true, false}, h.editing)
+}
+
+func (s *AnEditor) Replaces_text_in_one_editing_session(t *T) {
+	h := &historian{lineBuffer: lineBuffer{ll: []string{"gini is a ied"}}}
+	ed := &Editor{Buffer: h}
+	fx := lines.TermFixture(t.GoT(), 0, ed)
+	fx.Lines.Update(ed, nil, func(e *lines.Env) {
+		ed.Replace(e, 0, 10, 3, "ide")
+	})
+	t.Eq("gini is a ide", h.ll[0])
+	t.Eq([]bool{true, false}, h.editing)
+	_, cl := ed.Cursor()
+	t.Eq(10, cl)
+	t.Contains(fx.ScreenOf(ed), "gini is a ide")
+}
+
+func (s *AnEditor) Undoes_and_redoes_with_u_and_U(t *T) {
+	h := &historian{lineBuffer: lineBuffer{ll: []string{"gini"}}}
+	ed := &Editor{Buffer: h}
+	fx := lines.TermFixture(t.GoT(), 0, ed)
+	fx.FireRune('u')
+	t.Eq(1, h.undos)
+	_, cl := ed.Cursor()
+	t.Eq(2, cl)
+	fx.FireRune('U')
+	t.Eq(1, h.redos)
+	_, cl = ed.Cursor()
+	t.Eq(2, cl)
+}
+
+type saver struct {
+	lineBuffer
+	saved int
+}
+
+func (s *saver) Save() error {
+	s.saved++
+	return nil
+}
+
+// filed is a saver backed by a file which fails to save if err is set.
+type filed struct {
+	saver
+	err error
+}
+
+func (f *filed) File() string { return "hlp/index.gnh" }
+
+func (f *filed) Modified() bool { return f.saved == 0 }
+
+func (f *filed) Save() error {
+	if f.err != nil {
+		return f.err
+	}
+	return f.saver.Save()
+}
+
+func (s *AnEditor) Reports_changes_with_save_messages(t *T) {
+	f := &filed{saver: saver{lineBuffer: lineBuffer{ll: []string{"gini"}}}}
+	mm := []string{}
+	ed := &Editor{Buffer: f, Changed: func(_ *lines.Env, msg string) {
+		mm = append(mm, msg)
+	}}
+	fx := lines.TermFixture(t.GoT(), 0, ed)
+	path, modified := ed.File()
+	t.Eq("hlp/index.gnh", path)
+	t.True(modified)
+	fx.FireRune('l')
+	fx.FireRune('s')
+	f.err = errors.New("save failed")
+	fx.FireRune('s')
+	t.Eq("", mm[len(mm)-3])
+	t.Eq("saved hlp/index.gnh", mm[len(mm)-2])
+	t.Eq("save failed", mm[len(mm)-1])
+	_, modified = ed.File()
+	t.Not.True(modified)
+}
+
+func (s *AnEditor) Saves_its_buffer_with_s(t *T) {
+	svr := &saver{lineBuffer: lineBuffer{ll: []string{"gini"}}}
+	fx := lines.TermFixture(t.GoT(), 0, &Editor{Buffer: svr})
+	fx.FireRune('s')
+	t.Eq(1, svr.saved)
+	fx.FireRune('i')
+	fx.FireRune('s')
+	t.Eq(1, svr.saved)
+}
+
+func (s *AnEditor) Moves_copies_and_deletes_til_motion_targets(t *T) {
+	b := &lineBuffer{ll: []string{"gini is 42", "not an ide"}}
+	ed := &Editor{Buffer: b}
+	fx := lines.TermFixture(t.GoT(), 0, ed)
+	til := func(op Operation, m Motion, including bool) {
+		fx.Lines.Update(ed, nil, func(e *lines.Env) {
+			ed.Til(e, op, m, including)
+		})
+	}
+	til(Move, Til("42"), false)
+	ln, cl := ed.Cursor()
+	t.Eq(0, ln)
+	t.Eq(8, cl)
+	til(Copy, TilBack("gini"), false)
+	t.Eq(" is ", ed.Registers.Latest())
+	til(Delete, NextWord, false)
+	t.Eq("gini is not an ide", strings.Join(b.ll, "\n"))
+	t.Eq("42\n", ed.Registers.Latest())
+	til(Delete, TilBack("is"), true)
+	t.Eq("gini not an ide", strings.Join(b.ll, "\n"))
+	_, cl = ed.Cursor()
+	t.Eq(5, cl)
+}
+
+func (s *AnEditor) Pastes_latest_copy_or_deletion_with_p(t *T) {
+	b := &lineBuffer{ll: []string{"gini is 42"}}
+	ed := &Editor{Buffer: b}
+	fx := lines.TermFixture(t.GoT(), 0, ed)
+	fx.Lines.Update(ed, nil, func(e *lines.Env) {
+		ed.Til(e, Copy, WordEnd, false)
+		ed.Til(e, Delete, LineEnd, false)
+	})
+	t.Eq("", b.ll[0])
+	fx.FireRune('p')
+	t.Eq("gini is 42", b.ll[0])
+	t.Eq([]string{"gini"}, ed.Registers.Copies())
+	t.Eq([]string{"gini is 42"}, ed.Registers.Deletions())
+	fx.Lines.Update(ed, nil, func(e *lines.Env) {
+		ed.Til(e, Move, LineBeginning, false)
+		ed.Paste(e, "ide\nnot ")
+	})
+	t.Eq("ide\nnot gini is 42", strings.Join(b.ll, "\n"))
+	ln, cl := ed.Cursor()
+	t.Eq(1, ln)
+	t.Eq(4, cl)
+}
+
+func (s *AnEditor) Keeps_at_most_RingSize_registers(t *T) {
+	r := &Rings{}
+	for i := 0; i <= RingSize; i++ {
+		r.Copied(strconv.Itoa(i))
+	}
+	r.Deleted("gini")
+	t.Eq(RingSize, len(r.Copies()))
+	t.Eq(strconv.Itoa(RingSize), r.Copies()[0])
+	t.Eq("1", r.Copies()[RingSize-1])
+	t.Eq([]string{"gini"}, r.Deletions())
+	t.Eq("gini", r.Latest())
+}
+
+func (s *AnEditor) Restores_its_registers(t *T) {
+	r := &Rings{}
+	r.Copied("ide")
+	r.Restore([]string{"gini", "is"}, []string{"not"}, "is")
+	t.Eq([]string{"is", "gini"}, r.Copies())
+	t.Eq([]string{"not"}, r.Deletions())
+	t.Eq("is", r.Latest())
+}
+
+func (s *AnEditor) Reports_executed_commands(t *T) {
+	b := &lineBuffer{ll: []string{"gini is 42"}}
+	cc := []Cmd{}
+	ed := &Editor{Buffer: b, Executed: func(c Cmd) { cc = append(cc, c) }}
+	fx := lines.TermFixture(t.GoT(), 0, ed)
+	fx.Lines.Update(ed, nil, func(e *lines.Env) {
+		ed.Execute(e, Cmd{Op: Delete, Motion: Til("42"), Target: "til '42'"})
+		ed.Execute(e, Cmd{Op: Move, Motion: Til("x"), Target: "til 'x'"})
+	})
+	fx.FireRune('p')
+	t.FatalIfNot(t.Eq(2, len(cc)))
+	t.Eq("delete til '42'", cc[0].String())
+	t.Eq("paste 'gini is '", cc[1].String())
+	t.Not.True(cc[0].IsMovement())
+}
+
+func (s *AnEditor) Executes_commands_at_its_cursor(t *T) {
+	b := &lineBuffer{ll: []string{"gini is 42", "not an ide"}}
+	ed := &Editor{Buffer: b}
+	fx := lines.TermFixture(t.GoT(), 0, ed)
+	c := Cmd{Op: Delete, Motion: WordEnd, Target: "til word end"}
+	fx.Lines.Update(ed, nil, func(e *lines.Env) {
+		ed.Execute(e, c)
+		ed.Til(e, Move, NextWord, false)
+		ed.Execute(e, c)
+	})
+	t.Eq("  42\nnot an ide", strings.Join(b.ll, "\n"))
+	c.Including = true
+	t.Eq("delete til word end including", c.String())
+}
+
+func (s *AnEditor) Goes_to_given_position_and_provides_its_word(t *T) {
+	fx, ed, _ := fx(t, "gini is\nnot an ide")
+	fx.Lines.Update(ed, nil, func(e *lines.Env) { ed.Goto(e, 1, 5) })
+	ln, cl := ed.Cursor()
+	t.Eq(1, ln)
+	t.Eq(5, cl)
+	t.Eq("an", ed.Word())
+	fx.Lines.Update(ed, nil, func(e *lines.Env) { ed.Goto(e, 1, 3) })
+	t.Eq("", ed.Word())
+	fx.Lines.Update(ed, nil, func(e *lines.Env) { ed.Goto(e, 5, 42) })
+	ln, cl = ed.Cursor()
+	t.Eq(1, ln)
+	t.Eq(9, cl)
+}
+
+func (s *AnEditor) Scrolls_given_line_to_the_top(t *T) {
+	ll := make([]string, 100)
+	for i := range ll {
+		ll[i] = fmt.Sprintf("line %d", i)
+	}
+	fx, ed, _ := fx(t, strings.Join(ll, "\n"))
+	fx.Lines.Update(ed, nil, func(e *lines.Env) { ed.Top(e, 42) })
+	ln, cl := ed.Cursor()
+	t.Eq(42, ln)
+	t.Eq(0, cl)
+	t.True(strings.HasPrefix(fx.ScreenOf(ed).String(), "line 42"))
+}
+
+func (s *AnEditor) Styles_spans_of_a_styler_buffer(t *T) {
+	b := &linkedFX{lineBuffer: lineBuffer{ll: []string{"see [x](y)"}}}
+	ed := &Editor{Buffer: b}
+	fx := lines.TermFixture(t.GoT(), 0, ed)
+	cc := fx.CellsOf(ed)
+	t.Not.True(cc[0].HasAA(3, lines.Underline))
+	t.True(cc[0].HasAA(4, lines.Underline))
+	t.True(cc[0].HasAA(9, lines.Underline))
+}
+
+func (s *AnEditor) Follows_links_on_enter_in_command_mode(t *T) {
+	b := &linkedFX{lineBuffer: lineBuffer{ll: []string{"see [x](y)"}}}
+	followed := ""
+	ed := &Editor{Buffer: b, Follow: func(_ *lines.Env, target string) {
+		followed = target
+	}}
+	fx := lines.TermFixture(t.GoT(), 0, ed)
+	for _, r := range "llll" {
+		fx.FireRune(r)
+	}
+	fx.FireKey(lines.Enter)
+	t.Eq("y", followed)
+	t.Eq(1, b.Lines())
+	fx.FireRune('h')
+	followed = ""
+	fx.FireKey(lines.Enter)
+	t.Eq("", followed)
+	t.Eq(2, b.Lines())
+}
+
+// linkedFX is a Styler and Linker underlining its link at the columns
+// 4 to 9 of its first line.
+type linkedFX struct{ lineBuffer }
+
+func (b *linkedFX) Styles(ln int) []Span {
+	if ln != 0 {
+		return nil
+	}
+	return []Span{{Start: 4, End: 10,
+		Style: lines.DefaultStyle.WithAA(lines.Underline)}}
+}
+
+func (b *linkedFX) Link(ln, cl int) (string, bool) {
+	return "y", ln == 0 && cl >= 4 && cl < 10
+}
+
+func TestAnEditor(t *testing.T) {
+	t.Parallel()
+	Run(&AnEditor{}, t)
+}
@@ -0,0 +1,227 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package edt
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Pos is a position in a Buffer.
+type Pos struct{ Ln, Cl int }
+
+// before returns true if given position p is before given position q.
+func (p Pos) before(q Pos) bool {
+	return p.Ln < q.Ln || p.Ln == q.Ln && p.Cl < q.Cl
+}
+
+// Motion returns the target position of a movement starting at given
+// position at in given buffer b and false if there is no target.  If
+// including is set a motion to a searched text targets the position
+// after the found text when moving forward and the found text's first
+// rune when moving backward.  Otherwise the found text is excluded.
+// Motions to word and line boundaries ignore including.
+type Motion func(b Buffer, at Pos, including bool) (Pos, bool)
+
+// Til returns the motion to the next occurrence of given text s.
+func Til(s string) Motion {
+	return func(b Buffer, at Pos, including bool) (Pos, bool) {
+		if s == "" {
+			return at, false
+		}
+		n := utf8.RuneCountInString(s)
+		for ln, cl := at.Ln, at.Cl+1; ln < b.Lines(); ln, cl = ln+1, 0 {
+			rr := []rune(b.Line(ln))
+			if cl > len(rr) {
+				continue
+			}
+			tail := string(rr[cl:])
+			i := strings.Index(tail, s)
+			if i < 0 {
+				continue
+			}
+			cl += utf8.RuneCountInString(tail[:i])
+			if including {
+				cl += n
+			}
+			return Pos{Ln: ln, Cl: cl}, true
+		}
+		return at, false
+	}
+}
+
+// TilBack returns the motion to the previous occurrence of given text
+// s which ends before the starting position.
+func TilBack(s string) Motion {
+	return func(b Buffer, at Pos, including bool) (Pos, bool) {
+		if s == "" {
+			return at, false
+		}
+		n := utf8.RuneCountInString(s)
+		for ln := at.Ln; ln >= 0; ln-- {
+			rr := []rune(b.Line(ln))
+			if ln == at.Ln && at.Cl < len(rr) {
+				rr = rr[:at.Cl]
+			}
+			head := string(rr)
+			i := strings.LastIndex(head, s)
+			if i < 0 {
+				continue
+			}
+			cl := utf8.RuneCountInString(head[:i])
+			if !including {
+				cl += n
+			}
+			return Pos{Ln: ln, Cl: cl}, true
+		}
+		return at, false
+	}
+}
+
+// WordEnd is the motion to the end of the word under the cursor or of
+// the next word in the current line.
+func WordEnd(b Buffer, at Pos, _ bool) (Pos, bool) {
+	rr, cl := []rune(b.Line(at.Ln)), at.Cl
+	for cl < len(rr) && !isWord(rr[cl]) {
+		cl++
+	}
+	if cl == len(rr) {
+		return at, false
+	}
+	for cl < len(rr) && isWord(rr[cl]) {
+		cl++
+	}
+	return Pos{Ln: at.Ln, Cl: cl}, true
+}
+
+// NextWord is the motion to the beginning of the next word which may
+// be in one of the following lines.
+func NextWord(b Buffer, at Pos, _ bool) (Pos, bool) {
+	rr, cl := []rune(b.Line(at.Ln)), at.Cl
+	for cl < len(rr) && isWord(rr[cl]) {
+		cl++
+	}
+	for ln := at.Ln; ln < b.Lines(); ln++ {
+		if ln > at.Ln {
+			rr, cl = []rune(b.Line(ln)), 0
+		}
+		for cl < len(rr) && !isWord(rr[cl]) {
+			cl++
+		}
+		if cl < len(rr) {
+			return Pos{Ln: ln, Cl: cl}, true
+		}
+	}
+	return at, false
+}
+
+// LineEnd is the motion to the position after the current line's last
+// rune.
+func LineEnd(b Buffer, at Pos, _ bool) (Pos, bool) {
+	n := utf8.RuneCountInString(b.Line(at.Ln))
+	if at.Cl >= n {
+		return at, false
+	}
+	return Pos{Ln: at.Ln, Cl: n}, true
+}
+
+// WordBeginning is the motion to the beginning of the word under or
+// left of the cursor or of the previous word in the current line.
+func WordBeginning(b Buffer, at Pos, _ bool) (Pos, bool) {
+	rr, cl := []rune(b.Line(at.Ln)), at.Cl
+	if cl > len(rr) {
+		cl = len(rr)
+	}
+	for cl > 0 && !isWord(rr[cl-1]) {
+		cl--
+	}
+	if cl == 0 {
+		return at, false
+	}
+	for cl > 0 && isWord(rr[cl-1]) {
+		cl--
+	}
+	return Pos{Ln: at.Ln, Cl: cl}, true
+}
+
+// PreviousWord is the motion to the beginning of the word preceding
+// the word under the cursor which may be in one of the previous lines.
+func PreviousWord(b Buffer, at Pos, _ bool) (Pos, bool) {
+	rr, cl := []rune(b.Line(at.Ln)), at.Cl
+	if cl > len(rr) {
+		cl = len(rr)
+	}
+	for cl > 0 && cl < len(rr) && isWord(rr[cl]) && isWord(rr[cl-1]) {
+		cl--
+	}
+	for ln := at.Ln; ln >= 0; ln-- {
+		if ln < at.Ln {
+			rr = []rune(b.Line(ln))
+			cl = len(rr)
+		}
+		if p, ok := WordBeginning(b, Pos{Ln: ln, Cl: cl}, false); ok {
+			return p, true
+		}
+	}
+	return at, false
+}
+
+// LineBeginning is the motion to the current line's first rune.
+func LineBeginning(b Buffer, at Pos, _ bool) (Pos, bool) {
+	if at.Cl == 0 {
+		return at, false
+	}
+	return Pos{Ln: at.Ln}, true
+}
+
+func isWord(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// span returns the number of runes between given positions from and to
+// in given buffer b whereas a line break counts as one rune.
+func span(b Buffer, from, to Pos) int {
+	if from.Ln == to.Ln {
+		return to.Cl - from.Cl
+	}
+	n := utf8.RuneCountInString(b.Line(from.Ln)) - from.Cl + 1
+	for ln := from.Ln + 1; ln < to.Ln; ln++ {
+		n += utf8.RuneCountInString(b.Line(ln)) + 1
+	}
+	return n + to.Cl
+}
+
+// text returns the text between given positions from and to in given
+// buffer b.
+func text(b Buffer, from, to Pos) string {
+	rr := []rune(b.Line(from.Ln))
+	if from.Ln == to.Ln {
+		return string(rr[from.Cl:to.Cl])
+	}
+	ss := []string{string(rr[from.Cl:])}
+	for ln := from.Ln + 1; ln < to.Ln; ln++ {
+		ss = append(ss, b.Line(ln))
+	}
+	return strings.Join(append(
+		ss, string([]rune(b.Line(to.Ln))[:to.Cl])), "\n")
+}
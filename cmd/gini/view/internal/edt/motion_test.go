@@ -0,0 +1,93 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package edt
+
+import (
+	"testing"
+
+	. "github.com/slukits/gounit"
+)
+
+type AMotion struct{ Suite }
+
+func (s *AMotion) SetUp(t *T) { t.Parallel() }
+
+func mtnFX(ll ...string) Buffer { return &lineBuffer{ll: ll} }
+
+func (s *AMotion) Targets_next_occurrence_of_text(t *T) {
+	b := mtnFX("gini 42 is", "not 42")
+	p, ok := Til("42")(b, Pos{}, false)
+	t.True(ok)
+	t.Eq(Pos{Ln: 0, Cl: 5}, p)
+	p, _ = Til("42")(b, Pos{}, true)
+	t.Eq(Pos{Ln: 0, Cl: 7}, p)
+	p, _ = Til("42")(b, Pos{Ln: 0, Cl: 5}, false)
+	t.Eq(Pos{Ln: 1, Cl: 4}, p)
+	_, ok = Til("24")(b, Pos{}, false)
+	t.Not.True(ok)
+}
+
+func (s *AMotion) Targets_previous_occurrence_of_text(t *T) {
+	b := mtnFX("gini 42 is", "not 42")
+	p, ok := TilBack("42")(b, Pos{Ln: 1, Cl: 3}, false)
+	t.True(ok)
+	t.Eq(Pos{Ln: 0, Cl: 7}, p)
+	p, _ = TilBack("42")(b, Pos{Ln: 1, Cl: 3}, true)
+	t.Eq(Pos{Ln: 0, Cl: 5}, p)
+	_, ok = TilBack("42")(b, Pos{Ln: 0, Cl: 6}, false)
+	t.Not.True(ok)
+}
+
+func (s *AMotion) Targets_word_boundaries(t *T) {
+	b := mtnFX("gini is", "  not_an ide")
+	p, _ := WordEnd(b, Pos{Ln: 0, Cl: 1}, false)
+	t.Eq(Pos{Ln: 0, Cl: 4}, p)
+	p, _ = WordEnd(b, Pos{Ln: 0, Cl: 4}, false)
+	t.Eq(Pos{Ln: 0, Cl: 7}, p)
+	p, _ = NextWord(b, Pos{Ln: 0, Cl: 1}, false)
+	t.Eq(Pos{Ln: 0, Cl: 5}, p)
+	p, _ = NextWord(b, Pos{Ln: 0, Cl: 5}, false)
+	t.Eq(Pos{Ln: 1, Cl: 2}, p)
+	p, _ = WordBeginning(b, Pos{Ln: 1, Cl: 6}, false)
+	t.Eq(Pos{Ln: 1, Cl: 2}, p)
+	p, _ = PreviousWord(b, Pos{Ln: 1, Cl: 6}, false)
+	t.Eq(Pos{Ln: 0, Cl: 5}, p)
+	p, _ = PreviousWord(b, Pos{Ln: 1, Cl: 9}, false)
+	t.Eq(Pos{Ln: 1, Cl: 2}, p)
+	_, ok := WordBeginning(b, Pos{Ln: 1, Cl: 2}, false)
+	t.Not.True(ok)
+}
+
+func (s *AMotion) Targets_line_boundaries(t *T) {
+	b := mtnFX("gini is")
+	p, _ := LineEnd(b, Pos{Ln: 0, Cl: 1}, false)
+	t.Eq(Pos{Ln: 0, Cl: 7}, p)
+	p, _ = LineBeginning(b, Pos{Ln: 0, Cl: 3}, false)
+	t.Eq(Pos{}, p)
+	_, ok := LineBeginning(b, Pos{}, false)
+	t.Not.True(ok)
+}
+
+func TestAMotion(t *testing.T) {
+	t.Parallel()
+	Run(&AMotion{}, t)
+}
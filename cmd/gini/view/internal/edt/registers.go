@@ -0,0 +1,97 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package edt
+
+import ring "github.com/slukits/gini/pkg/collections"
+
+// Registers keep the text an Editor copied in a copy ring and the text
+// it deleted in a delete ring.  The latest entry of either ring is
+// pasted by the p command.
+type Registers interface {
+
+	// Copied adds given text to the copy ring.
+	Copied(string)
+
+	// Deleted adds given text to the delete ring.
+	Deleted(string)
+
+	// Latest returns the most recently copied or deleted text.
+	Latest() string
+
+	// Copies returns the entries of the copy ring latest first.
+	Copies() []string
+
+	// Deletions returns the entries of the delete ring latest first.
+	Deletions() []string
+}
+
+// RingSize is the number of entries Rings keep in each ring.
+const RingSize = 20
+
+// Rings are Registers which are kept in memory.  They are the Registers
+// of an Editor which wasn't given Registers.  The zero value is ready
+// to use.
+type Rings struct {
+	copies, deletions ring.Ring[string]
+	latest            string
+}
+
+func (r *Rings) Copied(s string) {
+	r.copies.Capacity = RingSize
+	r.copies.Add(s)
+	r.latest = s
+}
+
+func (r *Rings) Deleted(s string) {
+	r.deletions.Capacity = RingSize
+	r.deletions.Add(s)
+	r.latest = s
+}
+
+func (r *Rings) Latest() string { return r.latest }
+
+// Restore replaces the entries of given rings r by given copies and
+// deletions which are listed oldest first and sets the latest text.
+func (r *Rings) Restore(copies, deletions []string, latest string) {
+	r.copies = ring.Ring[string]{Capacity: RingSize}
+	r.deletions = ring.Ring[string]{Capacity: RingSize}
+	for _, s := range copies {
+		r.copies.Add(s)
+	}
+	for _, s := range deletions {
+		r.deletions.Add(s)
+	}
+	r.latest = latest
+}
+
+func (r *Rings) Copies() []string { return entries(&r.copies) }
+
+func (r *Rings) Deletions() []string { return entries(&r.deletions) }
+
+// entries returns the entries of given ring rg latest first.
+func entries(rg *ring.Ring[string]) (ss []string) {
+	rg.For(func(_ int, e string) (stop bool) {
+		ss = append(ss, e)
+		return false
+	})
+	return ss
+}
@@ -0,0 +1,294 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package view
+
+import (
+	"github.com/slukits/lines"
+)
+
+// ColumnWidth is the content width of the single column of a view's
+// default layout which is centered if the screen is wide enough.
+const ColumnWidth = 80
+
+// MinColumnWidth is the minimal width a column can be resized to.
+const MinColumnWidth = 10
+
+// Columns lays out its columns side by side.  A single column which
+// hasn't been resized is centered having a width of ColumnWidth if the
+// screen is wide enough.  Otherwise the columns fill the screen.  Note
+// that a component's dimensions may only be accessed during its own
+// event callbacks hence Columns posts update events to its columns to
+// resize them.
+type Columns struct {
+	lines.Component
+	lines.Chaining
+
+	cc []*Column
+
+	// col and split are the indices of the current split
+	col, split int
+}
+
+// newColumns returns columns having one column with given split.
+func newColumns(split lines.Componenter) *Columns {
+	cc := &Columns{cc: []*Column{newColumn(split)}}
+	cc.CC = append(cc.CC, cc.cc[0])
+	return cc
+}
+
+// Column stacks its splits vertically, e.g. editors, command output or
+// help pages.
+type Column struct {
+	lines.Component
+	lines.Stacking
+
+	resized bool
+}
+
+// filler fills the remaining screen space of given width around a
+// centered column.
+type filler struct {
+	lines.Component
+	width int
+}
+
+func (f *filler) OnInit(e *lines.Env) { f.Dim().SetWidth(f.width) }
+
+// newColumn returns a column stacking given splits.
+func newColumn(splits ...lines.Componenter) *Column {
+	c := &Column{}
+	c.CC = append(c.CC, splits...)
+	return c
+}
+
+func (c *Column) OnInit(e *lines.Env) {
+	c.Gaps(0).Vertical.AA(lines.Reverse)
+	lines.Print(c.Gaps(0).Vertical.At(0).Filling(), ' ')
+}
+
+// Len returns the number of splits of given column c.
+func (c *Column) Len() int { return len(c.CC) }
+
+// Split returns given column c's split with given index i or nil.
+func (c *Column) Split(i int) lines.Componenter {
+	if i < 0 || i >= len(c.CC) {
+		return nil
+	}
+	return c.CC[i]
+}
+
+func (c *Column) add(at int, split lines.Componenter) {
+	c.CC = append(c.CC[:at], append([]lines.Componenter{split},
+		c.CC[at:]...)...)
+}
+
+// remover is implemented by a split which needs to be informed about
+// its removal, e.g. to stop a running grep.
+type remover interface{ removed() }
+
+// remove removes the split at given index whereas a remover is
+// informed about its removal.
+func (c *Column) remove(at int) {
+	if r, ok := c.CC[at].(remover); ok {
+		r.removed()
+	}
+	c.CC = append(c.CC[:at], c.CC[at+1:]...)
+}
+
+// OnLayout updates the fillers around a centered column if the
+// available width changed.  Since components may not be added during a
+// layout the fillers are updated by a subsequent update event.
+func (cc *Columns) OnLayout(e *lines.Env) (reflow bool) {
+	left, right := cc.fillers()
+	if len(cc.CC) == len(cc.cc) && left == 0 ||
+		len(cc.CC) > len(cc.cc) &&
+			cc.CC[0].(*filler).width == left &&
+			cc.CC[2].(*filler).width == right {
+		return false
+	}
+	e.Lines.Update(cc, nil, cc.layout)
+	return false
+}
+
+// fillers returns the widths of the fillers left and right of a
+// centered column which are zero if the column isn't centered.
+func (cc *Columns) fillers() (left, right int) {
+	if len(cc.cc) != 1 || cc.cc[0].resized ||
+		cc.Dim().Width() <= ColumnWidth+2 {
+		return 0, 0
+	}
+	left = (cc.Dim().Width() - ColumnWidth - 2) / 2
+	return left, cc.Dim().Width() - ColumnWidth - 2 - left
+}
+
+// layout updates the chained components to given columns cc's columns
+// surrounding a single centered column with fillers.
+func (cc *Columns) layout(e *lines.Env) {
+	if left, right := cc.fillers(); left > 0 {
+		cc.CC = []lines.Componenter{
+			&filler{width: left}, cc.cc[0], &filler{width: right}}
+		return
+	}
+	cc.CC = make([]lines.Componenter, 0, len(cc.cc))
+	for _, c := range cc.cc {
+		cc.CC = append(cc.CC, c)
+	}
+}
+
+// Len returns the number of columns.
+func (cc *Columns) Len() int { return len(cc.cc) }
+
+// Column returns the column with given index i or nil.
+func (cc *Columns) Column(i int) *Column {
+	if i < 0 || i >= len(cc.cc) {
+		return nil
+	}
+	return cc.cc[i]
+}
+
+// Current returns the column and split index of the current split.
+func (cc *Columns) Current() (col, split int) { return cc.col, cc.split }
+
+// CurrentSplit returns the current split.
+func (cc *Columns) CurrentSplit() lines.Componenter {
+	if len(cc.cc) == 0 {
+		return nil
+	}
+	return cc.cc[cc.col].Split(cc.split)
+}
+
+// Find returns the column and split index of given split and false if
+// it isn't a split of the columns.
+func (cc *Columns) Find(split lines.Componenter) (col, idx int, ok bool) {
+	for i, c := range cc.cc {
+		for j, s := range c.CC {
+			if s == split {
+				return i, j, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// Add adds a new column stacking given splits at given index at and
+// focuses its first split.
+func (cc *Columns) Add(e *lines.Env, at int, splits ...lines.Componenter) {
+	if at < 0 || at > len(cc.cc) {
+		at = len(cc.cc)
+	}
+	c := newColumn(splits...)
+	cc.cc = append(cc.cc[:at], append([]*Column{c}, cc.cc[at:]...)...)
+	cc.layout(e)
+	cc.Focus(e, at, 0)
+}
+
+// Split adds given split below the current split and focuses it.
+func (cc *Columns) Split(e *lines.Env, split lines.Componenter) {
+	if len(cc.cc) == 0 {
+		cc.Add(e, 0, split)
+		return
+	}
+	cc.cc[cc.col].add(cc.split+1, split)
+	cc.Focus(e, cc.col, cc.split+1)
+}
+
+// Show adds given split below the current split without focusing it,
+// e.g. to show a help page while the context bar stays active.
+func (cc *Columns) Show(e *lines.Env, split lines.Componenter) {
+	if len(cc.cc) == 0 {
+		cc.Add(e, 0, split)
+		return
+	}
+	cc.cc[cc.col].add(cc.split+1, split)
+}
+
+// Remove removes the current split and its column if it was the
+// column's last split.  The last split is not removed.
+func (cc *Columns) Remove(e *lines.Env) {
+	if len(cc.cc) == 0 || len(cc.cc) == 1 && cc.cc[0].Len() == 1 {
+		return
+	}
+	c := cc.cc[cc.col]
+	c.remove(cc.split)
+	if c.Len() > 0 {
+		cc.Focus(e, cc.col, cc.split)
+		return
+	}
+	cc.cc = append(cc.cc[:cc.col], cc.cc[cc.col+1:]...)
+	cc.layout(e)
+	cc.Focus(e, cc.col, 0)
+}
+
+// Resize changes the width of the current column by given delta d
+// fixing its width while the other columns share the remaining width.
+func (cc *Columns) Resize(e *lines.Env, d int) {
+	if len(cc.cc) == 0 {
+		return
+	}
+	c := cc.cc[cc.col]
+	e.Lines.Update(c, nil, func(ce *lines.Env) {
+		if c.Dim().Width()+d < MinColumnWidth {
+			return
+		}
+		c.Dim().SetWidth(c.Dim().Width() + d)
+		if !c.resized {
+			c.resized = true
+			ce.Lines.Update(cc, nil, cc.layout)
+		}
+	})
+}
+
+// Move focuses the split which is given dx columns and dy splits away
+// from the current split.  Moving to an other column focuses its first
+// split.
+func (cc *Columns) Move(e *lines.Env, dx, dy int) {
+	if dx != 0 {
+		cc.Focus(e, cc.col+dx, 0)
+		return
+	}
+	cc.Focus(e, cc.col, cc.split+dy)
+}
+
+// Focus makes the split with given split index in the column with
+// given column index current and focuses it.  Indices are clamped to
+// existing columns and splits.  Since a split added during the current
+// event isn't initialized before the next event the focus is moved by
+// a subsequent update event.
+func (cc *Columns) Focus(e *lines.Env, col, split int) {
+	if len(cc.cc) == 0 {
+		return
+	}
+	cc.col = clamp(col, len(cc.cc))
+	cc.split = clamp(split, cc.cc[cc.col].Len())
+	focus := cc.CurrentSplit()
+	e.Lines.Update(cc, nil, func(e *lines.Env) { e.Lines.Focus(focus) })
+}
+
+func clamp(i, n int) int {
+	if i >= n {
+		i = n - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	return i
+}
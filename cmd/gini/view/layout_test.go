@@ -0,0 +1,134 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package view
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/slukits/gini/cmd/gini/view/internal/edt"
+	. "github.com/slukits/gounit"
+	"github.com/slukits/lines"
+)
+
+type Layout struct{ Suite }
+
+func (s *Layout) SetUp(t *T) { t.Parallel() }
+
+// layoutFX returns a fixture of given width for a view with an editor
+// showing "gini" whose added splits are editors showing "split".
+func layoutFX(t *T, width int) (*lines.Fixture, *View) {
+	vw := &View{
+		Buffer: &bufferFX{ll: []string{"gini"}},
+		NewSplit: func() lines.Componenter {
+			return &edt.Editor{Buffer: &bufferFX{ll: []string{"split"}}}
+		},
+	}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fx.Lines.Quitting.DelRune('q')
+	fx.FireResize(width, 24)
+	return fx, vw
+}
+
+func fire(fx *lines.Fixture, rr string) {
+	for _, r := range rr {
+		fx.FireRune(r)
+	}
+}
+
+func (s *Layout) Centers_single_column_on_wide_screen(t *T) {
+	fx, _ := layoutFX(t, 120)
+	t.Eq((120-ColumnWidth-2)/2+1, strings.Index(fx.Screen()[1], "gini"))
+}
+
+func (s *Layout) Fills_screen_with_single_column_on_narrow_screen(t *T) {
+	fx, _ := layoutFX(t, 60)
+	t.Eq(1, strings.Index(fx.Screen()[1], "gini"))
+}
+
+func (s *Layout) Adds_focused_column_right_of_current_column(t *T) {
+	fx, vw := layoutFX(t, 120)
+	fire(fx, " ca")
+	t.Eq(2, vw.Columns().Len())
+	t.Eq(1, strings.Index(fx.Screen()[1], "gini"))
+	t.Eq(61, strings.Index(fx.Screen()[1], "split"))
+	col, split := vw.Columns().Current()
+	t.Eq(1, col)
+	t.Eq(0, split)
+	fire(fx, "i")
+	t.Eq(edt.Insert, vw.Columns().Column(1).Split(0).(*edt.Editor).Mode())
+	t.Eq(edt.Command, vw.Editor().(*edt.Editor).Mode())
+}
+
+func (s *Layout) Stacks_splits_in_a_column(t *T) {
+	fx, vw := layoutFX(t, 120)
+	fire(fx, " cs")
+	t.Eq(2, vw.Columns().Column(0).Len())
+	t.Contains(fx.ScreenOf(vw.Editor()), "gini")
+	t.Contains(fx.ScreenOf(vw.Columns().Column(0).Split(1)), "split")
+	_, split := vw.Columns().Current()
+	t.Eq(1, split)
+}
+
+func (s *Layout) Moves_focus_between_splits(t *T) {
+	fx, vw := layoutFX(t, 120)
+	fire(fx, " cs ca")
+	fire(fx, " cp")
+	col, split := vw.Columns().Current()
+	t.Eq(0, col)
+	t.Eq(0, split)
+	fire(fx, " cj")
+	col, split = vw.Columns().Current()
+	t.Eq(0, col)
+	t.Eq(1, split)
+	fire(fx, "l")
+	_, cl := vw.Columns().CurrentSplit().(*edt.Editor).Cursor()
+	t.Eq(1, cl)
+	fire(fx, " cn")
+	col, _ = vw.Columns().Current()
+	t.Eq(1, col)
+}
+
+func (s *Layout) Removes_splits_and_empty_columns(t *T) {
+	fx, vw := layoutFX(t, 120)
+	fire(fx, " ca cs")
+	t.Eq(2, vw.Columns().Column(1).Len())
+	fire(fx, " cr")
+	t.Eq(1, vw.Columns().Column(1).Len())
+	fire(fx, " cr")
+	t.Eq(1, vw.Columns().Len())
+	fire(fx, " cr")
+	t.Eq(1, vw.Columns().Len())
+	t.Eq((120-ColumnWidth-2)/2+1, strings.Index(fx.Screen()[1], "gini"))
+}
+
+func (s *Layout) Resizes_current_column(t *T) {
+	fx, _ := layoutFX(t, 120)
+	fire(fx, " ca")
+	fire(fx, " c--")
+	t.Eq(63, strings.Index(fx.Screen()[1], "split"))
+}
+
+func TestLayout(t *testing.T) {
+	t.Parallel()
+	Run(&Layout{}, t)
+}
@@ -0,0 +1,367 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package view
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/slukits/gini/cmd/gini/view/internal/cnt"
+	"github.com/slukits/gini/cmd/gini/view/internal/edt"
+	"github.com/slukits/lines"
+)
+
+// Keystroke is a recorded keyboard input which is either a rune or, if
+// Rune is zero, a key.
+type Keystroke struct {
+	Rune rune
+	Key  lines.Key
+	Mod  lines.ModifierMask
+}
+
+// Macro is a named sequence of recorded keystrokes which may be
+// replayed.
+type Macro struct {
+	Name string
+	Keys []Keystroke
+}
+
+// Macros keep recorded keyboard macros.
+type Macros interface {
+
+	// Recorded adds given macro which becomes the latest macro.
+	Recorded(Macro)
+
+	// Macros returns the kept macros latest first.
+	Macros() []Macro
+
+	// Buffer returns the i-th latest macro as editable text (see
+	// Macro.String) whose saving replaces the macro by the parsed
+	// text (see ParseMacro).  Buffer returns nil if macros can't be
+	// edited.
+	Buffer(i int) Buffer
+}
+
+// MacrosSize is the number of macros kept by the default Macros of a
+// View.
+const MacrosSize = 20
+
+// macros are the Macros of a View which wasn't given Macros.  They are
+// kept in memory and can't be edited.
+type macros struct{ mm []Macro }
+
+func (mm *macros) Recorded(m Macro) {
+	mm.mm = append([]Macro{m}, mm.mm...)
+	if len(mm.mm) > MacrosSize {
+		mm.mm = mm.mm[:MacrosSize]
+	}
+}
+
+func (mm *macros) Macros() []Macro { return mm.mm }
+
+func (mm *macros) Buffer(int) Buffer { return nil }
+
+// keyNames are the names of keys in a macro's text representation.
+var keyNames = map[lines.Key]string{
+	lines.Enter:     "enter",
+	lines.Tab:       "tab",
+	lines.Backspace: "backspace",
+	lines.DEL:       "del",
+	lines.Esc:       "esc",
+	lines.Up:        "up",
+	lines.Down:      "down",
+	lines.Left:      "left",
+	lines.Right:     "right",
+	lines.PgUp:      "pgup",
+	lines.PgDn:      "pgdn",
+	lines.Home:      "home",
+	lines.End:       "end",
+	lines.Insert:    "insert",
+	lines.Delete:    "delete",
+}
+
+// modNames are the names of modifiers in a macro's text representation.
+var modNames = []struct {
+	mod  lines.ModifierMask
+	name string
+}{
+	{lines.Shift, "shift"},
+	{lines.Ctrl, "ctrl"},
+	{lines.Alt, "alt"},
+	{lines.Meta, "meta"},
+}
+
+// String returns given macro m's text representation: the first line
+// is the macro's name, the following lines its keystrokes.  A rune is
+// represented by itself while a key is represented by its name in
+// angle brackets, e.g. <enter>, or by its code, e.g. <k55>.  Modifiers
+// prefix a name, e.g. <shift+enter>, and a control key is represented
+// by its letter with the ctrl modifier, e.g. <ctrl+g>, while '<' and a
+// line feed are represented by <lt> and <lf>.  Line breaks between
+// keystrokes are ignored.
+func (m Macro) String() string {
+	b := &strings.Builder{}
+	b.WriteString(m.Name + "\n")
+	for _, k := range m.Keys {
+		b.WriteString(k.String())
+	}
+	return b.String()
+}
+
+// String returns given keystroke k's text representation.
+func (k Keystroke) String() string {
+	name, mod := string(k.Rune), k.Mod
+	switch {
+	case k.Rune == 0 && keyNames[k.Key] != "":
+		name = keyNames[k.Key]
+	case k.Rune == 0 && k.Key >= lines.CtrlA && k.Key <= lines.CtrlZ:
+		name, mod = string('a'+rune(k.Key-lines.CtrlA)), mod|lines.Ctrl
+	case k.Rune == 0:
+		name = "k" + strconv.Itoa(int(k.Key))
+	case k.Rune == '<':
+		name = "lt"
+	case k.Rune == '\n':
+		name = "lf"
+	case k.Mod == 0:
+		return name
+	}
+	for _, m := range modNames {
+		if mod&m.mod != 0 {
+			name = m.name + "+" + name
+		}
+	}
+	return "<" + name + ">"
+}
+
+// ErrMacro is returned by ParseMacro for an invalid macro text.
+var ErrMacro = errors.New("gini: view: macro: invalid keystroke")
+
+// ParseMacro parses given macro text s (see Macro.String).
+func ParseMacro(s string) (Macro, error) {
+	name, keys, _ := strings.Cut(s, "\n")
+	m, rr := Macro{Name: name}, []rune(keys)
+	for i := 0; i < len(rr); i++ {
+		switch rr[i] {
+		case '\n':
+			continue
+		case '<':
+		default:
+			m.Keys = append(m.Keys, Keystroke{Rune: rr[i]})
+			continue
+		}
+		end := i + 1
+		for end < len(rr) && rr[end] != '>' {
+			end++
+		}
+		if end == len(rr) {
+			return m, fmt.Errorf("%w: %s", ErrMacro, string(rr[i:]))
+		}
+		k, err := parseKeystroke(string(rr[i+1 : end]))
+		if err != nil {
+			return m, err
+		}
+		m.Keys, i = append(m.Keys, k), end
+	}
+	return m, nil
+}
+
+// parseKeystroke parses given text s of a keystroke without its angle
+// brackets whereas a letter with the ctrl modifier is a control key.
+func parseKeystroke(s string) (Keystroke, error) {
+	k, pp := Keystroke{}, strings.Split(s, "+")
+	for _, p := range pp[:len(pp)-1] {
+		found := false
+		for _, m := range modNames {
+			if m.name == p {
+				k.Mod, found = k.Mod|m.mod, true
+			}
+		}
+		if !found {
+			return k, fmt.Errorf("%w: <%s>", ErrMacro, s)
+		}
+	}
+	name := pp[len(pp)-1]
+	switch name {
+	case "lt":
+		k.Rune = '<'
+		return k, nil
+	case "lf":
+		k.Rune = '\n'
+		return k, nil
+	}
+	for key, n := range keyNames {
+		if n == name {
+			k.Key = key
+			return k, nil
+		}
+	}
+	if rr := []rune(name); len(rr) == 1 {
+		if k.Mod&lines.Ctrl != 0 && rr[0] >= 'a' && rr[0] <= 'z' {
+			k.Key = lines.CtrlA + lines.Key(rr[0]-'a')
+			return k, nil
+		}
+		k.Rune = rr[0]
+		return k, nil
+	}
+	code, err := strconv.Atoi(strings.TrimPrefix(name, "k"))
+	if err != nil || !strings.HasPrefix(name, "k") {
+		return k, fmt.Errorf("%w: <%s>", ErrMacro, s)
+	}
+	k.Key = lines.Key(code)
+	return k, nil
+}
+
+// typed records given keystroke while a macro is recorded.
+func (v *View) typed(r rune, k lines.Key, mm lines.ModifierMask) {
+	if v.recording == nil {
+		return
+	}
+	v.recording.Keys = append(v.recording.Keys,
+		Keystroke{Rune: r, Key: k, Mod: mm})
+}
+
+// record starts recording a keyboard macro or stops a running recording
+// adding the recorded macro to the view's macros.  The r stopping the
+// recording isn't part of the macro.
+func (v *View) record(e *lines.Env) {
+	if v.recording == nil {
+		v.recording = &Macro{}
+		v.message(e, "recording macro")
+		return
+	}
+	m := *v.recording
+	v.recording = nil
+	if len(m.Keys) > 0 {
+		m.Keys = m.Keys[:len(m.Keys)-1]
+	}
+	if len(m.Keys) == 0 {
+		v.message(e, "empty macro discarded")
+		return
+	}
+	m.Name = abbreviated(Macro{Keys: m.Keys}.String()[1:])
+	v.Macros.Recorded(m)
+	v.message(e, "recorded macro "+m.Name)
+}
+
+// Replay replays given macro m given number n of times.  Each keystroke
+// is typed into the focused component whereas unconsumed keystrokes
+// bubble to the view.  Since a keystroke may move the focus the next
+// keystroke is replayed by a subsequent update event, i.e. the replay
+// starts after pending focus changes.
+func (v *View) Replay(e *lines.Env, m Macro, n int) {
+	kk := []Keystroke{}
+	for i := 0; i < n; i++ {
+		kk = append(kk, m.Keys...)
+	}
+	e.Lines.Update(v, nil, func(e *lines.Env) { v.replay(e, kk) })
+}
+
+// typer is implemented by the components keystrokes are replayed to.
+type typer interface {
+	Type(*lines.Env, rune, lines.Key, lines.ModifierMask) bool
+}
+
+func (v *View) replay(e *lines.Env, kk []Keystroke) {
+	if len(kk) == 0 {
+		return
+	}
+	k, focused := kk[0], e.Focused()
+	e.Lines.Update(focused, nil, func(e *lines.Env) {
+		t, ok := focused.(typer)
+		if !ok || !t.Type(e, k.Rune, k.Key, k.Mod) {
+			e.Lines.Update(v, nil, func(e *lines.Env) {
+				v.bubbled(e, k)
+			})
+		}
+		e.Lines.Update(v, nil, func(e *lines.Env) {
+			v.replay(e, kk[1:])
+		})
+	})
+}
+
+// bubbled processes given replayed keystroke k which wasn't consumed by
+// the focused component.
+func (v *View) bubbled(e *lines.Env, k Keystroke) {
+	if k.Rune != 0 {
+		v.OnRune(e, k.Rune, k.Mod)
+		return
+	}
+	v.OnKey(e, k.Key, k.Mod)
+}
+
+// macroContexts returns the contexts of the kept macros paged by
+// cnt.Paged.  A macro's context replays the macro as many times as its
+// input box says and allows to edit the macro.
+func (v *View) macroContexts() (ii []*cnt.Item) {
+	for i, m := range v.Macros.Macros() {
+		i, m := i, m
+		ii = append(ii, &cnt.Item{Label: m.Name,
+			Input: &cnt.Input{Apply: func(e *lines.Env, s string) {
+				n, err := strconv.Atoi(s)
+				if s == "" {
+					n, err = 1, nil
+				}
+				if err != nil || n < 1 {
+					v.message(e, "replay: invalid count "+s)
+					return
+				}
+				v.Replay(e, m, n)
+			}},
+			Items: []*cnt.Item{{Label: "edit", Key: 'e',
+				Exec: func(e *lines.Env) { v.editMacro(e, i) }}},
+		})
+	}
+	return cnt.Paged(ii)
+}
+
+// editMacro opens the i-th latest macro's text in an editor below the
+// current split.
+func (v *View) editMacro(e *lines.Env, i int) {
+	if i >= len(v.Macros.Macros()) {
+		v.message(e, "no macro recorded")
+		return
+	}
+	b := v.Macros.Buffer(i)
+	if b == nil {
+		v.message(e, "macros can't be edited")
+		return
+	}
+	ed, cc := v.wired(&edt.Editor{Buffer: b}), v.Columns()
+	e.Lines.Update(cc, nil, func(e *lines.Env) { cc.Split(e, ed) })
+}
+
+// message shows given message msg in the context bar.
+func (v *View) message(e *lines.Env, msg string) {
+	e.Lines.Update(v.Context(), cnt.Message(msg), nil)
+}
+
+// abbreviated returns given string s on one line with at most
+// RingLabelWidth runes.
+func abbreviated(s string) string {
+	label := []rune(strings.ReplaceAll(s, "\n", "⏎"))
+	if len(label) > RingLabelWidth {
+		label = append(label[:RingLabelWidth-1], '…')
+	}
+	return string(label)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package view
+
+import (
+	"strconv"
+	"testing"
+
+	. "github.com/slukits/gounit"
+	"github.com/slukits/lines"
+)
+
+type AMacro struct{ Suite }
+
+func (s *AMacro) SetUp(t *T) { t.Parallel() }
+
+func (s *AMacro) Has_an_editable_text_representation(t *T) {
+	m := Macro{Name: "gini", Keys: []Keystroke{
+		{Rune: 'i'}, {Rune: '<'}, {Key: lines.Esc},
+		{Key: lines.Enter, Mod: lines.Shift},
+		{Key: lines.CtrlG, Mod: lines.Ctrl}, {Rune: 'G', Mod: lines.Alt},
+		{Key: lines.F1},
+	}}
+	t.Eq("gini\ni<lt><esc><shift+enter><ctrl+g><alt+G><k"+
+		strconv.Itoa(int(lines.F1))+">", m.String())
+	parsed, err := ParseMacro(m.String())
+	t.FatalOn(err)
+	t.Eq(m, parsed)
+}
+
+func (s *AMacro) Represents_control_keys_by_their_letter(t *T) {
+	m := Macro{Name: "gini", Keys: []Keystroke{{Key: lines.CtrlG}}}
+	t.Eq("gini\n<ctrl+g>", m.String())
+	parsed, err := ParseMacro(m.String())
+	t.FatalOn(err)
+	t.Eq([]Keystroke{{Key: lines.CtrlG, Mod: lines.Ctrl}}, parsed.Keys)
+	vw := &View{}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fx.Lines.Update(vw, nil, func(e *lines.Env) {
+		vw.Replay(e, parsed, 1)
+	})
+	t.Contains(fx.ScreenOf(vw.Context()), "project")
+}
+
+func (s *AMacro) Ignores_line_breaks_of_its_text(t *T) {
+	m, err := ParseMacro("gini\nab\nc<enter>")
+	t.FatalOn(err)
+	t.Eq([]Keystroke{{Rune: 'a'}, {Rune: 'b'}, {Rune: 'c'},
+		{Key: lines.Enter}}, m.Keys)
+}
+
+func (s *AMacro) Fails_parsing_invalid_keystrokes(t *T) {
+	_, err := ParseMacro("gini\n<hyper+a>")
+	t.ErrIs(err, ErrMacro)
+	_, err = ParseMacro("gini\n<enter")
+	t.ErrIs(err, ErrMacro)
+	_, err = ParseMacro("gini\n<bogus>")
+	t.ErrIs(err, ErrMacro)
+}
+
+func (s *AMacro) Is_recorded_and_replayed(t *T) {
+	vw := &View{}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fx.FireRune('r')
+	fx.FireRune('i')
+	fx.FireRune('4')
+	fx.FireRune('2')
+	fx.FireKey(lines.Esc)
+	fx.FireRune('r')
+	mm := vw.Macros.Macros()
+	t.FatalIfNot(t.Eq(1, len(mm)))
+	t.Eq("i42<esc>", mm[0].Name)
+	t.Contains(fx.ScreenOf(vw.Context()), "[recorded macro i42<esc>]")
+	fx.Lines.Update(vw, nil, func(e *lines.Env) {
+		vw.Replay(e, mm[0], 2)
+	})
+	// each replay inserts before the cursor which is on the last 2
+	t.Contains(fx.ScreenOf(vw.Editor()), "442422")
+}
+
+func (s *AMacro) Is_replayed_from_macros_context(t *T) {
+	vw := &View{}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	vw.Macros.Recorded(Macro{Name: "gini", Keys: []Keystroke{
+		{Rune: 'i'}, {Rune: 'g'}, {Key: lines.Esc}}})
+	fx.FireRune(' ')
+	fx.FireRune('.')
+	fx.FireRune('a')
+	fx.FireRune('0')
+	fx.FireRune('3')
+	fx.FireKey(lines.Enter)
+	t.Contains(fx.ScreenOf(vw.Editor()), "ggg")
+}
+
+func TestAMacro(t *testing.T) {
+	t.Parallel()
+	Run(&AMacro{}, t)
+}
@@ -0,0 +1,268 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package view
+
+import (
+	"fmt"
+
+	"github.com/slukits/gini/cmd/gini/view/internal/edt"
+	"github.com/slukits/lines"
+)
+
+// Results is a split listing the matches of a grep which are streamed
+// into it while the grep is running.  j/k or the up/down keys select a
+// match and enter jumps to it.  The first line shows the search and the
+// number of matches.
+type Results struct {
+	lines.Component
+
+	// Search is the grep whose matches are listed.
+	Search Search
+
+	// Typed is informed about each rune or key typed into the results,
+	// e.g. to record a keyboard macro.
+	Typed func(r rune, k lines.Key, mm lines.ModifierMask)
+
+	// origin is the editor the grep was started from.
+	origin *edt.Editor
+
+	// pending are the matches which are streamed once the results are
+	// initialized.
+	pending <-chan Match
+
+	// cancel cancels the running grep.
+	cancel func()
+
+	// jump jumps to the selected match.
+	jump func(*lines.Env, *Results, Match)
+
+	mm            []Match
+	selected, top int
+	searching     bool
+
+	// replacement is the previewed replacement or nil.
+	replacement *string
+
+	// grep counts the started greps to ignore matches of a replaced
+	// grep.
+	grep int
+}
+
+// StreamBatch is the maximal number of matches which are added to the
+// results at once.
+const StreamBatch = 256
+
+// streamed reports matches of the grep with given number to the
+// results or the end of the grep.
+type streamed struct {
+	grep    int
+	matches []Match
+	done    bool
+}
+
+// previewed reports the replacement which is previewed by the results
+// or nil to stop the preview.
+type previewed struct{ replacement *string }
+
+// OnInit starts streaming the pending matches.
+func (r *Results) OnInit(e *lines.Env) {
+	cancel := r.cancel
+	r.cancel = nil
+	r.start(e.Lines, r.Search, r.origin, r.pending, cancel)
+	r.pending = nil
+	r.print(e)
+}
+
+// start replaces the listed matches by the matches of given search s
+// which are streamed from given channel mm.  The replaced grep is
+// canceled while given cancel function cancels the started grep.
+func (r *Results) start(
+	ll *lines.Lines, s Search, origin *edt.Editor, mm <-chan Match,
+	cancel func(),
+) {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.cancel = cancel
+	r.grep++
+	r.Search, r.origin, r.mm, r.replacement = s, origin, nil, nil
+	r.selected, r.top, r.searching = 0, 0, true
+	go r.stream(ll, r.grep, mm)
+}
+
+// removed cancels the running grep once the results split is removed.
+func (r *Results) removed() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+}
+
+// stream posts given matches mm of the grep with given number as
+// update events to the results.  Matches which are available at once
+// are posted together up to StreamBatch matches.
+func (r *Results) stream(ll *lines.Lines, grep int, mm <-chan Match) {
+	for m := range mm {
+		ll.Update(r, streamed{grep: grep, matches: batch(m, mm)}, nil)
+	}
+	ll.Update(r, streamed{grep: grep, done: true}, nil)
+}
+
+// batch returns given match m followed by the matches which can be
+// received from given channel mm without blocking up to StreamBatch
+// matches.
+func batch(m Match, mm <-chan Match) []Match {
+	bb := []Match{m}
+	for len(bb) < StreamBatch {
+		select {
+		case m, ok := <-mm:
+			if !ok {
+				return bb
+			}
+			bb = append(bb, m)
+		default:
+			return bb
+		}
+	}
+	return bb
+}
+
+// OnUpdate adds streamed matches and sets the previewed replacement.
+func (r *Results) OnUpdate(e *lines.Env, data interface{}) {
+	switch data := data.(type) {
+	case streamed:
+		if data.grep != r.grep {
+			return
+		}
+		if data.done {
+			r.searching = false
+		}
+		r.mm = append(r.mm, data.matches...)
+	case previewed:
+		r.replacement = data.replacement
+	}
+	r.print(e)
+}
+
+// Matches returns the listed matches.
+func (r *Results) Matches() []Match { return r.mm }
+
+// Selected returns the index of the selected match.
+func (r *Results) Selected() int { return r.selected }
+
+// Searching returns true while matches are streamed into the results.
+func (r *Results) Searching() bool { return r.searching }
+
+// OnRune selects the next or previous match on j or k.
+func (r *Results) OnRune(e *lines.Env, rn rune, mm lines.ModifierMask) {
+	if r.Typed != nil {
+		r.Typed(rn, 0, mm)
+	}
+	if r.Type(e, rn, 0, mm) {
+		e.StopBubbling()
+	}
+}
+
+// OnKey selects the next or previous match on the down or up key and
+// jumps to the selected match on enter.
+func (r *Results) OnKey(e *lines.Env, k lines.Key, mm lines.ModifierMask) {
+	if r.Typed != nil {
+		r.Typed(0, k, mm)
+	}
+	if r.Type(e, 0, k, mm) {
+		e.StopBubbling()
+	}
+}
+
+// Type processes given rune rn or given key k if rn is zero as if it
+// was typed by the user and returns false if the input isn't consumed.
+func (r *Results) Type(
+	e *lines.Env, rn rune, k lines.Key, _ lines.ModifierMask,
+) bool {
+	switch {
+	case rn == 'j' || rn == 0 && k == lines.Down:
+		r.selected = clamp(r.selected+1, len(r.mm))
+	case rn == 'k' || rn == 0 && k == lines.Up:
+		r.selected = clamp(r.selected-1, len(r.mm))
+	case rn == 0 && k == lines.Enter:
+		if len(r.mm) > 0 && r.jump != nil {
+			r.jump(e, r, r.mm[r.selected])
+		}
+		return true
+	default:
+		return false
+	}
+	r.print(e)
+	return true
+}
+
+// print writes the search's header followed by the matches which fit on
+// the screen scrolling them if the selected match isn't visible.
+func (r *Results) print(e *lines.Env) {
+	_, _, _, height := r.ContentArea()
+	if height <= 0 {
+		return
+	}
+	r.Reset(lines.All)
+	header := fmt.Sprintf("%s: %d matches", r.Search, len(r.mm))
+	if r.searching {
+		header += " …"
+	}
+	if r.replacement != nil {
+		header += fmt.Sprintf(" replaced by '%s'", *r.replacement)
+	}
+	fmt.Fprint(e.LL(0), header)
+	if height--; height <= 0 {
+		return
+	}
+	if r.selected < r.top {
+		r.top = r.selected
+	}
+	if r.selected >= r.top+height {
+		r.top = r.selected - height + 1
+	}
+	replace := r.replacer()
+	for i := 0; i < height && r.top+i < len(r.mm); i++ {
+		m := r.mm[r.top+i]
+		line := fmt.Sprintf("%d:%d: %s", m.Ln, m.Cl, replace(m.Line))
+		if m.File != "" {
+			line = m.File + ":" + line
+		}
+		if r.top+i == r.selected {
+			fmt.Fprint(e.LL(i+1).AA(lines.Reverse), line)
+			continue
+		}
+		fmt.Fprint(e.LL(i+1), line)
+	}
+}
+
+// replacer returns the function replacing the matches of a line by the
+// previewed replacement or the identity if there is no preview.
+func (r *Results) replacer() func(string) string {
+	if r.replacement == nil {
+		return func(s string) string { return s }
+	}
+	replace, err := r.Search.Replacer(*r.replacement)
+	if err != nil {
+		return func(s string) string { return s }
+	}
+	return replace
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package view
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/slukits/gini/cmd/gini/view/internal/cnt"
+	"github.com/slukits/lines"
+)
+
+// Setting is the effective value of a setting and the scope it
+// originates from.
+type Setting = cnt.Setting
+
+// SettingsScopes are the names of the scopes a setting may be set in
+// from the most specific to the most general.
+var SettingsScopes = cnt.SettingsScopes
+
+// Settings provide the settings of buffers.
+type Settings interface {
+
+	// Settings returns the effective settings of given buffer b.
+	Settings(b Buffer) []Setting
+
+	// Set sets the setting with given name to given value in given
+	// scope (see SettingsScopes) relative to given buffer b.
+	Set(b Buffer, name, value, scope string) error
+}
+
+// errNoSettings is returned by the settings of a View which wasn't
+// given Settings.
+var errNoSettings = errors.New("gini: view: settings: no settings")
+
+// noSettings are the Settings of a View which wasn't given Settings.
+type noSettings struct{}
+
+func (noSettings) Settings(Buffer) []Setting { return nil }
+
+func (noSettings) Set(_ Buffer, name, _, _ string) error {
+	return fmt.Errorf("%w: %s", errNoSettings, name)
+}
+
+// settingsContext returns the context listing the settings of the
+// buffer of the current editor or of the origin of the current grep
+// results.
+func (v *View) settingsContext() *cnt.Item {
+	it := cnt.Settings("#", '#', func() []Setting {
+		ed := v.origin()
+		if ed == nil {
+			return nil
+		}
+		return v.Settings.Settings(ed.Buffer)
+	}, v.set)
+	it.Help = ContextsHelp + "settings"
+	return it
+}
+
+// set sets the setting with given name to given value in given scope
+// relative to the buffer of the current editor and reports the outcome.
+func (v *View) set(e *lines.Env, name, value, scope string) {
+	ed := v.origin()
+	if ed == nil {
+		v.message(e, "set: no editor")
+		return
+	}
+	if err := v.Settings.Set(ed.Buffer, name, value, scope); err != nil {
+		v.message(e, "set: "+err.Error())
+		return
+	}
+	v.message(e, fmt.Sprintf("set %s=%s in %s", name, value, scope))
+}
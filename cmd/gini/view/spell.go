@@ -0,0 +1,113 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package view
+
+import (
+	"unicode/utf8"
+
+	"github.com/slukits/gini/cmd/gini/view/internal/cnt"
+	"github.com/slukits/gini/cmd/gini/view/internal/edt"
+	"github.com/slukits/lines"
+)
+
+// Misspelling is a misspelled word of a buffer together with the
+// suggested corrections.
+type Misspelling struct {
+	Word        string
+	Ln, Cl      int
+	Suggestions []string
+}
+
+// Speller is implemented by a Buffer whose words are spell-checked.
+// It marks misspelled words by its Styles (see Styler) while the z
+// command of an editor's command mode lists the corrections of the
+// misspelled word under the cursor in the context bar.
+type Speller interface {
+
+	// Misspelled returns the misspelled word at given position and
+	// false if there is none.
+	Misspelled(ln, cl int) (Misspelling, bool)
+
+	// Accept adds given word to the accepted words, e.g. to the word
+	// list of the buffer's project.
+	Accept(word string) error
+}
+
+// spell enters the context listing the suggested corrections of the
+// misspelled word under the cursor of the current editor or reports
+// that there is no such word.
+func (v *View) spell(e *lines.Env) {
+	ii := v.spellingEntries()
+	if len(ii) == 0 {
+		v.message(e, "spelling: no misspelled word under cursor")
+		return
+	}
+	v.Context().(*cnt.Context).Enter(e, &cnt.Item{Label: "spelling",
+		Items: ii, Help: ContextsHelp + "spelling"})
+}
+
+// spellingEntries returns the commands replacing the misspelled word
+// under the cursor of the current editor by a suggested correction and
+// the command accepting the word.
+func (v *View) spellingEntries() (ii []*cnt.Item) {
+	ed := v.origin()
+	if ed == nil {
+		return nil
+	}
+	sp, ok := ed.Buffer.(Speller)
+	if !ok {
+		return nil
+	}
+	m, ok := sp.Misspelled(ed.Cursor())
+	if !ok {
+		return nil
+	}
+	for i, s := range m.Suggestions {
+		if i == len(indexKeys) {
+			break
+		}
+		s := s
+		ii = append(ii, &cnt.Item{Label: s, Key: rune(indexKeys[i]),
+			Exec: func(e *lines.Env) {
+				e.Lines.Update(ed, nil, func(e *lines.Env) {
+					ed.Replace(e, m.Ln, m.Cl,
+						utf8.RuneCountInString(m.Word), s)
+				})
+			}})
+	}
+	return append(ii, &cnt.Item{Label: "accept", Key: '+',
+		Exec: func(e *lines.Env) { v.accept(e, ed, sp, m.Word) }})
+}
+
+// accept has given speller sp accept given word w and updates given
+// editor ed to the accepted word not being marked anymore.
+func (v *View) accept(e *lines.Env, ed *edt.Editor, sp Speller, w string) {
+	if err := sp.Accept(w); err != nil {
+		v.message(e, "accept: "+err.Error())
+		return
+	}
+	v.message(e, "accepted "+w)
+	e.Lines.Update(ed, nil, func(e *lines.Env) {
+		ln, cl := ed.Cursor()
+		ed.Goto(e, ln, cl)
+	})
+}
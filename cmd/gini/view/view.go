@@ -40,18 +40,234 @@ package view
 
 import (
 	"github.com/slukits/gini/cmd/gini/view/internal/cnt"
+	"github.com/slukits/gini/cmd/gini/view/internal/edt"
 	"github.com/slukits/lines"
 )
 
+// Buffer provides the content of an editor and receives its
+// modifications.  A Buffer may additionally implement Historian to
+// support undo/redo and Saver to support saving.
+type Buffer = edt.Buffer
+
+// Historian is implemented by a Buffer with an undo history.
+type Historian = edt.Historian
+
+// Saver is implemented by a Buffer which can be saved.
+type Saver = edt.Saver
+
+// Registers keep the copied and deleted text of the view's editors.
+type Registers = edt.Registers
+
+// Rings are the in-memory Registers of a view which wasn't given
+// Registers.
+type Rings = edt.Rings
+
+// Filed is implemented by a Buffer which is backed by a file whose
+// path and modification state are shown in the context bar.
+type Filed = edt.Filed
+
+// Span is a styled range of a line's runes.
+type Span = edt.Span
+
+// Styler is implemented by a Buffer styling its lines, e.g. a help
+// page highlighting its headings, emphases, keys and links.
+type Styler = edt.Styler
+
+// Linker is implemented by a Buffer linking to help page sections
+// which are shown in the help split on <enter> in the command mode.
+type Linker = edt.Linker
+
+// View stacks the context bar on top of the columns.
 type View struct {
 	lines.Component
 	lines.Stacking
+
+	// Buffer is the content of the initially shown editor.
+	Buffer Buffer
+
+	// NewSplit creates the split of a column or split added from the
+	// columns context; it defaults to an editor of an empty buffer.
+	NewSplit func() lines.Componenter
+
+	// Registers are shared by the view's editors; they default to
+	// registers which aren't persisted.
+	Registers Registers
+
+	editor *edt.Editor
+
+	// Macros keep the recorded keyboard macros; they default to macros
+	// which are neither persisted nor editable.
+	Macros Macros
+
+	// Commands keep the repeatable commands executed by the view's
+	// editors; they default to commands which aren't persisted.
+	Commands Commands
+
+	// Grepper greps and replaces matches; it defaults to a grepper of
+	// the current editor's buffer.
+	Grepper Grepper
+
+	// SavedGreps keep the greps saved under hotkeys; they default to
+	// saved greps which are neither persisted nor editable.
+	SavedGreps SavedGreps
+
+	// Settings provide the settings of the view's buffers; they default
+	// to settings without any setting.
+	Settings Settings
+
+	// Helper provides the help pages shown in the help context; it
+	// defaults to a helper without help pages.
+	Helper Helper
+
+	// FileTypes provide the commands of the file-type context; they
+	// default to file types without commands.
+	FileTypes FileTypes
+
+	// helping is the split showing the help of the help context.
+	helping *edt.Editor
+
+	// results is the split showing the matches of the latest grep.
+	results *Results
+
+	// searches are the previous greps latest first.
+	searches []Search
+
+	// recording is the keyboard macro which is currently recorded.
+	recording *Macro
+
+	// cmds are the contexts of editor commands by their keys.
+	cmds map[rune]*cnt.Item
+
+	// keys are the contexts of editor commands bound to keys.
+	keys map[lines.Key]*cnt.Item
 }
 
 func (v *View) OnInit(e *lines.Env) {
-	v.CC = append(v.CC, &cnt.Context{})
+	v.editor = v.wired(&edt.Editor{Buffer: v.Buffer})
+	v.cmds, v.keys = v.cmdContexts(), v.keyContexts()
+	cc := newColumns(v.editor)
+	if v.Macros == nil {
+		v.Macros = &macros{}
+	}
+	if v.Commands == nil {
+		v.Commands = &commands{}
+	}
+	if v.Grepper == nil {
+		v.Grepper = &bufferGrep{}
+	}
+	if v.SavedGreps == nil {
+		v.SavedGreps = savedGreps{}
+	}
+	if v.Settings == nil {
+		v.Settings = noSettings{}
+	}
+	if v.Helper == nil {
+		v.Helper = noHelp{}
+	}
+	if v.FileTypes == nil {
+		v.FileTypes = noFileTypes{}
+	}
+	v.CC = append(v.CC, &cnt.Context{
+		Root:      v.contexts(),
+		Back:      cc.CurrentSplit,
+		Typed:     v.typed,
+		Help:      v.help,
+		Registers: v.Registers,
+	}, cc)
+}
+
+// OnAfterInit focuses the initially shown editor.
+func (v *View) OnAfterInit(e *lines.Env) {
+	e.Lines.Focus(v.Editor())
+}
+
+// OnRune activates the context bar on a space which wasn't consumed by
+// the focused component while an r starts or stops recording a keyboard
+// macro.  An a or m repeats the latest command or movement at the
+// cursor of the current editor while a z lists the corrections of the
+// misspelled word under the cursor.  The commands t, T, c, C, d, D, P,
+// A, M, g, G, * and e of an editor's command mode enter their context
+// in the context bar, e.g. * followed by a hotkey greps a saved grep.
+func (v *View) OnRune(e *lines.Env, r rune, _ lines.ModifierMask) {
+	switch r {
+	case ' ':
+		v.Context().(*cnt.Context).Activate(e)
+		return
+	case 'r':
+		v.record(e)
+		return
+	case 'a', 'm':
+		v.repeat(e, r == 'm')
+		return
+	case 'z':
+		v.spell(e)
+		return
+	}
+	if it, ok := v.cmds[r]; ok {
+		v.Context().(*cnt.Context).Enter(e, it)
+	}
 }
 
+// OnKey enters the context of a command bound to a key which wasn't
+// consumed by the focused component, e.g. ctrl+g grepping the project.
+func (v *View) OnKey(e *lines.Env, k lines.Key, _ lines.ModifierMask) {
+	if it, ok := v.keys[k]; ok {
+		v.Context().(*cnt.Context).Enter(e, it)
+	}
+}
+
+// Context returns the context bar.
 func (v *View) Context() lines.Componenter {
 	return v.CC[0]
 }
+
+// Columns returns the columns holding the view's splits.
+func (v *View) Columns() *Columns {
+	return v.CC[1].(*Columns)
+}
+
+// Editor returns the initially shown editor.
+func (v *View) Editor() lines.Componenter {
+	return v.editor
+}
+
+func (v *View) newSplit() lines.Componenter {
+	if v.NewSplit == nil {
+		return v.wired(&edt.Editor{})
+	}
+	split := v.NewSplit()
+	if ed, ok := split.(*edt.Editor); ok && ed.Changed == nil {
+		v.wired(ed)
+	}
+	return split
+}
+
+// wired makes given editor ed share the view's registers, report typed
+// input for macro recording, executed commands for repetition, followed
+// links for showing their help and its messages and, if it is the
+// current split, its status to the context bar.
+func (v *View) wired(ed *edt.Editor) *edt.Editor {
+	if v.Registers == nil {
+		v.Registers = &edt.Rings{}
+	}
+	if ed.Registers == nil {
+		ed.Registers = v.Registers
+	}
+	ed.Typed = v.typed
+	ed.Executed = v.executed
+	ed.Follow = v.help
+	ed.Changed = func(e *lines.Env, msg string) {
+		if msg != "" {
+			e.Lines.Update(v.Context(), cnt.Message(msg), nil)
+		}
+		if v.Columns().CurrentSplit() != ed {
+			return
+		}
+		ln, cl := ed.Cursor()
+		file, modified := ed.File()
+		e.Lines.Update(v.Context(), cnt.Status{Ln: ln, Cl: cl,
+			Mode: ed.Mode().String(), File: file, Modified: modified,
+		}, nil)
+	}
+	return ed
+}
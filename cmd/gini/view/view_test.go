@@ -22,9 +22,13 @@ along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
 package view
 
 import (
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/slukits/gini/cmd/gini/view/internal/cnt"
+	"github.com/slukits/gini/cmd/gini/view/internal/edt"
 	. "github.com/slukits/gounit"
 	"github.com/slukits/lines"
 )
@@ -40,6 +44,485 @@ func (s *AView) Has_a_context_component(t *T) {
 	t.True(ok)
 }
 
+func (s *AView) Has_an_editor_showing_given_buffer(t *T) {
+	vw := &View{Buffer: &bufferFX{ll: []string{"gini"}}}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	_, ok := vw.Editor().(*edt.Editor)
+	t.True(ok)
+	t.Contains(fx.ScreenOf(vw.Editor()), "gini")
+}
+
+func (s *AView) Focuses_its_editor(t *T) {
+	vw := &View{Buffer: &bufferFX{ll: []string{"gini"}}}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fx.FireRune('l')
+	ln, cl := vw.Editor().(*edt.Editor).Cursor()
+	t.Eq(0, ln)
+	t.Eq(1, cl)
+}
+
+func (s *AView) Activates_context_bar_on_unbound_space(t *T) {
+	vw := &View{Buffer: &bufferFX{ll: []string{"gini"}}}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fx.FireRune(' ')
+	t.True(vw.Context().(*cnt.Context).Active())
+	fx.FireRune(' ')
+	t.Not.True(vw.Context().(*cnt.Context).Active())
+	fx.FireRune('l')
+	_, cl := vw.Editor().(*edt.Editor).Cursor()
+	t.Eq(1, cl)
+}
+
+func (s *AView) Does_not_activate_context_bar_on_typed_space(t *T) {
+	vw := &View{Buffer: &bufferFX{ll: []string{"gini"}}}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fx.FireRune('i')
+	fx.FireRune(' ')
+	t.Not.True(vw.Context().(*cnt.Context).Active())
+}
+
+func (s *AView) Scrolls_pages_from_lines_context(t *T) {
+	ll := make([]string, 100)
+	for i := range ll {
+		ll[i] = fmt.Sprintf("line %d", i)
+	}
+	vw := &View{Buffer: &bufferFX{ll: ll}}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	height := len(fx.ScreenOf(vw.Editor()))
+	fx.FireRune(' ')
+	fx.FireRune('l')
+	fx.FireRune('d')
+	ln, _ := vw.Editor().(*edt.Editor).Cursor()
+	t.Eq(height, ln)
+	t.Contains(fx.ScreenOf(vw.Editor()), fmt.Sprintf("line %d", height))
+	fx.FireRune('u')
+	ln, _ = vw.Editor().(*edt.Editor).Cursor()
+	t.Eq(0, ln)
+}
+
+func (s *AView) Switches_editor_mode_from_mode_context(t *T) {
+	vw := &View{Buffer: &bufferFX{ll: []string{"gini"}}}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fx.FireRune(' ')
+	fx.FireRune('m')
+	fx.FireRune('o')
+	t.Eq(edt.Overwrite, vw.Editor().(*edt.Editor).Mode())
+	t.Not.True(vw.Context().(*cnt.Context).Active())
+}
+
+func (s *AView) Quits_from_app_context(t *T) {
+	vw := &View{Buffer: &bufferFX{ll: []string{"gini"}}}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fx.Lines.Quitting.DelRune('q')
+	quit := false
+	fx.Lines.OnQuit(func() { quit = true })
+	fx.FireRune(' ')
+	fx.FireRune('.')
+	fx.FireRune('q')
+	t.True(quit)
+}
+
+func (s *AView) Shows_status_and_messages_of_current_editor(t *T) {
+	vw := &View{Buffer: &filedFX{bufferFX: bufferFX{ll: []string{"gini"}}}}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fx.FireRune('l')
+	t.Contains(fx.ScreenOf(vw.Context()),
+		"ln: 0  cl: 1  mod:cmd  hlp/index.gnh")
+	fx.FireRune('s')
+	t.Contains(fx.ScreenOf(vw.Context()), "[saved hlp/index.gnh]")
+	fx.FireRune('i')
+	t.Contains(fx.ScreenOf(vw.Context()), "mod:ins")
+}
+
+func (s *AView) Browses_messages_from_app_context(t *T) {
+	b := &filedFX{bufferFX: bufferFX{ll: []string{"gini"}},
+		err: errors.New("disk full")}
+	vw := &View{Buffer: b}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fx.FireRune('s')
+	b.err = nil
+	fx.FireRune('s')
+	ctx := vw.Context().(*cnt.Context)
+	t.Eq(2, len(ctx.Messages()))
+	fx.FireRune(' ')
+	fx.FireRune('.')
+	fx.FireRune('m')
+	fx.FireRune('o')
+	t.True(ctx.Active())
+	t.Eq(cnt.Message("disk full"), ctx.Message())
+}
+
+func (s *AView) Goes_til_searched_text_from_til_context(t *T) {
+	vw := &View{Buffer: &bufferFX{ll: []string{"gini is 42"}}}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fx.FireRune('t')
+	t.True(vw.Context().(*cnt.Context).Active())
+	fx.FireRune('4')
+	fx.FireRune('2')
+	t.Contains(fx.ScreenOf(vw.Context()), "til: '42'")
+	fx.FireKey(lines.Enter)
+	t.Not.True(vw.Context().(*cnt.Context).Active())
+	_, cl := vw.Editor().(*edt.Editor).Cursor()
+	t.Eq(8, cl)
+}
+
+func (s *AView) Copies_til_motion_target_selected_in_til_context(t *T) {
+	vw := &View{Buffer: &bufferFX{ll: []string{"gini is 42"}}}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fx.FireRune('c')
+	fx.FireRune('j')
+	fx.FireRune('k')
+	fx.FireRune('u')
+	t.Contains(fx.ScreenOf(vw.Context()), "including")
+	fx.FireRune('s')
+	t.Eq("gini ", vw.Registers.Latest())
+	fx.FireRune('C')
+	t.Contains(fx.ScreenOf(vw.Context()), "word beginning")
+	fx.FireKey(lines.Esc)
+	fx.FireKey(lines.Esc)
+	t.Not.True(vw.Context().(*cnt.Context).Active())
+}
+
+func (s *AView) Pastes_ring_entries_selected_in_paste_context(t *T) {
+	vw := &View{Registers: &edt.Rings{}}
+	vw.Registers.Copied("gini")
+	vw.Registers.Copied("is")
+	vw.Registers.Deleted("not\nan ide")
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fx.FireRune('P')
+	fx.FireRune('c')
+	t.Eq("paste>copy ring: 0:is 1:gini",
+		fx.ScreenOf(vw.Context()).Trimmed().String())
+	fx.FireRune('1')
+	t.Contains(fx.ScreenOf(vw.Editor()), "gini")
+	fx.FireRune('P')
+	fx.FireRune('d')
+	t.Contains(fx.ScreenOf(vw.Context()), "0:not⏎an ide")
+}
+
+func (s *AView) Pastes_latest_register_into_input_boxes(t *T) {
+	vw := &View{Buffer: &textFX{ll: []string{"gini is gini"}},
+		Registers: &edt.Rings{}}
+	vw.Registers.Copied("gini")
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fire(fx, "gjkp")
+	t.Contains(fx.ScreenOf(vw.Context()), "gini")
+	fire(fx, "D^")
+	t.Eq("gini", vw.Registers.Deletions()[0])
+}
+
+func (s *AView) Pages_ring_entries_in_paste_context(t *T) {
+	vw := &View{Registers: &edt.Rings{}}
+	for i := 0; i < 12; i++ {
+		vw.Registers.Copied(fmt.Sprintf("c%d", i))
+	}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fx.FireRune('P')
+	fx.FireRune('c')
+	t.Contains(fx.ScreenOf(vw.Context()), "9:c2 >:more")
+	fx.FireRune('>')
+	t.Eq("paste>copy ring>more: 0:c1 1:c0",
+		fx.ScreenOf(vw.Context()).Trimmed().String())
+	fx.FireKey(lines.Esc)
+	t.Contains(fx.ScreenOf(vw.Context()), "0:c11")
+	fx.FireRune('>')
+	fx.FireRune('1')
+	t.Contains(fx.ScreenOf(vw.Editor()), "c0")
+}
+
+func (s *AView) Repeats_latest_command_and_movement_with_a_and_m(t *T) {
+	b := &edt.Rings{}
+	vw := &View{Buffer: &bufferFX{ll: []string{"gini is 42 is 42"}},
+		Registers: b}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	for _, r := range "cis" {
+		fx.FireRune(r)
+	}
+	fx.FireKey(lines.Enter)
+	t.Eq("gini ", b.Latest())
+	for _, r := range "tjks" {
+		fx.FireRune(r)
+	}
+	fx.FireRune('m')
+	_, cl := vw.Editor().(*edt.Editor).Cursor()
+	t.Eq(7, cl)
+	fx.FireRune('a')
+	t.Eq(" 42 ", b.Latest())
+	t.Eq(1, len(vw.Commands.Commands()))
+	t.Eq("go til space", vw.Commands.Movements()[0].String())
+}
+
+func (s *AView) Executes_ring_entries_selected_in_command_contexts(t *T) {
+	vw := &View{Buffer: &bufferFX{ll: []string{"gini is 42"}}}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	for _, r := range "t42" {
+		fx.FireRune(r)
+	}
+	fx.FireKey(lines.Enter)
+	for _, r := range "Tjkg" {
+		fx.FireRune(r)
+	}
+	fx.FireRune('M')
+	t.Eq("movements: 0:go back til line beginning 1:go til '42'",
+		fx.ScreenOf(vw.Context()).Trimmed().String())
+	fx.FireRune('1')
+	_, cl := vw.Editor().(*edt.Editor).Cursor()
+	t.Eq(8, cl)
+	fx.FireRune('A')
+	t.Eq("commands:", fx.ScreenOf(vw.Context()).Trimmed().String())
+}
+
+func (s *AView) Sets_settings_of_current_buffer_from_settings_context(
+	t *T,
+) {
+	ss := &settingsFX{values: map[string]string{}}
+	vw := &View{Buffer: &bufferFX{ll: []string{"gini"}}, Settings: ss}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fx.FireRune(' ')
+	fx.FireRune('#')
+	t.Contains(fx.ScreenOf(vw.Context()), "0:backups=3(default)")
+	for _, r := range "0g5" {
+		fx.FireRune(r)
+	}
+	fx.FireKey(lines.Enter)
+	ctx := vw.Context().(*cnt.Context)
+	t.Eq(cnt.Message("set backups=5 in global"), ctx.Message())
+	fx.FireRune(' ')
+	fx.FireRune('#')
+	t.Contains(fx.ScreenOf(vw.Context()), "0:backups=5(global)")
+	for _, r := range "0g0" {
+		fx.FireRune(r)
+	}
+	fx.FireKey(lines.Enter)
+	t.Eq(cnt.Message("set: backups must be positive"), ctx.Message())
+}
+
+func (s *AView) Shows_help_of_selected_entries_in_help_context(t *T) {
+	ll := make([]string, 100)
+	for i := range ll {
+		ll[i] = fmt.Sprintf("line %d", i)
+	}
+	ll[60] = "## lines"
+	vw := &View{Buffer: &bufferFX{ll: []string{"gini"}},
+		Helper: &helperFX{bufferFX{ll: ll}}}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	ctx := vw.Context().(*cnt.Context)
+	for _, r := range " .h l" {
+		fx.FireRune(r)
+	}
+	t.True(ctx.Helping())
+	t.True(ctx.Active())
+	t.Eq(2, vw.Columns().Column(0).Len())
+	help := vw.Columns().Column(0).Split(1)
+	t.True(strings.HasPrefix(fx.ScreenOf(help).String(), "## lines"))
+	fx.FireRune('d')
+	t.Not.True(ctx.Active())
+	t.Eq(help, vw.Columns().CurrentSplit())
+	ln, _ := vw.Editor().(*edt.Editor).Cursor()
+	t.Eq(0, ln)
+	fx.FireRune(' ')
+	fx.FireRune('c')
+	t.Eq(cnt.Message("help: no section: columns"), ctx.Message())
+	fx.FireKey(lines.Esc)
+	fx.FireRune('?')
+	t.Not.True(ctx.Helping())
+}
+
+func (s *AView) Scrolls_to_selected_heading_of_indexed_buffer(t *T) {
+	b := &pageFX{bufferFX{ll: make([]string, 100)}}
+	b.ll[60] = "## lines"
+	vw := &View{Buffer: b}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	for _, r := range " li" {
+		fx.FireRune(r)
+	}
+	t.Contains(fx.ScreenOf(vw.Context()), "lines")
+	fx.FireRune('0')
+	t.True(strings.HasPrefix(
+		fx.ScreenOf(vw.Editor()).String(), "## lines"))
+}
+
+func (s *AView) Shows_help_section_of_followed_link(t *T) {
+	b := &pageFX{bufferFX{ll: []string{"see [lines](contexts.gnh#lines)"}}}
+	vw := &View{Buffer: b,
+		Helper: &helperFX{bufferFX{ll: []string{"", "## lines"}}}}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	for _, r := range "llll" {
+		fx.FireRune(r)
+	}
+	fx.FireKey(lines.Enter)
+	t.Eq(2, vw.Columns().Column(0).Len())
+	help := vw.Columns().Column(0).Split(1)
+	t.Eq(help, vw.Columns().CurrentSplit())
+	t.True(strings.HasPrefix(fx.ScreenOf(help).String(), "## lines"))
+}
+
+func (s *AView) Lists_file_type_commands_of_current_buffer(t *T) {
+	b := &bufferFX{ll: []string{"gini"}}
+	ft := &fileTypesFX{}
+	vw := &View{Buffer: b, FileTypes: ft}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fx.FireRune(' ')
+	fx.FireRune('*')
+	t.Contains(fx.ScreenOf(vw.Context()), "format")
+	fx.FireRune('f')
+	t.True(ft.formatted == b)
+	ctx := vw.Context().(*cnt.Context)
+	t.Eq(cnt.Message("format: done"), ctx.Message())
+	ft.err = errors.New("failed")
+	fx.FireRune(' ')
+	fx.FireRune('*')
+	fx.FireRune('f')
+	t.Eq(cnt.Message("format: failed"), ctx.Message())
+}
+
+func (s *AView) Lists_corrections_of_misspelled_word_under_cursor(t *T) {
+	b := &spellerFX{bufferFX{ll: []string{"gini is a ied"}}, nil}
+	vw := &View{Buffer: b}
+	fx := lines.TermFixture(t.GoT(), 0, vw)
+	fx.FireRune('z')
+	t.Eq(cnt.Message("spelling: no misspelled word under cursor"),
+		vw.Context().(*cnt.Context).Message())
+	for i := 0; i < 10; i++ {
+		fx.FireRune('l')
+	}
+	fx.FireRune('z')
+	t.Contains(fx.ScreenOf(vw.Context()), "ide")
+	t.Contains(fx.ScreenOf(vw.Context()), "accept")
+	fx.FireRune('0')
+	t.Eq("gini is a ide", b.ll[0])
+	b.ll[0] = "gini is a ied"
+	fx.FireRune('z')
+	fx.FireRune('+')
+	t.Eq([]string{"ied"}, b.accepted)
+	t.Eq(cnt.Message("accepted ied"),
+		vw.Context().(*cnt.Context).Message())
+}
+
+// bufferFX is a read only Buffer implementation.
+type bufferFX struct{ ll []string }
+
+func (b *bufferFX) Lines() int                         { return len(b.ll) }
+func (b *bufferFX) Line(idx int) string                { return b.ll[idx] }
+func (b *bufferFX) Insert(_, _ int, _ string) error    { return nil }
+func (b *bufferFX) Delete(_, _, _ int) (string, error) { return "", nil }
+
+// filedFX is a Filed bufferFX failing to save if err is set.
+type filedFX struct {
+	bufferFX
+	err error
+}
+
+func (b *filedFX) File() string   { return "hlp/index.gnh" }
+func (b *filedFX) Modified() bool { return false }
+
+func (b *filedFX) Save() error { return b.err }
+
+// settingsFX provides the setting "backups" which defaults to 3 and
+// must be positive; values are kept by their scope.
+type settingsFX struct{ values map[string]string }
+
+func (s *settingsFX) Settings(Buffer) []Setting {
+	for _, scope := range SettingsScopes {
+		if v, ok := s.values[scope]; ok {
+			return []Setting{{Name: "backups", Value: v, Origin: scope}}
+		}
+	}
+	return []Setting{{Name: "backups", Value: "3", Origin: "default"}}
+}
+
+func (s *settingsFX) Set(_ Buffer, name, value, scope string) error {
+	if value == "0" {
+		return errors.New(name + " must be positive")
+	}
+	s.values[scope] = value
+	return nil
+}
+
+// pageFX is an Indexed and linking bufferFX whose headings start with
+// "## " and whose links are targeted by the text between "(" and ")".
+type pageFX struct{ bufferFX }
+
+func (b *pageFX) Index() (hh []Heading) {
+	for i, l := range b.ll {
+		if strings.HasPrefix(l, "## ") {
+			hh = append(hh, Heading{Title: l[3:], Level: 2, Ln: i})
+		}
+	}
+	return hh
+}
+
+func (b *pageFX) Link(ln, cl int) (string, bool) {
+	l := b.ll[ln]
+	start, end := strings.Index(l, "["), strings.Index(l, ")")
+	if start < 0 || end < 0 || cl < start || cl > end {
+		return "", false
+	}
+	return l[strings.Index(l, "(")+1 : end], true
+}
+
+// spellerFX is a modifiable bufferFX of one line whose word "ied" is
+// misspelled with the suggested correction "ide".
+type spellerFX struct {
+	bufferFX
+	accepted []string
+}
+
+func (b *spellerFX) Insert(ln, cl int, s string) error {
+	b.ll[ln] = b.ll[ln][:cl] + s + b.ll[ln][cl:]
+	return nil
+}
+
+func (b *spellerFX) Delete(ln, cl, n int) (string, error) {
+	deleted := b.ll[ln][cl : cl+n]
+	b.ll[ln] = b.ll[ln][:cl] + b.ll[ln][cl+n:]
+	return deleted, nil
+}
+
+func (b *spellerFX) Misspelled(ln, cl int) (Misspelling, bool) {
+	l := b.ll[ln]
+	start := strings.LastIndex(l[:cl], " ") + 1
+	end := start + strings.Index(l[start:]+" ", " ")
+	if l[start:end] != "ied" {
+		return Misspelling{}, false
+	}
+	return Misspelling{Word: l[start:end], Ln: ln, Cl: start,
+		Suggestions: []string{"ide"}}, true
+}
+
+func (b *spellerFX) Accept(w string) error {
+	b.accepted = append(b.accepted, w)
+	return nil
+}
+
+// fileTypesFX provides the command "format" remembering the buffer it
+// was executed for and failing if err is set.
+type fileTypesFX struct {
+	formatted Buffer
+	err       error
+}
+
+func (ft *fileTypesFX) Commands(b Buffer) []FileTypeCmd {
+	return []FileTypeCmd{{Label: "format", Key: 'f',
+		Exec: func() (string, error) {
+			ft.formatted = b
+			return "done", ft.err
+		}}}
+}
+
+// helperFX provides the sections of its help page by their headings.
+type helperFX struct{ page bufferFX }
+
+func (h *helperFX) Help(anchor string) (Buffer, int, error) {
+	section := strings.TrimPrefix(anchor, ContextsHelp)
+	for i, l := range h.page.ll {
+		if l == "## "+section {
+			return &h.page, i, nil
+		}
+	}
+	return nil, 0, errors.New("no section: " + section)
+}
+
 func TestAView(t *testing.T) {
 	t.Parallel()
 	Run(&AView{}, t)
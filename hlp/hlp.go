@@ -0,0 +1,213 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+/*
+Package hlp provides GINI's help pages.  If GINI is executed inside its
+git repository the help pages of the repository's working tree are
+used, i.e. their modifications are stored in the repository.  Otherwise
+the help pages embedded into the binary are provided until a help page
+is edited for the first time:  then a copy of the page is stored in the
+environment's cache directory where its modifications persist.
+*/
+package hlp
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/slukits/gini/pkg/dir"
+	"github.com/slukits/gini/pkg/env"
+	"github.com/slukits/gini/pkg/lg"
+)
+
+//go:embed *.gnh
+var embedded embed.FS
+
+const (
+
+	// Index is the name of the introductory help page.
+	Index = "index.gnh"
+
+	// Dir is the name of the directory holding the help pages in
+	// GINI's repository and in the cache directory.
+	Dir = "hlp"
+
+	// Repo identifies GINI's git repository by its remote url.
+	Repo = "github.com/slukits/gini"
+)
+
+// Pages resolves help pages to their content and the files their
+// modifications are stored to.  The zero value is ready to use.
+type Pages struct {
+
+	// Log is a logger for reporting errors it defaults to the
+	// zero-logger.  Its environment provides the working directory
+	// to determine if GINI runs in its repository and the cache
+	// directory for edited help pages.
+	Log *lg.Logger
+
+	// Lib provides the std-lib functions Pages needs to provide its
+	// features
+	Lib Lib
+
+	repo    *dir.Dir
+	initLib bool
+}
+
+func (p *Pages) lg() *lg.Logger {
+	if p.Log == nil {
+		p.Log = &lg.Logger{}
+	}
+	return p.Log
+}
+
+func (p *Pages) env() *env.Env {
+	if p.lg().Env == nil {
+		p.Log.Env = &env.Env{}
+	}
+	return p.Log.Env
+}
+
+func (p *Pages) lib() Lib {
+	if !p.initLib {
+		p.initLib = true
+		if p.Lib.ReadFile == nil {
+			p.Lib.ReadFile = os.ReadFile
+		}
+		if p.Lib.WriteFile == nil {
+			p.Lib.WriteFile = os.WriteFile
+		}
+		if p.Lib.MkdirAll == nil {
+			p.Lib.MkdirAll = os.MkdirAll
+		}
+	}
+	return p.Lib
+}
+
+// Repo returns GINI's repository directory and true if the working
+// directory of given pages p's environment is inside it.
+func (p *Pages) Repo() (*dir.Dir, bool) {
+	if p.repo != nil {
+		return p.repo, true
+	}
+	repo, ok := (&dir.Dir{Log: p.lg()}).Repo()
+	if !ok || !repo.FileContains(".git/config", []byte(Repo)) {
+		return nil, false
+	}
+	p.repo = repo
+	return repo, true
+}
+
+// Path returns the path of the file modifications of the help page
+// with given name are stored to:  inside GINI's repository that is the
+// page's file in the working tree otherwise its copy in the cache
+// directory.  Note outside the repository the returned file doesn't
+// exist until the page was edited (see Pages.Edit).
+func (p *Pages) Path(name string) string {
+	if repo, ok := p.Repo(); ok {
+		return filepath.Join(repo.String(), Dir, name)
+	}
+	return filepath.Join(p.env().Cache(), Dir, name)
+}
+
+// Load returns the content of the help page with given name which is
+// read from the page's file (see Pages.Path) or taken from the embedded
+// help pages if this file doesn't exist outside GINI's repository.
+func (p *Pages) Load(name string) ([]byte, error) {
+	bb, err := p.lib().ReadFile(p.Path(name))
+	if err == nil {
+		return bb, nil
+	}
+	if _, ok := p.Repo(); ok || !os.IsNotExist(err) {
+		return nil, fmt.Errorf("gini: hlp: load: %w", err)
+	}
+	bb, err = fs.ReadFile(embedded, name)
+	if err != nil {
+		return nil, fmt.Errorf("gini: hlp: load: %w", err)
+	}
+	return bb, nil
+}
+
+// Edit is called before the help page with given name is modified for
+// the first time.  Outside GINI's repository it copies the embedded
+// page into the cache directory unless a copy exists.  A copy which
+// can't be read fails the edit rather than being overwritten.
+func (p *Pages) Edit(name string) error {
+	if _, ok := p.Repo(); ok {
+		return nil
+	}
+	path := p.Path(name)
+	if _, err := p.lib().ReadFile(path); !os.IsNotExist(err) {
+		if err != nil {
+			return fmt.Errorf("gini: hlp: edit: %w", err)
+		}
+		return nil
+	}
+	bb, err := fs.ReadFile(embedded, name)
+	if err != nil {
+		return fmt.Errorf("gini: hlp: edit: %w", err)
+	}
+	if err := p.lib().MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("gini: hlp: edit: %w", err)
+	}
+	if err := p.lib().WriteFile(path, bb, 0600); err != nil {
+		return fmt.Errorf("gini: hlp: edit: %w", err)
+	}
+	return nil
+}
+
+// Anchor splits given anchor of a help page's section into the page's
+// name and the section's title, e.g. "contexts.gnh#lines" into
+// "contexts.gnh" and "lines".  The page defaults to Index.
+func Anchor(anchor string) (page, section string) {
+	page, section, _ = strings.Cut(anchor, "#")
+	if page == "" {
+		page = Index
+	}
+	return page, section
+}
+
+// Heading returns the title of given line of a help page if it is a
+// heading, i.e. it starts with one or more # followed by a space.
+func Heading(line string) (title string, ok bool) {
+	title = strings.TrimLeft(line, "#")
+	if len(title) == len(line) || !strings.HasPrefix(title, " ") {
+		return "", false
+	}
+	return strings.TrimSpace(title), true
+}
+
+// Lib provides std-lib functions which may fail.
+type Lib struct {
+
+	// ReadFile defaults to os.ReadFile and its semantics
+	ReadFile func(name string) ([]byte, error)
+
+	// WriteFile defaults to os.WriteFile and its semantics
+	WriteFile func(name string, data []byte, perm fs.FileMode) error
+
+	// MkdirAll defaults to os.MkdirAll and its semantics
+	MkdirAll func(path string, perm fs.FileMode) error
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package hlp
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/slukits/gini/pkg/env"
+	"github.com/slukits/gini/pkg/lg"
+	. "github.com/slukits/gounit"
+)
+
+type _Pages struct{ Suite }
+
+func (s *_Pages) SetUp(t *T) { t.Parallel() }
+
+// pagesFX returns help pages of a temporary environment whose working
+// directory is its home directory.
+func pagesFX(t *T) *Pages {
+	lgg := &lg.Logger{Env: (&env.Env{}).SetHome(t.FS().Tmp().Path())}
+	lgg.Env.Lib.Chdir = func(path string) error { return nil }
+	t.FatalOn(lgg.Env.ChWD(lgg.Env.Home()))
+	return &Pages{Log: lgg}
+}
+
+// repoFX turns the working directory of given pages into a fake GINI
+// repository with given index page content.
+func repoFX(t *T, p *Pages, index string) {
+	wd := p.Log.Env.WD()
+	t.FatalOn(os.MkdirAll(filepath.Join(wd, ".git"), 0700))
+	t.FatalOn(os.WriteFile(filepath.Join(wd, ".git", "config"),
+		[]byte("url = https://"+Repo+".git"), 0600))
+	t.FatalOn(os.MkdirAll(filepath.Join(wd, Dir), 0700))
+	t.FatalOn(os.WriteFile(
+		filepath.Join(wd, Dir, Index), []byte(index), 0600))
+}
+
+func (s *_Pages) Are_not_in_repo_outside_gini_s_repository(t *T) {
+	_, ok := pagesFX(t).Repo()
+	t.Not.True(ok)
+}
+
+func (s *_Pages) Provide_embedded_page_outside_repository(t *T) {
+	bb, err := pagesFX(t).Load(Index)
+	t.FatalOn(err)
+	t.Contains(string(bb), "GINI Is Not an IDE")
+}
+
+func (s *_Pages) Fail_to_load_unknown_page(t *T) {
+	_, err := pagesFX(t).Load("unknown.gnh")
+	t.ErrIs(err, fs.ErrNotExist)
+}
+
+func (s *_Pages) Store_pages_in_cache_outside_repository(t *T) {
+	p := pagesFX(t)
+	t.True(strings.HasPrefix(p.Path(Index), p.Log.Env.Cache()))
+}
+
+func (s *_Pages) Copy_embedded_page_to_cache_on_edit(t *T) {
+	p := pagesFX(t)
+	_, err := os.Stat(p.Path(Index))
+	t.True(os.IsNotExist(err))
+	t.FatalOn(p.Edit(Index))
+	bb, err := os.ReadFile(p.Path(Index))
+	t.FatalOn(err)
+	t.Contains(string(bb), "GINI Is Not an IDE")
+}
+
+func (s *_Pages) Provide_cached_copy_once_edited(t *T) {
+	p := pagesFX(t)
+	t.FatalOn(p.Edit(Index))
+	t.FatalOn(os.WriteFile(p.Path(Index), []byte("edited"), 0600))
+	t.FatalOn(p.Edit(Index))
+	bb, err := p.Load(Index)
+	t.FatalOn(err)
+	t.Eq("edited", string(bb))
+}
+
+func (s *_Pages) Fail_edit_if_cache_cant_be_created(t *T) {
+	p := pagesFX(t)
+	p.Lib.MkdirAll = func(string, fs.FileMode) error {
+		return errors.New("mkdir mock error")
+	}
+	t.ErrMatched(p.Edit(Index), "mkdir mock error")
+}
+
+func (s *_Pages) Keep_a_cached_copy_which_cant_be_read(t *T) {
+	p := pagesFX(t)
+	t.FatalOn(p.Edit(Index))
+	t.FatalOn(os.WriteFile(p.Path(Index), []byte("edited"), 0600))
+	p.Lib.ReadFile = func(string) ([]byte, error) {
+		return nil, errors.New("read mock error")
+	}
+	t.ErrMatched(p.Edit(Index), "read mock error")
+	bb, err := os.ReadFile(p.Path(Index))
+	t.FatalOn(err)
+	t.Eq("edited", string(bb))
+}
+
+func (s *_Pages) Are_in_repo_inside_gini_s_repository(t *T) {
+	p := pagesFX(t)
+	repoFX(t, p, "working tree")
+	repo, ok := p.Repo()
+	t.True(ok)
+	t.Eq(p.Log.Env.WD(), repo.String())
+}
+
+func (s *_Pages) Provide_working_tree_pages_inside_repository(t *T) {
+	p := pagesFX(t)
+	repoFX(t, p, "working tree")
+	t.Eq(filepath.Join(p.Log.Env.WD(), Dir, Index), p.Path(Index))
+	bb, err := p.Load(Index)
+	t.FatalOn(err)
+	t.Eq("working tree", string(bb))
+	t.FatalOn(p.Edit(Index))
+	_, err = os.Stat(filepath.Join(p.Log.Env.Cache(), Dir, Index))
+	t.True(os.IsNotExist(err))
+}
+
+func (s *_Pages) Split_anchors_into_page_and_section(t *T) {
+	page, section := Anchor("contexts.gnh#lines")
+	t.Eq("contexts.gnh", page)
+	t.Eq("lines", section)
+	page, section = Anchor("#help")
+	t.Eq(Index, page)
+	t.Eq("help", section)
+}
+
+func (s *_Pages) Provide_a_section_for_each_heading(t *T) {
+	title, ok := Heading("## copy and delete ")
+	t.True(ok)
+	t.Eq("copy and delete", title)
+	_, ok = Heading("#no heading")
+	t.Not.True(ok)
+	_, ok = Heading("no heading")
+	t.Not.True(ok)
+	bb, err := pagesFX(t).Load("contexts.gnh")
+	t.FatalOn(err)
+	n := 0
+	for _, l := range strings.Split(string(bb), "\n") {
+		if _, ok := Heading(l); ok {
+			n++
+		}
+	}
+	t.True(n > 10)
+}
+
+func TestPages(t *testing.T) {
+	t.Parallel()
+	Run(&_Pages{}, t)
+}
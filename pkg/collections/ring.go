@@ -29,70 +29,142 @@ package ring
 // capacity is reached.  Stored elements are provide last in first out.
 // The capacity may be adapted by the client and a Ring's zero value is
 // ready to use.
-type Ring struct {
+type Ring[T any] struct {
 
 	// Capacity represents the number of elements a ring can hold.  Is
 	// the capacity not positive it defaults to 20.
 	Capacity int
 
-	data        []interface{}
-	nextElement int
+	// data holds the elements in a circular buffer whose next element
+	// is stored at index next while n is the number of elements.
+	data []T
+	next int
+	n    int
 }
 
 // Len returns the capacity of a ring which defaults to 20 elements.
-func (r *Ring) Len() int {
+func (r *Ring[T]) Len() int {
 	r.ensureCapacityConsistency()
 	return r.Capacity
 }
 
-func (r *Ring) ensureCapacityConsistency() {
+// Count returns the number of elements stored in given ring r.
+func (r *Ring[T]) Count() int {
+	r.ensureCapacityConsistency()
+	return r.n
+}
+
+func (r *Ring[T]) ensureCapacityConsistency() {
 	if r.Capacity > 0 && r.Capacity == len(r.data) {
 		return
 	}
 	if r.Capacity <= 0 {
 		r.Capacity = 20
-		r.data = make([]interface{}, 20)
-		return
 	}
-	if r.Capacity < len(r.data) {
-		r.data = r.data[len(r.data)-r.Capacity:]
-		if r.nextElement > r.Capacity {
-			r.nextElement = r.Capacity
-		}
-		return
+	r.reset(r.elements())
+}
+
+// elements returns the elements of given ring r latest first.
+func (r *Ring[T]) elements() []T {
+	ee := make([]T, 0, r.n)
+	for i := 0; i < r.n; i++ {
+		ee = append(ee, r.data[r.index(i)])
 	}
-	r.data = append(
-		r.data,
-		make([]interface{}, r.Capacity-len(r.data))...,
-	)
+	return ee
+}
+
+// reset stores given elements ee which are provided latest first in a
+// circular buffer of given ring r's capacity dropping the oldest
+// elements which exceed the capacity.
+func (r *Ring[T]) reset(ee []T) {
+	if len(ee) > r.Capacity {
+		ee = ee[:r.Capacity]
+	}
+	r.data, r.n = make([]T, r.Capacity), len(ee)
+	for i, e := range ee {
+		r.data[len(ee)-1-i] = e
+	}
+	r.next = r.n % r.Capacity
+}
+
+// index returns the data index of the i-th latest element.
+func (r *Ring[T]) index(i int) int {
+	return ((r.next-1-i)%len(r.data) + len(r.data)) % len(r.data)
 }
 
 // Add adds given element e (and elements ee) to given ring r whereas
 // the oldest element is removed to make space for a new element if r
 // holds capacity many elements.
-func (r *Ring) Add(e interface{}, ee ...interface{}) {
+func (r *Ring[T]) Add(e T, ee ...T) {
+	r.ensureCapacityConsistency()
 	r.add(e)
 	for _, e := range ee {
 		r.add(e)
 	}
 }
 
-func (r *Ring) add(e interface{}) {
-	if r.nextElement < r.Len() {
-		r.data[r.nextElement] = e
-		r.nextElement++
+func (r *Ring[T]) add(e T) {
+	r.data[r.next] = e
+	r.next = (r.next + 1) % len(r.data)
+	if r.n < len(r.data) {
+		r.n++
+	}
+}
+
+// Latest returns the latest element of given ring r and false if r is
+// empty.
+func (r *Ring[T]) Latest() (T, bool) { return r.At(0) }
+
+// At returns the i-th latest element of given ring r, i.e. At(0) is
+// the latest element, and false if there is no such element.
+func (r *Ring[T]) At(i int) (T, bool) {
+	r.ensureCapacityConsistency()
+	if i < 0 || i >= r.n {
+		var zero T
+		return zero, false
+	}
+	return r.data[r.index(i)], true
+}
+
+// Remove removes the i-th latest element of given ring r and returns
+// it along with false if there is no such element.
+func (r *Ring[T]) Remove(i int) (T, bool) {
+	e, ok := r.At(i)
+	if !ok {
+		return e, false
+	}
+	for j := i; j > 0; j-- {
+		r.data[r.index(j)] = r.data[r.index(j-1)]
+	}
+	r.next = r.index(0)
+	var zero T
+	r.data[r.next] = zero
+	r.n--
+	return e, true
+}
+
+// Rotate makes the i-th latest element of given ring r its latest
+// element while the elements which were later become the oldest.  A
+// negative i rotates in the opposite direction, e.g. Rotate(-1) makes
+// the oldest element the latest.
+func (r *Ring[T]) Rotate(i int) {
+	r.ensureCapacityConsistency()
+	if r.n == 0 {
 		return
 	}
-	copy(r.data, r.data[1:])
-	r.data[r.nextElement-1] = e
+	i = (i%r.n + r.n) % r.n
+	ee := r.elements()
+	r.reset(append(append(make([]T, 0, r.n), ee[i:]...), ee[:i]...))
 }
 
 // For provides to given receiver rcv stored elements of given ring
 // r last in first out until r runs out of elements or the receiver
 // stops the callback by returning true.
-func (r *Ring) For(rcv func(int, interface{}) (stop bool)) {
+func (r *Ring[T]) For(rcv func(int, T) (stop bool)) {
 	r.ensureCapacityConsistency()
-	for i := r.nextElement - 1; i >= 0; i-- {
-		rcv(r.nextElement-1-i, r.data[i])
+	for i := 0; i < r.n; i++ {
+		if rcv(i, r.data[r.index(i)]) {
+			return
+		}
 	}
 }
@@ -39,11 +39,11 @@ type ARing struct{ Suite }
 func (s *ARing) SetUp(t *T) { t.Parallel() }
 
 func (s *ARing) Capacity_defaults_to_twenty_elements(t *T) {
-	t.Eq(20, (&Ring{}).Len())
+	t.Eq(20, (&Ring[interface{}]{}).Len())
 }
 
 func (s *ARing) Provides_no_element_if_empty(t *T) {
-	r := &Ring{}
+	r := &Ring[interface{}]{}
 	providedElementsCount := 0
 	r.For(func(i int, e interface{}) (stop bool) {
 		providedElementsCount++
@@ -69,7 +69,7 @@ func elementsFX(n int) (ee []interface{}) {
 }
 
 func (s *ARing) Below_its_capacity_has_added_elements(t *T) {
-	r := &Ring{}
+	r := &Ring[interface{}]{}
 	n, got := rand.Intn(r.Len()-2), map[interface{}]bool{}
 	exp := elementsFX(n + 1) // at least one elements
 
@@ -85,7 +85,7 @@ func (s *ARing) Below_its_capacity_has_added_elements(t *T) {
 }
 
 func (s *ARing) Provides_elements_last_in_first_out(t *T) {
-	r := &Ring{}
+	r := &Ring[interface{}]{}
 	ee := elementsFX(r.Len())
 	r.Add(ee[0], ee[1:]...)
 	providedElementsCount := 0
@@ -101,7 +101,7 @@ func (s *ARing) Provides_elements_last_in_first_out(t *T) {
 func (s *ARing) Provides_latest_capacity_many_elements_on_overflow(
 	t *T,
 ) {
-	r := &Ring{}
+	r := &Ring[interface{}]{}
 	n, got := rand.Intn(r.Len())+r.Len()+1, make([]interface{}, r.Len())
 	exp := elementsFX(n)
 	r.Add(exp[0], exp[1:]...)
@@ -118,7 +118,7 @@ func (s *ARing) Provides_latest_capacity_many_elements_on_overflow(
 }
 
 func (s *ARing) Reduces_its_elements_on_capacity_reduction(t *T) {
-	r := &Ring{}
+	r := &Ring[interface{}]{}
 	smallerCapacity, ee := rand.Intn(r.Len()-2)+1, elementsFX(r.Len())
 	r.Add(ee[0], ee[1:]...)
 	r.Capacity = smallerCapacity
@@ -135,7 +135,7 @@ func (s *ARing) Reduces_its_elements_on_capacity_reduction(t *T) {
 }
 
 func (s *ARing) Increases_its_elements_on_capacity_increase(t *T) {
-	r, ee := &Ring{}, elementsFX(25)
+	r, ee := &Ring[interface{}]{}, elementsFX(25)
 	r.Add(ee[0], ee[1:20]...)
 	r.Capacity = len(ee)
 	r.Add(ee[20], ee[21:25]...)
@@ -151,6 +151,73 @@ func (s *ARing) Increases_its_elements_on_capacity_increase(t *T) {
 	}
 }
 
+func (s *ARing) Stops_iteration_if_requested(t *T) {
+	r := &Ring[string]{}
+	r.Add("1st", "2nd", "3rd")
+	got := []string{}
+	r.For(func(i int, e string) (stop bool) {
+		got = append(got, e)
+		return i == 1
+	})
+	t.Eq([]string{"3rd", "2nd"}, got)
+}
+
+func (s *ARing) Provides_its_elements_by_index_latest_first(t *T) {
+	r := &Ring[string]{Capacity: 3}
+	_, ok := r.Latest()
+	t.Not.True(ok)
+	r.Add("1st", "2nd", "3rd", "4th")
+	e, ok := r.Latest()
+	t.True(ok)
+	t.Eq("4th", e)
+	e, _ = r.At(2)
+	t.Eq("2nd", e)
+	_, ok = r.At(3)
+	t.Not.True(ok)
+	t.Eq(3, r.Count())
+}
+
+func elements(r *Ring[string]) (ee []string) {
+	r.For(func(_ int, e string) (stop bool) {
+		ee = append(ee, e)
+		return false
+	})
+	return ee
+}
+
+func (s *ARing) Removes_element_at_given_index(t *T) {
+	r := &Ring[string]{Capacity: 3}
+	r.Add("1st", "2nd", "3rd", "4th")
+	e, ok := r.Remove(1)
+	t.True(ok)
+	t.Eq("3rd", e)
+	t.Eq([]string{"4th", "2nd"}, elements(r))
+	r.Add("5th")
+	t.Eq([]string{"5th", "4th", "2nd"}, elements(r))
+	r.Add("6th")
+	t.Eq([]string{"6th", "5th", "4th"}, elements(r))
+	_, ok = r.Remove(3)
+	t.Not.True(ok)
+}
+
+func (s *ARing) Rotates_its_elements(t *T) {
+	r := &Ring[string]{}
+	r.Add("1st", "2nd", "3rd")
+	r.Rotate(1)
+	t.Eq([]string{"2nd", "1st", "3rd"}, elements(r))
+	r.Rotate(-1)
+	t.Eq([]string{"3rd", "2nd", "1st"}, elements(r))
+	r.Add("4th")
+	t.Eq([]string{"4th", "3rd", "2nd", "1st"}, elements(r))
+}
+
+func BenchmarkRing_Add(b *testing.B) {
+	r := &Ring[int]{Capacity: 1000}
+	for i := 0; i < b.N; i++ {
+		r.Add(i)
+	}
+}
+
 func TestARing(t *testing.T) {
 	t.Parallel()
 	Run(&ARing{}, t)
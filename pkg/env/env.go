@@ -54,6 +54,7 @@ type Env struct {
 	wd      string
 	conf    string
 	logging string
+	cache   string
 	mutex   *sync.Mutex
 	initLib bool
 }
@@ -214,6 +215,20 @@ func (e *Env) Logging() string {
 	return e.logging
 }
 
+// Cache returns the cache directory of given environment e which holds
+// data GINI derives from the user's files like backups.
+func (e *Env) Cache() string {
+	e.lock()
+	defer e.mutex.Unlock()
+	if e.cache == "" {
+		e.mutex.Unlock()
+		cache := filepath.Join(e.Conf(), "cache")
+		e.lock()
+		e.cache = cache
+	}
+	return e.cache
+}
+
 // MkLogging creates the logging directory and errors if MkdirAll errors.
 func (e *Env) MkLogging() error {
 	return e.lib().MkdirAll(e.Logging(), 0700)
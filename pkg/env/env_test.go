@@ -182,6 +182,11 @@ func (e *env) Logging_dir_is_in_config_dir(t *T) {
 	t.True(strings.HasPrefix(env.Logging(), env.Conf()))
 }
 
+func (e *env) Cache_dir_is_in_config_dir(t *T) {
+	env := (&Env{}).SetHome(t.FS().Tmp().Path())
+	t.True(strings.HasPrefix(env.Cache(), env.Conf()))
+}
+
 func (e *env) Creates_logging_directory(t *T) {
 	env := (&Env{}).SetHome(t.FS().Tmp().Path())
 	t.FatalIfNot(t.True(strings.HasPrefix(env.Logging(), env.Home())))
@@ -0,0 +1,384 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+/*
+Package file provides loading and saving of files whereas saving a file
+backs up the overwritten content.  Backups are numbered and kept in the
+cache directory of a file's environment (see env.Env.Cache) so they
+don't clutter the user's directories.
+*/
+package file
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/slukits/gini/pkg/env"
+	"github.com/slukits/gini/pkg/lg"
+)
+
+const (
+
+	// DefaultBackups is the number of backups kept of a file if not
+	// set otherwise.
+	DefaultBackups = 100
+
+	// MaxBackups is the maximal number of backups kept of a file.
+	MaxBackups = 1000
+
+	// DefaultMaxSize is the size in bytes from which on a file is not
+	// backed up if not set otherwise.
+	DefaultMaxSize = 1 << 20
+
+	// BackupsDir is the directory inside an environment's cache
+	// directory holding the backups.
+	BackupsDir = "backups"
+
+	// PathFile is the file in a file's backup directory holding the
+	// path of the backed up file.
+	PathFile = "path"
+)
+
+// File loads and saves the file at its Path.  Saving a file writes its
+// new content to a temporary file which replaces the file, i.e. a file
+// is either completely written or not at all.  The overwritten content
+// is backed up if it is smaller than MaxSize.
+type File struct {
+
+	// Log is a logger for reporting errors it defaults to the
+	// zero-logger.  Log.Env determines the cache directory holding
+	// the backups.
+	Log *lg.Logger
+
+	// Lib provides the std-lib functions File needs to provide its
+	// features
+	Lib Lib
+
+	// Path of the loaded and saved file.
+	Path string
+
+	// Backups is the number of backups which are kept.  Is Backups
+	// not positive it defaults to DefaultBackups; it is capped at
+	// MaxBackups.
+	Backups int
+
+	// MaxSize is the size in bytes from which on a file is not backed
+	// up.  It defaults to DefaultMaxSize if not positive.
+	MaxSize int64
+
+	// Perm is the permission of a file created by Save.  It defaults
+	// to 0644 if zero.
+	Perm fs.FileMode
+
+	initLib bool
+}
+
+func (f *File) lg() *lg.Logger {
+	if f.Log == nil {
+		f.Log = &lg.Logger{}
+	}
+	return f.Log
+}
+
+func (f *File) env() *env.Env {
+	if f.lg().Env == nil {
+		f.Log.Env = &env.Env{}
+	}
+	return f.Log.Env
+}
+
+func (f *File) lib() Lib {
+	if !f.initLib {
+		f.initLib = true
+		if f.Lib.ReadFile == nil {
+			f.Lib.ReadFile = ioutil.ReadFile
+		}
+		if f.Lib.WriteFile == nil {
+			f.Lib.WriteFile = ioutil.WriteFile
+		}
+		if f.Lib.CreateTemp == nil {
+			f.Lib.CreateTemp = ioutil.TempFile
+		}
+		if f.Lib.Rename == nil {
+			f.Lib.Rename = os.Rename
+		}
+		if f.Lib.Remove == nil {
+			f.Lib.Remove = os.Remove
+		}
+		if f.Lib.Stat == nil {
+			f.Lib.Stat = os.Stat
+		}
+		if f.Lib.ReadDir == nil {
+			f.Lib.ReadDir = os.ReadDir
+		}
+		if f.Lib.MkdirAll == nil {
+			f.Lib.MkdirAll = os.MkdirAll
+		}
+	}
+	return f.Lib
+}
+
+func (f *File) backups() int {
+	switch {
+	case f.Backups <= 0:
+		return DefaultBackups
+	case f.Backups > MaxBackups:
+		return MaxBackups
+	}
+	return f.Backups
+}
+
+func (f *File) maxSize() int64 {
+	if f.MaxSize <= 0 {
+		return DefaultMaxSize
+	}
+	return f.MaxSize
+}
+
+// Load returns the content of given file f.
+func (f *File) Load() ([]byte, error) {
+	bb, err := f.lib().ReadFile(f.Path)
+	if err != nil {
+		return nil, fmt.Errorf("gini: pkg: file: load: %w", err)
+	}
+	return bb, nil
+}
+
+// Save replaces the content of given file f with given content after
+// backing up the current content.  Save creates the file if it doesn't
+// exist.  Note a failing backup is logged to lg.ERR but doesn't prevent
+// the file from being saved.
+func (f *File) Save(content io.WriterTo) error {
+	perm := f.Perm
+	if perm == 0 {
+		perm = 0644
+	}
+	if fi, err := f.lib().Stat(f.Path); err == nil {
+		perm = fi.Mode().Perm()
+		if fi.Size() < f.maxSize() {
+			f.backup()
+		}
+	}
+	tmp, err := f.lib().CreateTemp(
+		filepath.Dir(f.Path), "."+filepath.Base(f.Path)+".*")
+	if err != nil {
+		return fmt.Errorf("gini: pkg: file: save: %w", err)
+	}
+	_, err = content.WriteTo(tmp)
+	if err == nil {
+		err = tmp.Chmod(perm)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cErr := tmp.Close(); err == nil {
+		err = cErr
+	}
+	if err == nil {
+		err = f.lib().Rename(tmp.Name(), f.Path)
+	}
+	if err != nil {
+		f.lib().Remove(tmp.Name())
+		return fmt.Errorf("gini: pkg: file: save: %w", err)
+	}
+	return nil
+}
+
+// BackupDir returns the directory holding the backups of given file f.
+// It is named by the hex encoded SHA-256 hash of f's absolute path which
+// is stored in the directory's PathFile.  Hence the name's length is
+// fixed however deeply f is nested.
+func (f *File) BackupDir() string {
+	sum := sha256.Sum256([]byte(filepath.ToSlash(f.abs())))
+	return filepath.Join(f.env().Cache(), BackupsDir,
+		hex.EncodeToString(sum[:]))
+}
+
+// abs returns the absolute path of given file f or its path if it can't
+// be made absolute.
+func (f *File) abs() string {
+	path, err := filepath.Abs(f.Path)
+	if err != nil {
+		return f.Path
+	}
+	return path
+}
+
+// backup copies the current content of given file f into a new
+// backup and prunes its backups afterwards.  Errors are logged.
+func (f *File) backup() {
+	bb, err := f.lib().ReadFile(f.Path)
+	if err != nil {
+		f.lg().Tof(lg.ERR, "gini: pkg: file: backup: %v", err)
+		return
+	}
+	dir := f.BackupDir()
+	if err := f.lib().MkdirAll(dir, 0700); err != nil {
+		f.lg().Tof(lg.ERR, "gini: pkg: file: backup: %v", err)
+		return
+	}
+	err = f.lib().WriteFile(
+		filepath.Join(dir, PathFile), []byte(f.abs()), 0600)
+	if err != nil {
+		f.lg().Tof(lg.ERR, "gini: pkg: file: backup: %v", err)
+		return
+	}
+	bkk, err := f.List()
+	if err != nil {
+		f.lg().Tof(lg.ERR, "gini: pkg: file: backup: %v", err)
+		return
+	}
+	n := 1
+	if len(bkk) > 0 {
+		n = bkk[0].N + 1
+	}
+	err = f.lib().WriteFile(
+		filepath.Join(dir, strconv.Itoa(n)), bb, 0600)
+	if err != nil {
+		f.lg().Tof(lg.ERR, "gini: pkg: file: backup: %v", err)
+		return
+	}
+	f.prune()
+}
+
+// prune removes the oldest backups of given file f exceeding f's
+// backups count and backups which are not smaller than f's MaxSize.
+func (f *File) prune() {
+	bb, err := f.List()
+	if err != nil {
+		f.lg().Tof(lg.ERR, "gini: pkg: file: prune: %v", err)
+		return
+	}
+	for i, b := range bb {
+		if i < f.backups() && b.Size < f.maxSize() {
+			continue
+		}
+		if err := f.lib().Remove(b.Path); err != nil {
+			f.lg().Tof(lg.ERR, "gini: pkg: file: prune: %v", err)
+		}
+	}
+}
+
+// Backup describes a backup of a File.
+type Backup struct {
+
+	// N is the backup's number which is the higher the more recent
+	// the backup is.
+	N int
+
+	// Path is the path of the backup's file.
+	Path string
+
+	// Size of the backup in bytes.
+	Size int64
+
+	// Time is the time the backup was made.
+	Time time.Time
+}
+
+// List returns the backups of given file f with the most recent backup
+// first.
+func (f *File) List() ([]Backup, error) {
+	dir := f.BackupDir()
+	ee, err := f.lib().ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("gini: pkg: file: list: %w", err)
+	}
+	bb := []Backup{}
+	for _, e := range ee {
+		n, err := strconv.Atoi(e.Name())
+		if err != nil || e.IsDir() {
+			continue
+		}
+		fi, err := e.Info()
+		if err != nil {
+			continue
+		}
+		bb = append(bb, Backup{
+			N:    n,
+			Path: filepath.Join(dir, e.Name()),
+			Size: fi.Size(),
+			Time: fi.ModTime(),
+		})
+	}
+	sort.Slice(bb, func(i, j int) bool { return bb[i].N > bb[j].N })
+	return bb, nil
+}
+
+// Restore replaces the content of given file f with the content of the
+// backup with given number n.  Since the replaced content is backed up
+// a restore can be undone by restoring the most recent backup.
+func (f *File) Restore(n int) error {
+	bb, err := f.lib().ReadFile(
+		filepath.Join(f.BackupDir(), strconv.Itoa(n)))
+	if err != nil {
+		return fmt.Errorf("gini: pkg: file: restore: %w", err)
+	}
+	return f.Save(content(bb))
+}
+
+// content turns a byte slice into an io.WriterTo.
+type content []byte
+
+func (c content) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(c)
+	return int64(n), err
+}
+
+// Lib provides std-lib functions which may fail.
+type Lib struct {
+
+	// ReadFile defaults to ioutil.ReadFile and its semantics
+	ReadFile func(name string) ([]byte, error)
+
+	// WriteFile defaults to ioutil.WriteFile and its semantics
+	WriteFile func(name string, data []byte, perm fs.FileMode) error
+
+	// CreateTemp defaults to ioutil.TempFile and its semantics
+	CreateTemp func(dir, pattern string) (*os.File, error)
+
+	// Rename defaults to os.Rename and its semantics
+	Rename func(oldpath, newpath string) error
+
+	// Remove defaults to os.Remove and its semantics
+	Remove func(name string) error
+
+	// Stat defaults to os.Stat and its semantics
+	Stat func(name string) (fs.FileInfo, error)
+
+	// ReadDir defaults to os.ReadDir and its semantics
+	ReadDir func(name string) ([]fs.DirEntry, error)
+
+	// MkdirAll defaults to os.MkdirAll and its semantics
+	MkdirAll func(path string, perm fs.FileMode) error
+}
@@ -0,0 +1,235 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package file
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/slukits/gini/pkg/env"
+	"github.com/slukits/gini/pkg/lg"
+	. "github.com/slukits/gounit"
+)
+
+type _File struct{ Suite }
+
+func (s *_File) SetUp(t *T) { t.Parallel() }
+
+// fileFX returns a File in a temporary environment whose path is
+// inside that environment's home and has given content if not nil.
+func fileFX(t *T, content []byte) *File {
+	lgg := &lg.Logger{Env: (&env.Env{}).SetHome(t.FS().Tmp().Path())}
+	f := &File{Log: lgg, Path: filepath.Join(lgg.Env.Home(), "gini.txt")}
+	if content != nil {
+		t.FatalOn(os.WriteFile(f.Path, content, 0600))
+	}
+	return f
+}
+
+func (s *_File) Loads_its_content(t *T) {
+	f := fileFX(t, []byte("gini"))
+	bb, err := f.Load()
+	t.FatalOn(err)
+	t.Eq("gini", string(bb))
+}
+
+func (s *_File) Fails_loading_a_not_existing_file(t *T) {
+	_, err := fileFX(t, nil).Load()
+	t.ErrIs(err, fs.ErrNotExist)
+}
+
+func (s *_File) Creates_a_not_existing_file_on_save(t *T) {
+	f := fileFX(t, nil)
+	t.FatalOn(f.Save(content("gini")))
+	bb, err := f.Load()
+	t.FatalOn(err)
+	t.Eq("gini", string(bb))
+	bkk, err := f.List()
+	t.FatalOn(err)
+	t.Eq(0, len(bkk))
+}
+
+func (s *_File) Backs_up_overwritten_content_on_save(t *T) {
+	f := fileFX(t, []byte("first"))
+	t.FatalOn(f.Save(content("second")))
+	bkk, err := f.List()
+	t.FatalOn(err)
+	t.FatalIfNot(t.Eq(1, len(bkk)))
+	t.Eq(1, bkk[0].N)
+	t.True(strings.HasPrefix(bkk[0].Path, f.Log.Env.Cache()))
+	bb, err := os.ReadFile(bkk[0].Path)
+	t.FatalOn(err)
+	t.Eq("first", string(bb))
+}
+
+func (s *_File) Backs_up_deeply_nested_files(t *T) {
+	f := fileFX(t, nil)
+	dir := filepath.Dir(f.Path)
+	for i := 0; i < 30; i++ {
+		dir = filepath.Join(dir, "a_rather_long_directory_name")
+	}
+	t.FatalOn(os.MkdirAll(dir, 0700))
+	f.Path = filepath.Join(dir, "gini.txt")
+	t.FatalOn(os.WriteFile(f.Path, []byte("first"), 0600))
+	t.FatalOn(f.Save(content("second")))
+	t.Not.Contains(f.Log.String(lg.ERR), "backup")
+	bkk, err := f.List()
+	t.FatalOn(err)
+	t.Eq(1, len(bkk))
+	bb, err := os.ReadFile(filepath.Join(f.BackupDir(), PathFile))
+	t.FatalOn(err)
+	t.Eq(f.Path, string(bb))
+}
+
+func (s *_File) Lists_most_recent_backup_first(t *T) {
+	f := fileFX(t, []byte("1"))
+	t.FatalOn(f.Save(content("2")))
+	t.FatalOn(f.Save(content("3")))
+	bkk, err := f.List()
+	t.FatalOn(err)
+	t.FatalIfNot(t.Eq(2, len(bkk)))
+	t.Eq(2, bkk[0].N)
+	t.Eq(1, bkk[1].N)
+}
+
+func (s *_File) Keeps_given_number_of_backups(t *T) {
+	f := fileFX(t, []byte("0"))
+	f.Backups = 3
+	for _, c := range []string{"1", "2", "3", "4", "5"} {
+		t.FatalOn(f.Save(content(c)))
+	}
+	bkk, err := f.List()
+	t.FatalOn(err)
+	t.FatalIfNot(t.Eq(3, len(bkk)))
+	t.Eq(5, bkk[0].N)
+	t.Eq(3, bkk[2].N)
+}
+
+func (s *_File) Has_default_and_maximal_number_of_backups(t *T) {
+	f := &File{}
+	t.Eq(DefaultBackups, f.backups())
+	f.Backups = MaxBackups + 1
+	t.Eq(MaxBackups, f.backups())
+}
+
+func (s *_File) Doesnt_back_up_files_of_max_size(t *T) {
+	f := fileFX(t, []byte("1234"))
+	f.MaxSize = 4
+	t.FatalOn(f.Save(content("5")))
+	bkk, err := f.List()
+	t.FatalOn(err)
+	t.Eq(0, len(bkk))
+}
+
+func (s *_File) Prunes_backups_not_smaller_than_max_size(t *T) {
+	f := fileFX(t, []byte("1234"))
+	t.FatalOn(f.Save(content("1")))
+	f.MaxSize = 4
+	t.FatalOn(f.Save(content("2")))
+	bkk, err := f.List()
+	t.FatalOn(err)
+	t.FatalIfNot(t.Eq(1, len(bkk)))
+	t.Eq(2, bkk[0].N)
+}
+
+func (s *_File) Keeps_its_permissions_on_save(t *T) {
+	f := fileFX(t, []byte("gini"))
+	t.FatalOn(os.Chmod(f.Path, 0640))
+	t.FatalOn(f.Save(content("GINI")))
+	fi, err := os.Stat(f.Path)
+	t.FatalOn(err)
+	t.Eq(fs.FileMode(0640), fi.Mode().Perm())
+}
+
+func (s *_File) Creates_a_file_with_its_permission(t *T) {
+	f := fileFX(t, nil)
+	f.Perm = 0600
+	t.FatalOn(f.Save(content("gini")))
+	fi, err := os.Stat(f.Path)
+	t.FatalOn(err)
+	t.Eq(fs.FileMode(0600), fi.Mode().Perm())
+}
+
+func (s *_File) Restores_a_backup(t *T) {
+	f := fileFX(t, []byte("first"))
+	t.FatalOn(f.Save(content("second")))
+	t.FatalOn(f.Restore(1))
+	bb, err := f.Load()
+	t.FatalOn(err)
+	t.Eq("first", string(bb))
+	bkk, err := f.List()
+	t.FatalOn(err)
+	t.FatalIfNot(t.Eq(2, len(bkk)))
+	bb, err = os.ReadFile(bkk[0].Path)
+	t.FatalOn(err)
+	t.Eq("second", string(bb))
+}
+
+func (s *_File) Fails_restoring_a_not_existing_backup(t *T) {
+	f := fileFX(t, []byte("first"))
+	t.ErrIs(f.Restore(42), fs.ErrNotExist)
+}
+
+func (s *_File) Leaves_file_untouched_if_it_cant_be_replaced(t *T) {
+	f := fileFX(t, []byte("gini"))
+	f.Lib.Rename = func(_, _ string) error {
+		return errors.New("rename mock error")
+	}
+	t.ErrMatched(f.Save(content("GINI")), "rename mock error")
+	bb, err := f.Load()
+	t.FatalOn(err)
+	t.Eq("gini", string(bb))
+	ee, err := os.ReadDir(filepath.Dir(f.Path))
+	t.FatalOn(err)
+	for _, e := range ee {
+		t.Not.True(strings.HasPrefix(e.Name(), ".gini.txt"))
+	}
+}
+
+func (s *_File) Fails_saving_if_temp_file_cant_be_created(t *T) {
+	f := fileFX(t, []byte("gini"))
+	f.Lib.CreateTemp = func(_, _ string) (*os.File, error) {
+		return nil, errors.New("create-temp mock error")
+	}
+	t.ErrMatched(f.Save(content("GINI")), "create-temp mock error")
+}
+
+func (s *_File) Saves_and_reports_error_if_backup_fails(t *T) {
+	f := fileFX(t, []byte("gini"))
+	f.Lib.MkdirAll = func(string, fs.FileMode) error {
+		return errors.New("mkdir mock error")
+	}
+	t.FatalOn(f.Save(content("GINI")))
+	bb, err := f.Load()
+	t.FatalOn(err)
+	t.Eq("GINI", string(bb))
+	t.Contains(f.Log.String(lg.ERR), "mkdir mock error")
+}
+
+func TestFile(t *testing.T) {
+	t.Parallel()
+	Run(&_File{}, t)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package ftype
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"regexp"
+	"strings"
+
+	"github.com/slukits/gini/pkg/settings"
+)
+
+// IndentSetting is the name of the json type's setting of the number of
+// spaces a nesting level is indented by its format command.
+const IndentSetting = "indent"
+
+// Builtin returns a registry of the types GINI knows out of the box:
+// go, json, gnh (GINI's help pages) and sh (shell scripts).
+func Builtin() *Registry {
+	r := &Registry{}
+	for _, t := range []*Type{
+		{Name: "go", Patterns: []string{"*.go"}, Sniff: goSniff,
+			LexerDef: definition("go.lex"),
+			Commands: []Command{{Name: "format", Key: 'f',
+				Exec: goFormat}}},
+		{Name: "json", Patterns: []string{"*.json"}, Sniff: jsonSniff,
+			LexerDef:   definition("json.lex"),
+			GrammarDef: definition("json.ebnf"),
+			Settings: []settings.Setting{{Name: IndentSetting,
+				Kind: settings.Int, Default: 2,
+				Doc: "spaces per indentation level", Check: indent}},
+			Commands: []Command{{Name: "format", Key: 'f',
+				Exec: jsonFormat}}},
+		{Name: "gnh", Patterns: []string{"*.gnh"}},
+		{Name: "sh", Patterns: []string{"*.sh", "*.bash"},
+			Interpreters: []string{"sh", "bash", "zsh", "dash"},
+			LexerDef:     definition("sh.lex")},
+	} {
+		if err := r.Register(t); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+// definition returns the embedded definition with given name.
+func definition(name string) string {
+	bb, err := defs.ReadFile("defs/" + name)
+	if err != nil {
+		panic(err)
+	}
+	return string(bb)
+}
+
+var goPackage = regexp.MustCompile(`(?m)^package [\pL_][\pL\pN_]*\s*$`)
+
+// goSniff returns true if given head has a package clause.
+func goSniff(head []byte) bool { return goPackage.Match(head) }
+
+// jsonSniff returns true if given head starts with an object.
+func jsonSniff(head []byte) bool {
+	head = bytes.TrimSpace(head)
+	if len(head) < 2 || head[0] != '{' {
+		return false
+	}
+	head = bytes.TrimSpace(head[1:])
+	return len(head) > 0 && (head[0] == '"' || head[0] == '}')
+}
+
+// indent checks the value of the indent setting.
+func indent(v interface{}) error {
+	if i := v.(int); i < 0 || i > 8 {
+		return errors.New("must be between 0 and 8")
+	}
+	return nil
+}
+
+// goFormat formats given go source src.
+func goFormat(src []byte, _ Lookup) ([]byte, string, error) {
+	bb, err := format.Source(src)
+	if err != nil {
+		return nil, "", fmt.Errorf("gini: ftype: go: format: %w", err)
+	}
+	return bb, "formatted", nil
+}
+
+// jsonFormat indents given json content src by the number of spaces of
+// the indent setting.
+func jsonFormat(src []byte, setting Lookup) ([]byte, string, error) {
+	indent, ok := setting(IndentSetting).(int)
+	if !ok || indent < 0 {
+		indent = 2
+	}
+	buf := &bytes.Buffer{}
+	err := json.Indent(buf, bytes.TrimSpace(src), "",
+		strings.Repeat(" ", indent))
+	if err != nil {
+		return nil, "", fmt.Errorf("gini: ftype: json: format: %w", err)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), "formatted", nil
+}
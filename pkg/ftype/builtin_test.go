@@ -0,0 +1,107 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package ftype
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/slukits/gini/pkg/lex"
+	. "github.com/slukits/gounit"
+)
+
+type _Builtin struct{ Suite }
+
+func (s *_Builtin) SetUp(t *T) { t.Parallel() }
+
+// classes returns the classes of the tokens of given src lexed by the
+// lexer of given builtin type's name.
+func classes(t *T, name, src string) (cc []string) {
+	tp, _ := Builtin().Type(name)
+	l, err := tp.Lexer()
+	t.FatalOn(err)
+	for _, tk := range l.Lex(strings.Split(src, "\n")).All() {
+		if tk.Class != "space" {
+			cc = append(cc, tk.Class)
+		}
+	}
+	return cc
+}
+
+func (s *_Builtin) Go_lexer(t *T) {
+	t.Eq([]string{"keyword", "ident", "operator", "string", "operator",
+		"number", "comment"},
+		classes(t, "go", `var s = "a\"b" + 4.2e1 // x`))
+	t.Eq([]string{"comment", "comment", "comment", "string", "string",
+		"string", "string"}, classes(t, "go", "/* a\n*/ `raw\nstring`"))
+	t.Eq([]string{"ident", "operator", "ident"},
+		classes(t, "go", "forx := iffy"))
+	t.Eq([]string{"ident", "operator", "rune"},
+		classes(t, "go", `r := '\n'`))
+	t.Eq(0, len(filter(classes(t, "go", "a <<= b &^ c..."),
+		lex.Unknown)))
+}
+
+func (s *_Builtin) Sh_lexer(t *T) {
+	t.Eq([]string{"keyword", "operator", "variable", "operator",
+		"operator", "keyword", "ident", "string", "operator", "keyword",
+		"comment"},
+		classes(t, "sh", `if [ $HOME ]; then echo "x"; fi # y`))
+}
+
+func filter(cc []string, class string) (ff []string) {
+	for _, c := range cc {
+		if c == class {
+			ff = append(ff, c)
+		}
+	}
+	return ff
+}
+
+func (s *_Builtin) Go_formats_source(t *T) {
+	goType, _ := Builtin().Type("go")
+	bb, msg, err := goType.command('f').Exec(
+		[]byte("package x\nfunc  f( ) {}"), nil)
+	t.FatalOn(err)
+	t.Eq("formatted", msg)
+	t.Eq("package x\n\nfunc f() {}\n", string(bb))
+	_, _, err = goType.command('f').Exec([]byte("package"), nil)
+	t.ErrMatched(err, "go: format")
+}
+
+func (s *_Builtin) Json_formats_by_indent_setting(t *T) {
+	jsonType, _ := Builtin().Type("json")
+	bb, _, err := jsonType.command('f').Exec([]byte(`{"a":[1]}`+"\n"),
+		func(name string) interface{} {
+			t.Eq(IndentSetting, name)
+			return 1
+		})
+	t.FatalOn(err)
+	t.Eq("{\n \"a\": [\n  1\n ]\n}\n", string(bb))
+	t.ErrMatched(indent(9), "between")
+	t.FatalOn(indent(4))
+}
+
+func TestBuiltin(t *testing.T) {
+	t.Parallel()
+	Run(&_Builtin{}, t)
+}
@@ -0,0 +1,291 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+/*
+Package ftype detects the types of files and binds each type to the
+features GINI provides for it:  the definitions of its lexer (see lex)
+and parser (see parse), its settings and the commands of its file-type
+context.  A Registry detects the type of a file by its name, e.g. *.go,
+by the interpreter of its shebang line, e.g. #!/usr/bin/env bash, or by
+sniffing its content, e.g. a leading "package" clause; a file of an
+undetected type is of the type Text.
+*/
+package ftype
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/slukits/gini/pkg/lex"
+	"github.com/slukits/gini/pkg/parse"
+	"github.com/slukits/gini/pkg/settings"
+)
+
+//go:embed defs/*
+var defs embed.FS
+
+// SniffLen is the number of a file's leading bytes which are needed for
+// its detection.
+const SniffLen = 512
+
+var (
+
+	// ErrRegistered is returned for the registration of a type whose
+	// name is already registered.
+	ErrRegistered = errors.New("gini: ftype: type already registered")
+
+	// ErrNoGrammar is returned by Grammar for a type without grammar.
+	ErrNoGrammar = errors.New("gini: ftype: no grammar")
+
+	// ErrNoLexer is returned by Lexer for a type without lexer.
+	ErrNoLexer = errors.New("gini: ftype: no lexer")
+)
+
+// Lookup returns the value of a type's setting with given name (see
+// Type.Setting) for the file a command is executed on.
+type Lookup func(name string) interface{}
+
+// Command is a command of a file type's context.
+type Command struct {
+
+	// Name labels the command in the file-type context.
+	Name string
+
+	// Key selects the command in the file-type context.
+	Key rune
+
+	// Exec executes the command on given content src of a file of the
+	// command's type whose settings are looked up by given function.
+	// It returns the content which replaces src or nil and a message
+	// for the user.
+	Exec func(src []byte, setting Lookup) (
+		replace []byte, msg string, err error)
+}
+
+// Type is a file type.
+type Type struct {
+
+	// Name identifies the type, e.g. "go".
+	Name string
+
+	// Patterns are the patterns of the names of the type's files, e.g.
+	// "*.go" or "Makefile" (see filepath.Match).
+	Patterns []string
+
+	// Interpreters are the names of the interpreters of the type's
+	// scripts named by their shebang line, e.g. "bash".  A version
+	// suffix of an interpreter is ignored, e.g. "python3".
+	Interpreters []string
+
+	// Sniff returns true if given leading bytes of a file's content
+	// are of the type.
+	Sniff func(head []byte) bool
+
+	// LexerDef is the definition of the type's lexer (see lex.Compile)
+	// or empty.
+	LexerDef string
+
+	// GrammarDef is the definition of the type's parser (see
+	// parse.Compile) whose tokens are lexed by its lexer or empty.
+	GrammarDef string
+
+	// Settings are specific to the type; their names are qualified by
+	// the type's name (see Type.Setting).
+	Settings []settings.Setting
+
+	// Commands are the features of the type's file-type context.
+	Commands []Command
+
+	lexer   *lex.Lexer
+	grammar *parse.Grammar
+}
+
+// Text is the type of files whose type isn't detected.
+var Text = &Type{Name: "text"}
+
+// Setting returns the name of given type t's setting with given name
+// qualified by t's name, e.g. "json indent".
+func (t *Type) Setting(name string) string { return t.Name + " " + name }
+
+// Lexer returns given type t's lexer compiled from its definition.
+func (t *Type) Lexer() (*lex.Lexer, error) {
+	if t.lexer != nil {
+		return t.lexer, nil
+	}
+	if t.LexerDef == "" {
+		return nil, fmt.Errorf("%w: %s", ErrNoLexer, t.Name)
+	}
+	l, err := lex.Compile(t.LexerDef)
+	if err != nil {
+		return nil, fmt.Errorf("gini: ftype: %s: %w", t.Name, err)
+	}
+	t.lexer = l
+	return l, nil
+}
+
+// Grammar returns given type t's grammar compiled from its definition.
+func (t *Type) Grammar() (*parse.Grammar, error) {
+	if t.grammar != nil {
+		return t.grammar, nil
+	}
+	if t.GrammarDef == "" {
+		return nil, fmt.Errorf("%w: %s", ErrNoGrammar, t.Name)
+	}
+	g, err := parse.Compile(t.GrammarDef)
+	if err != nil {
+		return nil, fmt.Errorf("gini: ftype: %s: %w", t.Name, err)
+	}
+	t.grammar = g
+	return g, nil
+}
+
+// Parse lexes and parses given content src by given type t's lexer and
+// grammar.
+func (t *Type) Parse(src []byte) (*parse.Node, error) {
+	l, err := t.Lexer()
+	if err != nil {
+		return nil, err
+	}
+	g, err := t.Grammar()
+	if err != nil {
+		return nil, err
+	}
+	return g.Parse(l.Lex(strings.Split(string(src), "\n")).All()), nil
+}
+
+// Registry detects the types of files among its registered types.  The
+// zero value has no types (see Builtin).
+type Registry struct {
+	tt []*Type
+}
+
+// Register adds given type t to given registry r.  A type with a
+// grammar gets the command "check" (c) reporting syntax errors unless
+// it has a command with the key c.
+func (r *Registry) Register(t *Type) error {
+	if _, ok := r.Type(t.Name); ok || t.Name == Text.Name {
+		return fmt.Errorf("%w: %s", ErrRegistered, t.Name)
+	}
+	if t.GrammarDef != "" && t.command('c') == nil {
+		t.Commands = append(t.Commands, Command{Name: "check",
+			Key: 'c', Exec: t.check})
+	}
+	r.tt = append(r.tt, t)
+	return nil
+}
+
+func (t *Type) command(k rune) *Command {
+	for i := range t.Commands {
+		if t.Commands[i].Key == k {
+			return &t.Commands[i]
+		}
+	}
+	return nil
+}
+
+// check reports the first syntax error of given content src.
+func (t *Type) check(src []byte, _ Lookup) ([]byte, string, error) {
+	root, err := t.Parse(src)
+	if err != nil {
+		return nil, "", err
+	}
+	ee := root.Errors()
+	if len(ee) == 0 {
+		return nil, "no syntax errors", nil
+	}
+	return nil, fmt.Sprintf("%d syntax errors; first %d:%d: %s",
+		len(ee), ee[0].Ln+1, ee[0].Cl+1, ee[0].Text), nil
+}
+
+// Types returns the registered types in the order of their
+// registration.
+func (r *Registry) Types() []*Type {
+	return append([]*Type{}, r.tt...)
+}
+
+// Type returns the registered type with given name.
+func (r *Registry) Type(name string) (*Type, bool) {
+	for _, t := range r.tt {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// Detect returns the type of the file with given path whose content
+// starts with given bytes head (see SniffLen).  Its name is matched
+// first, then its shebang line and finally its content is sniffed; the
+// first registered type which matches wins.  Text is returned if no
+// type matches.
+func (r *Registry) Detect(path string, head []byte) *Type {
+	name := filepath.Base(path)
+	for _, t := range r.tt {
+		for _, p := range t.Patterns {
+			if ok, _ := filepath.Match(p, name); ok {
+				return t
+			}
+		}
+	}
+	if interpreter := shebang(head); interpreter != "" {
+		for _, t := range r.tt {
+			for _, i := range t.Interpreters {
+				if i == interpreter {
+					return t
+				}
+			}
+		}
+	}
+	for _, t := range r.tt {
+		if t.Sniff != nil && t.Sniff(head) {
+			return t
+		}
+	}
+	return Text
+}
+
+// shebang returns the name of the interpreter of given head's shebang
+// line without version suffix or an empty string if there is none.
+func shebang(head []byte) string {
+	if !bytes.HasPrefix(head, []byte("#!")) {
+		return ""
+	}
+	line, _, _ := bytes.Cut(head[2:], []byte("\n"))
+	ff := strings.Fields(string(line))
+	if len(ff) == 0 {
+		return ""
+	}
+	interpreter := filepath.Base(ff[0])
+	if interpreter == "env" {
+		interpreter = ""
+		for _, f := range ff[1:] {
+			if !strings.HasPrefix(f, "-") && !strings.Contains(f, "=") {
+				interpreter = filepath.Base(f)
+				break
+			}
+		}
+	}
+	return strings.TrimRight(interpreter, "0123456789.")
+}
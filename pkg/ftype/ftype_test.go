@@ -0,0 +1,110 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package ftype
+
+import (
+	"testing"
+
+	. "github.com/slukits/gounit"
+)
+
+type _Registry struct{ Suite }
+
+func (s *_Registry) SetUp(t *T) { t.Parallel() }
+
+func (s *_Registry) Detects_types_by_name(t *T) {
+	r := Builtin()
+	t.Eq("go", r.Detect("/a/main.go", nil).Name)
+	t.Eq("json", r.Detect("conf.json", []byte("[1]")).Name)
+	t.Eq("gnh", r.Detect("hlp/index.gnh", nil).Name)
+	t.Eq("sh", r.Detect("x.bash", nil).Name)
+	t.True(r.Detect("README", []byte("some text")) == Text)
+}
+
+func (s *_Registry) Detects_types_by_shebang(t *T) {
+	r := Builtin()
+	for _, head := range []string{
+		"#!/bin/sh\necho", "#!/usr/bin/env bash", "#! /bin/zsh -e",
+		"#!/usr/bin/env -S LANG=C dash\n",
+	} {
+		t.Eq("sh", r.Detect("run", []byte(head)).Name)
+	}
+	t.True(r.Detect("run", []byte("#!/usr/bin/python3")) == Text)
+	t.Eq("python", shebang([]byte("#!/usr/bin/env python3.11")))
+}
+
+func (s *_Registry) Detects_types_by_sniffing_their_content(t *T) {
+	r := Builtin()
+	t.Eq("go", r.Detect("x", []byte("// doc\npackage main\n")).Name)
+	t.Eq("json", r.Detect("x", []byte(" {\n \"a\": 1}")).Name)
+	t.True(r.Detect("x", []byte("{ a }")) == Text)
+	t.True(r.Detect("x", []byte("the package x")) == Text)
+}
+
+func (s *_Registry) Rejects_already_registered_names(t *T) {
+	r := Builtin()
+	t.ErrIs(r.Register(&Type{Name: "go"}), ErrRegistered)
+	t.ErrIs(r.Register(&Type{Name: Text.Name}), ErrRegistered)
+	t.FatalOn(r.Register(&Type{Name: "md", Patterns: []string{"*.md"}}))
+	t.Eq("md", r.Detect("README.md", nil).Name)
+	tt := r.Types()
+	t.Eq("md", tt[len(tt)-1].Name)
+}
+
+func (s *_Registry) Adds_check_command_to_types_with_grammar(t *T) {
+	r := Builtin()
+	json, _ := r.Type("json")
+	check := json.command('c')
+	t.FatalIfNot(t.True(check != nil))
+	_, msg, err := check.Exec([]byte(`{"a": [1, true]}`), nil)
+	t.FatalOn(err)
+	t.Eq("no syntax errors", msg)
+	_, msg, err = check.Exec([]byte("{\"a\": [1,\n true}"), nil)
+	t.FatalOn(err)
+	t.Eq(`1 syntax errors; first 2:6: expected "]"`, msg)
+	goType, _ := r.Type("go")
+	t.True(goType.command('c') == nil)
+}
+
+func (s *_Registry) Compiles_the_definitions_of_builtin_types(t *T) {
+	for _, tp := range Builtin().Types() {
+		if tp.LexerDef != "" {
+			_, err := tp.Lexer()
+			t.FatalOn(err)
+		}
+		if tp.GrammarDef != "" {
+			_, err := tp.Grammar()
+			t.FatalOn(err)
+		}
+	}
+	_, err := Text.Lexer()
+	t.ErrIs(err, ErrNoLexer)
+	_, err = Text.Grammar()
+	t.ErrIs(err, ErrNoGrammar)
+	_, err = (&Type{Name: "x", LexerDef: "a ("}).Lexer()
+	t.ErrMatched(err, "gini: ftype: x:")
+}
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+	Run(&_Registry{}, t)
+}
@@ -0,0 +1,360 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+/*
+Package gnh parses GINI's help pages into a document tree.  A help page
+is line oriented text which is structured by the following grammar
+whereas EOL is a line break or the end of the page and a blank line
+contains only white space:
+
+	page      = { blank | heading | list | paragraph } .
+	heading   = "#" { "#" } " " inline EOL .
+	list      = item { item | more } .
+	item      = { " " } "- " inline EOL .
+	more      = "  " { " " } inline EOL .
+	paragraph = inline EOL { inline EOL } .
+	inline    = { text | emphasis | key | link } .
+	emphasis  = "_" text "_" .
+	key       = "<" name ">" .
+	link      = "[" text "](" [ page ] [ "#" section ] ")" .
+
+A paragraph line mustn't start a heading or an item; the more-lines of
+an item are indented deeper than the item's "-".  An emphasis starts at
+the beginning of a word and ends at the end of a word, e.g. the title of
+an article: _Why another "editor"_.  A key is named by lower case
+letters, digits or "-", e.g. <space> or <ctrl>d.  A link refers to a
+section of a help page by its heading's title, e.g.
+[lines](contexts.gnh#lines); without page it refers to a section of
+its own page.  Inline elements never span several lines.
+*/
+package gnh
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Kind classifies the nodes of a document tree.
+type Kind int
+
+const (
+
+	// Page is the root of a document tree holding its blocks.
+	Page Kind = iota
+
+	// Heading is a heading block whose Level is its number of #.
+	Heading
+
+	// List is a block of items.
+	List
+
+	// Item is an entry of a list whose Level is its indentation
+	// divided by two.
+	Item
+
+	// Paragraph is a block of lines of inline elements.
+	Paragraph
+
+	// Text is plain text.
+	Text
+
+	// Emphasis is emphasized text without its underscores.
+	Emphasis
+
+	// Key names a key of the keyboard without its angle brackets.
+	Key
+
+	// Link is the label of a link to the section its Target refers to.
+	Link
+)
+
+var kinds = []string{"page", "heading", "list", "item", "paragraph",
+	"text", "emphasis", "key", "link"}
+
+// String returns the name of given kind k.
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kinds) {
+		return "unknown"
+	}
+	return kinds[k]
+}
+
+// Node is a node of a help page's document tree.  Block nodes span
+// whole lines while inline nodes are parts of a line.
+type Node struct {
+	Kind Kind
+
+	// Level is the level of a heading or an item.
+	Level int
+
+	// Text is the content of an inline node, e.g. the label of a link,
+	// or the title of a heading.
+	Text string
+
+	// Target is the anchor a link refers to, e.g. "contexts.gnh#lines".
+	Target string
+
+	// Ln and Cl are the line and rune column of the node's first rune
+	// in the parsed page while EndLn and EndCl are the line and the
+	// column behind the node's last rune.
+	Ln, Cl, EndLn, EndCl int
+
+	// Children are the blocks of a page, the items of a list and the
+	// inline nodes of a heading, an item or a paragraph.
+	Children []*Node
+}
+
+// Contains returns true if given position is inside given node n.
+func (n *Node) Contains(ln, cl int) bool {
+	switch {
+	case ln < n.Ln || ln > n.EndLn:
+		return false
+	case ln == n.Ln && cl < n.Cl:
+		return false
+	case ln == n.EndLn && cl >= n.EndCl:
+		return false
+	}
+	return true
+}
+
+// At returns the innermost node of given node n's tree containing given
+// position or nil if n doesn't contain it.
+func (n *Node) At(ln, cl int) *Node {
+	if !n.Contains(ln, cl) {
+		return nil
+	}
+	for _, c := range n.Children {
+		if at := c.At(ln, cl); at != nil {
+			return at
+		}
+	}
+	return n
+}
+
+// Walk calls given function fn for given node n and its descendants in
+// document order.
+func (n *Node) Walk(fn func(*Node)) {
+	fn(n)
+	for _, c := range n.Children {
+		c.Walk(fn)
+	}
+}
+
+// Headings returns the headings of given node n's tree in document
+// order.
+func (n *Node) Headings() (hh []*Node) {
+	n.Walk(func(n *Node) {
+		if n.Kind == Heading {
+			hh = append(hh, n)
+		}
+	})
+	return hh
+}
+
+// Parse parses given help page src into its document tree.
+func Parse(src string) *Node {
+	ll := strings.Split(src, "\n")
+	p := &Node{Kind: Page, EndLn: len(ll) - 1,
+		EndCl: len([]rune(ll[len(ll)-1]))}
+	var block *Node
+	for ln, l := range ll {
+		rr := []rune(l)
+		indent := 0
+		for indent < len(rr) && rr[indent] == ' ' {
+			indent++
+		}
+		switch {
+		case indent == len(rr):
+			block = nil
+		case rr[0] == '#' && heading(rr) > 0:
+			block = nil
+			level := heading(rr)
+			h := &Node{Kind: Heading, Level: level, Ln: ln, EndLn: ln,
+				EndCl: len(rr), Text: strings.TrimSpace(string(
+					rr[level+1:]))}
+			h.Children = inline(rr, ln, level+1)
+			p.Children = append(p.Children, h)
+		case item(rr, indent):
+			if block == nil || block.Kind != List {
+				block = &Node{Kind: List, Ln: ln}
+				p.Children = append(p.Children, block)
+			}
+			block.Children = append(block.Children, &Node{Kind: Item,
+				Level: indent / 2, Ln: ln, Cl: indent,
+				Children: inline(rr, ln, indent+2)})
+			end(block, ln, len(rr))
+		case block != nil && block.Kind == List &&
+			indent > last(block).Cl:
+			it := last(block)
+			it.Children = append(it.Children, inline(rr, ln, indent)...)
+			end(block, ln, len(rr))
+		default:
+			if block == nil || block.Kind != Paragraph {
+				block = &Node{Kind: Paragraph, Ln: ln}
+				p.Children = append(p.Children, block)
+			}
+			block.Children = append(block.Children,
+				inline(rr, ln, indent)...)
+			block.EndLn, block.EndCl = ln, len(rr)
+		}
+	}
+	return p
+}
+
+// heading returns the number of # starting given line rr if they are
+// followed by a space; otherwise zero.
+func heading(rr []rune) int {
+	level := 0
+	for level < len(rr) && rr[level] == '#' {
+		level++
+	}
+	if level == len(rr) || rr[level] != ' ' {
+		return 0
+	}
+	return level
+}
+
+// item returns true if given line rr starts an item at given indent.
+func item(rr []rune, indent int) bool {
+	return indent+1 < len(rr) && rr[indent] == '-' && rr[indent+1] == ' '
+}
+
+func last(list *Node) *Node { return list.Children[len(list.Children)-1] }
+
+// end extends given list and its last item to given position.
+func end(list *Node, ln, cl int) {
+	list.EndLn, list.EndCl = ln, cl
+	it := last(list)
+	it.EndLn, it.EndCl = ln, cl
+}
+
+// inline parses the inline nodes of given line rr with given index ln
+// starting at given column cl.
+func inline(rr []rune, ln, cl int) (nn []*Node) {
+	text := cl
+	flush := func(end int) {
+		if end > text {
+			nn = append(nn, &Node{Kind: Text, Text: string(rr[text:end]),
+				Ln: ln, Cl: text, EndLn: ln, EndCl: end})
+		}
+	}
+	for i := cl; i < len(rr); {
+		n := element(rr, ln, i)
+		if n == nil {
+			i++
+			continue
+		}
+		flush(i)
+		nn = append(nn, n)
+		i, text = n.EndCl, n.EndCl
+	}
+	flush(len(rr))
+	return nn
+}
+
+// element returns the emphasis, key or link starting at given column cl
+// of given line rr or nil if there is no such element.
+func element(rr []rune, ln, cl int) *Node {
+	var kind Kind
+	var text, target string
+	end := -1
+	switch rr[cl] {
+	case '_':
+		kind, end = Emphasis, emphasis(rr, cl)
+		if end > 0 {
+			text = string(rr[cl+1 : end-1])
+		}
+	case '<':
+		kind, end = Key, key(rr, cl)
+		if end > 0 {
+			text = string(rr[cl+1 : end-1])
+		}
+	case '[':
+		kind = Link
+		end, text, target = link(rr, cl)
+	}
+	if end < 0 {
+		return nil
+	}
+	return &Node{Kind: kind, Text: text, Target: target,
+		Ln: ln, Cl: cl, EndLn: ln, EndCl: end}
+}
+
+// emphasis returns the column behind the emphasis starting at given
+// column cl of given line rr or -1 if there is no such emphasis.
+func emphasis(rr []rune, cl int) int {
+	if cl > 0 && word(rr[cl-1]) || cl+1 >= len(rr) || rr[cl+1] == ' ' {
+		return -1
+	}
+	for i := cl + 2; i < len(rr); i++ {
+		if rr[i] != '_' || rr[i-1] == ' ' {
+			continue
+		}
+		if i+1 == len(rr) || !word(rr[i+1]) {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// key returns the column behind the key starting at given column cl of
+// given line rr or -1 if there is no such key.
+func key(rr []rune, cl int) int {
+	for i := cl + 1; i < len(rr); i++ {
+		switch r := rr[i]; {
+		case r == '>':
+			if i == cl+1 {
+				return -1
+			}
+			return i + 1
+		case !unicode.IsLower(r) && !unicode.IsDigit(r) && r != '-':
+			return -1
+		}
+	}
+	return -1
+}
+
+// link returns the column behind the link starting at given column cl
+// of given line rr along with its label and target or -1 if there is no
+// such link.
+func link(rr []rune, cl int) (end int, label, target string) {
+	close := -1
+	for i := cl + 1; i < len(rr); i++ {
+		if rr[i] == ']' {
+			close = i
+			break
+		}
+	}
+	if close < 0 || close+1 >= len(rr) || rr[close+1] != '(' {
+		return -1, "", ""
+	}
+	for i := close + 2; i < len(rr); i++ {
+		if rr[i] == ')' {
+			return i + 1, string(rr[cl+1 : close]),
+				string(rr[close+2 : i])
+		}
+	}
+	return -1, "", ""
+}
+
+func word(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package gnh
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/slukits/gounit"
+)
+
+type _Parse struct{ Suite }
+
+func (s *_Parse) SetUp(t *T) { t.Parallel() }
+
+// kindsOf returns the kinds of given nodes nn.
+func kindsOf(nn []*Node) (kk []Kind) {
+	for _, n := range nn {
+		kk = append(kk, n.Kind)
+	}
+	return kk
+}
+
+func (s *_Parse) Headings_lists_and_paragraphs_into_blocks(t *T) {
+	p := Parse("# GINI\n\nsome\ntext\n- an item\n  more\n- item\n" +
+		"text\n## sub")
+	t.Eq([]Kind{Heading, Paragraph, List, Paragraph, Heading},
+		kindsOf(p.Children))
+	h := p.Children[0]
+	t.Eq("GINI", h.Text)
+	t.Eq(1, h.Level)
+	t.Eq(2, p.Children[4].Level)
+	t.Eq(2, len(p.Children[1].Children))
+	list := p.Children[2]
+	t.Eq([]Kind{Item, Item}, kindsOf(list.Children))
+	t.Eq(4, list.Ln)
+	t.Eq(6, list.EndLn)
+	t.Eq("more", list.Children[0].Children[1].Text)
+	t.Eq(5, list.Children[0].EndLn)
+}
+
+func (s *_Parse) Inline_elements(t *T) {
+	p := Parse("press <space>, read _the intro_ or [lines](a.gnh#b)")
+	nn := p.Children[0].Children
+	t.Eq([]Kind{Text, Key, Text, Emphasis, Text, Link}, kindsOf(nn))
+	t.Eq("space", nn[1].Text)
+	t.Eq(6, nn[1].Cl)
+	t.Eq(13, nn[1].EndCl)
+	t.Eq("the intro", nn[3].Text)
+	t.Eq("lines", nn[5].Text)
+	t.Eq("a.gnh#b", nn[5].Target)
+}
+
+func (s *_Parse) Text_which_only_looks_like_inline_elements(t *T) {
+	p := Parse("a_b_c <Space> 1 < 2 _ x_ [x] (y) <")
+	nn := p.Children[0].Children
+	t.Eq([]Kind{Text}, kindsOf(nn))
+}
+
+func (s *_Parse) Headings_which_need_a_space(t *T) {
+	p := Parse("#no heading\n##")
+	t.Eq([]Kind{Paragraph}, kindsOf(p.Children))
+}
+
+func (s *_Parse) Nodes_by_their_position(t *T) {
+	p := Parse("# intro\n\nsee [lines](#lines) now")
+	t.Eq(Link, p.At(2, 5).Kind)
+	t.Eq("#lines", p.At(2, 18).Target)
+	t.Eq(Text, p.At(2, 19).Kind)
+	t.Eq(Page, p.At(1, 0).Kind)
+	t.Eq((*Node)(nil), p.At(3, 0))
+	t.Eq(1, len(p.Headings()))
+}
+
+func (s *_Parse) GINI_s_help_pages(t *T) {
+	for _, name := range []string{"index.gnh", "contexts.gnh"} {
+		bb, err := os.ReadFile(filepath.Join("..", "..", "hlp", name))
+		t.FatalOn(err)
+		p := Parse(string(bb))
+		t.True(len(p.Headings()) > 0)
+		for _, n := range p.Children {
+			t.True(n.Kind != Text)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	Run(&_Parse{}, t)
+}
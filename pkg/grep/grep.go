@@ -0,0 +1,273 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+/*
+Package grep searches files and directories concurrently for lines
+matching a regular expression.  Matches are streamed as soon as they are
+found so a client may display them while the search is still running.
+*/
+package grep
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/slukits/gini/pkg/lg"
+)
+
+const (
+
+	// DefaultWorkers is the number of files searched concurrently if
+	// not set otherwise.
+	DefaultWorkers = 8
+
+	// SniffLen is the number of a file's leading bytes which are
+	// checked for a NUL byte to detect a binary file.
+	SniffLen = 8000
+)
+
+// Match is a match of a searched regular expression in a line of a
+// file.  Lines and columns are zero-based while columns count runes.
+type Match struct {
+
+	// Path is the path of the file containing the match.
+	Path string
+
+	// Ln is the index of the matching line.
+	Ln int
+
+	// Cl is the column of the match's first rune.
+	Cl int
+
+	// Len is the number of runes of the match.
+	Len int
+
+	// Line is the matching line.
+	Line string
+}
+
+// Grep searches files for lines matching a regular expression.  The
+// zero-type is ready to use.
+type Grep struct {
+
+	// Log is a logger for reporting errors it defaults to the
+	// zero-logger.
+	Log *lg.Logger
+
+	// Lib provides the std-lib functions Grep needs to provide its
+	// features
+	Lib Lib
+
+	// Workers is the number of files which are searched concurrently
+	// defaulting to DefaultWorkers.
+	Workers int
+
+	initLib bool
+}
+
+func (g *Grep) lg() *lg.Logger {
+	if g.Log == nil {
+		g.Log = &lg.Logger{}
+	}
+	return g.Log
+}
+
+func (g *Grep) lib() Lib {
+	if !g.initLib {
+		g.initLib = true
+		if g.Lib.ReadFile == nil {
+			g.Lib.ReadFile = ioutil.ReadFile
+		}
+		if g.Lib.ReadDir == nil {
+			g.Lib.ReadDir = os.ReadDir
+		}
+		if g.Lib.Stat == nil {
+			g.Lib.Stat = os.Stat
+		}
+	}
+	return g.Lib
+}
+
+func (g *Grep) workers() int {
+	if g.Workers <= 0 {
+		return DefaultWorkers
+	}
+	return g.Workers
+}
+
+// Search searches given paths for lines matching given regular
+// expression re.  A path may be a file or a directory whose files are
+// searched and, if recursive is true, the files of its sub-directories.
+// .git directories and binary files, i.e. files having a NUL byte in
+// their first SniffLen bytes, are skipped.  Matches are sent to the
+// returned channel which is closed after all files were searched or
+// given context ctx was canceled.  The matches of a file are sent in
+// order of their occurrence while files are searched concurrently.
+// Read errors are logged to lg.ERR.
+func (g *Grep) Search(
+	ctx context.Context, re *regexp.Regexp, recursive bool,
+	paths ...string,
+) <-chan Match {
+	g.lib()
+	g.lg()
+	files, mm := make(chan string), make(chan Match)
+	go func() {
+		for _, p := range paths {
+			if !g.walk(ctx, p, recursive, files) {
+				break
+			}
+		}
+		close(files)
+	}()
+	wg := &sync.WaitGroup{}
+	for i := 0; i < g.workers(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range files {
+				g.search(ctx, re, f, mm)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(mm)
+	}()
+	return mm
+}
+
+// walk sends given path p to given files channel if it is a file or
+// the files of the directory p if it is a directory whereas
+// sub-directories other than .git are walked if recursive is true.
+// Symbolic links to directories are skipped, e.g. a link to a parent
+// directory which would be walked forever, while symbolic links to
+// files are searched.  walk returns false if given context ctx was
+// canceled.
+func (g *Grep) walk(
+	ctx context.Context, p string, recursive bool, files chan<- string,
+) bool {
+	fi, err := g.lib().Stat(p)
+	if err != nil {
+		g.lg().Tof(lg.ERR, "gini: pkg: grep: %v", err)
+		return ctx.Err() == nil
+	}
+	if !fi.IsDir() {
+		return send(ctx, files, p)
+	}
+	ee, err := g.lib().ReadDir(p)
+	if err != nil {
+		g.lg().Tof(lg.ERR, "gini: pkg: grep: %v", err)
+		return ctx.Err() == nil
+	}
+	for _, e := range ee {
+		path, ok := filepath.Join(p, e.Name()), true
+		switch {
+		case e.Type()&fs.ModeSymlink != 0:
+			if fi, err := g.lib().Stat(path); err == nil && !fi.IsDir() {
+				ok = send(ctx, files, path)
+			}
+		case e.IsDir():
+			if recursive && e.Name() != ".git" {
+				ok = g.walk(ctx, path, recursive, files)
+			}
+		default:
+			ok = send(ctx, files, path)
+		}
+		if !ok {
+			return false
+		}
+	}
+	return ctx.Err() == nil
+}
+
+// send sends given value v to given channel c and returns false if
+// given context ctx was canceled before.
+func send[T any](ctx context.Context, c chan<- T, v T) bool {
+	select {
+	case c <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// search sends the matches of given regular expression re in the
+// file with given path to given channel mm until given context ctx is
+// canceled.
+func (g *Grep) search(
+	ctx context.Context, re *regexp.Regexp, path string, mm chan<- Match,
+) {
+	if ctx.Err() != nil {
+		return
+	}
+	bb, err := g.lib().ReadFile(path)
+	if err != nil {
+		g.lg().Tof(lg.ERR, "gini: pkg: grep: %v", err)
+		return
+	}
+	if IsBinary(bb) {
+		return
+	}
+	scn, ln := bufio.NewScanner(bytes.NewReader(bb)), 0
+	scn.Buffer(make([]byte, 0, 64*1024), len(bb)+1)
+	for scn.Scan() {
+		line := scn.Text()
+		for _, loc := range re.FindAllStringIndex(line, -1) {
+			if !send(ctx, mm, Match{Path: path, Ln: ln, Line: line,
+				Cl:  utf8.RuneCountInString(line[:loc[0]]),
+				Len: utf8.RuneCountInString(line[loc[0]:loc[1]]),
+			}) {
+				return
+			}
+		}
+		ln++
+	}
+}
+
+// IsBinary returns true if given content bb has a NUL byte in its first
+// SniffLen bytes.
+func IsBinary(bb []byte) bool {
+	if len(bb) > SniffLen {
+		bb = bb[:SniffLen]
+	}
+	return bytes.IndexByte(bb, 0) >= 0
+}
+
+// Lib provides std-lib functions which may fail.
+type Lib struct {
+
+	// ReadFile defaults to ioutil.ReadFile and its semantics
+	ReadFile func(name string) ([]byte, error)
+
+	// ReadDir defaults to os.ReadDir and its semantics
+	ReadDir func(name string) ([]fs.DirEntry, error)
+
+	// Stat defaults to os.Stat and its semantics
+	Stat func(name string) (fs.FileInfo, error)
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package grep
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/slukits/gini/pkg/env"
+	"github.com/slukits/gini/pkg/lg"
+	. "github.com/slukits/gounit"
+)
+
+type _Grep struct{ Suite }
+
+func (s *_Grep) SetUp(t *T) { t.Parallel() }
+
+// ctx is the context of searches which aren't canceled.
+var ctx = context.Background()
+
+// collect returns the matches of given channel mm sorted by path, line
+// and column.
+func collect(mm <-chan Match) (ss []Match) {
+	for m := range mm {
+		ss = append(ss, m)
+	}
+	sort.Slice(ss, func(i, j int) bool {
+		if ss[i].Path != ss[j].Path {
+			return ss[i].Path < ss[j].Path
+		}
+		if ss[i].Ln != ss[j].Ln {
+			return ss[i].Ln < ss[j].Ln
+		}
+		return ss[i].Cl < ss[j].Cl
+	})
+	return ss
+}
+
+func (s *_Grep) Finds_all_matches_of_a_file(t *T) {
+	d := t.FS().Tmp()
+	d.MkFile("a.txt", []byte("gini is\nnot an ide\nöh gini gini"))
+	got := collect((&Grep{}).Search(ctx, regexp.MustCompile("gini"), false,
+		filepath.Join(d.Path(), "a.txt")))
+	t.FatalIfNot(t.Eq(3, len(got)))
+	t.Eq(Match{Path: filepath.Join(d.Path(), "a.txt"), Ln: 2, Cl: 3,
+		Len: 4, Line: "öh gini gini"}, got[1])
+	t.Eq(8, got[2].Cl)
+}
+
+func (s *_Grep) Searches_directory_files_but_not_sub_directories(t *T) {
+	d := t.FS().Tmp()
+	d.MkFile("a.txt", []byte("gini"))
+	sub, _ := d.Mk("sub")
+	sub.MkFile("b.txt", []byte("gini"))
+	got := collect((&Grep{}).Search(ctx,
+		regexp.MustCompile("gini"), false, d.Path()))
+	t.FatalIfNot(t.Eq(1, len(got)))
+	t.Eq(filepath.Join(d.Path(), "a.txt"), got[0].Path)
+}
+
+func (s *_Grep) Searches_sub_directories_if_recursive(t *T) {
+	d := t.FS().Tmp()
+	d.MkFile("a.txt", []byte("gini"))
+	sub, _ := d.Mk("sub")
+	sub.MkFile("b.txt", []byte("gini"))
+	git, _ := d.Mk(".git")
+	git.MkFile("c.txt", []byte("gini"))
+	got := collect((&Grep{Workers: 2}).Search(ctx,
+		regexp.MustCompile("gini"), true, d.Path()))
+	t.FatalIfNot(t.Eq(2, len(got)))
+	t.Eq(filepath.Join(d.Path(), "sub", "b.txt"), got[1].Path)
+}
+
+func (s *_Grep) Skips_symbolically_linked_directories(t *T) {
+	d := t.FS().Tmp()
+	d.MkFile("a.txt", []byte("gini"))
+	sub, _ := d.Mk("sub")
+	sub.MkFile("b.txt", []byte("gini"))
+	t.FatalOn(os.Symlink("..", filepath.Join(sub.Path(), "loop")))
+	t.FatalOn(os.Symlink("a.txt", filepath.Join(d.Path(), "c.txt")))
+	got := collect((&Grep{}).Search(ctx,
+		regexp.MustCompile("gini"), true, d.Path()))
+	t.FatalIfNot(t.Eq(3, len(got)))
+	t.Eq(filepath.Join(d.Path(), "c.txt"), got[1].Path)
+	got = collect((&Grep{}).Search(ctx,
+		regexp.MustCompile("gini"), false, sub.Path()))
+	t.FatalIfNot(t.Eq(1, len(got)))
+	t.Eq(filepath.Join(sub.Path(), "b.txt"), got[0].Path)
+}
+
+func (s *_Grep) Skips_binary_files(t *T) {
+	d := t.FS().Tmp()
+	d.MkFile("a.bin", []byte("gini\x00is"))
+	d.MkFile("b.txt", []byte("gini"))
+	got := collect((&Grep{}).Search(ctx,
+		regexp.MustCompile("gini"), false, d.Path()))
+	t.FatalIfNot(t.Eq(1, len(got)))
+	t.Eq(filepath.Join(d.Path(), "b.txt"), got[0].Path)
+}
+
+func (s *_Grep) Logs_read_errors(t *T) {
+	d := t.FS().Tmp()
+	d.MkFile("a.txt", []byte("gini"))
+	g := &Grep{Log: &lg.Logger{Env: (&env.Env{}).SetHome(d.Path())}}
+	g.Lib.ReadFile = func(string) ([]byte, error) {
+		return nil, errors.New("read failed")
+	}
+	t.Eq(0, len(collect(g.Search(ctx, regexp.MustCompile("gini"), false,
+		d.Path()))))
+	t.Contains(g.Log.String(lg.ERR), "gini: pkg: grep: read failed")
+}
+
+func (s *_Grep) Stops_searching_if_canceled(t *T) {
+	d := t.FS().Tmp()
+	for i := 0; i < 100; i++ {
+		d.MkFile(fmt.Sprintf("%d.txt", i), []byte("gini\ngini\n"))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	mm := (&Grep{Workers: 2}).Search(
+		ctx, regexp.MustCompile("gini"), false, d.Path())
+	<-mm
+	cancel()
+	n := 1
+	for range mm {
+		n++
+	}
+	t.True(n < 200)
+}
+
+func TestGrep(t *testing.T) {
+	t.Parallel()
+	Run(&_Grep{}, t)
+}
@@ -0,0 +1,420 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+/*
+Package lex provides a generic lexer engine whose lexers are defined
+declaratively.  A definition lists rules grouped into modes; each rule
+names a token class and gives the regular expression its tokens match:
+
+	# a go like lexer
+	[code]
+	comment  //.*
+	string   `          >raw
+	string   "          >string
+	space    \s+
+	ident    [\pL_][\pL\pN_]*
+	number   \d+
+
+	[raw]
+	string   `          <
+	string   [^`]+
+
+	[string]
+	string   "          <
+	escape   \\.
+	string   [^"\\]+
+
+Blank lines and lines starting with # are ignored while a line [name]
+starts the mode with given name.  A rule's class, regular expression and
+optional action are separated by white space, i.e. a space inside a
+regular expression must be written as \x20 or \s.  The action >name
+enters the mode with given name after the rule's token while < returns
+to the mode which was entered before.  The first mode is the mode a
+lexer starts in; rules preceding the first mode belong to the mode
+"default".  Modes let tokens like raw strings or block comments span
+several lines.
+
+Source text is lexed line by line.  At each position the rules of the
+current mode are tried in the order of their definition and the first
+rule matching a non-empty text produces the next token.  Runes no rule
+matches are reported as tokens of the class Unknown.  Since a line's
+tokens only depend on the line and on the modes entered at its
+beginning, lexed lines are re-lexed incrementally after a modification
+(see Lexed.Update).
+*/
+package lex
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Unknown is the class of tokens of runes which aren't matched by any
+// rule of the current mode.
+const Unknown = "unknown"
+
+// DefaultMode is the name of the mode of the rules preceding the first
+// mode of a definition.
+const DefaultMode = "default"
+
+// ErrDefinition is returned for an invalid lexer definition.
+var ErrDefinition = errors.New("gini: lex: definition")
+
+// Token is a lexed token.  Lines and columns are zero-based while
+// columns count runes.
+type Token struct {
+
+	// Class is the name of the token's rule or Unknown.
+	Class string
+
+	// Ln is the index of the token's line.
+	Ln int
+
+	// Cl is the column of the token's first rune.
+	Cl int
+
+	// Len is the number of runes of the token.
+	Len int
+
+	// Text is the lexed text.
+	Text string
+}
+
+// Lexer lexes text according to a definition (see Compile).
+type Lexer struct {
+	modes   map[string]*mode
+	initial *mode
+}
+
+type mode struct {
+	name  string
+	rules []*rule
+}
+
+type rule struct {
+	class string
+	re    *regexp.Regexp
+	enter string
+	leave bool
+	mode  *mode
+}
+
+// Load returns the lexer defined in the file with given path.
+func Load(path string) (*Lexer, error) {
+	bb, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("gini: lex: load: %w", err)
+	}
+	return Compile(string(bb))
+}
+
+// Compile returns the lexer of given definition def or an error
+// wrapping ErrDefinition if def is invalid.
+func Compile(def string) (*Lexer, error) {
+	l := &Lexer{modes: map[string]*mode{}}
+	var current *mode
+	for i, line := range strings.Split(def, "\n") {
+		ff := strings.Fields(line)
+		if len(ff) == 0 || strings.HasPrefix(ff[0], "#") {
+			continue
+		}
+		if name, ok := header(line); ok {
+			if _, ok := l.modes[name]; ok {
+				return nil, definitionErr(i, "mode redefined: "+name)
+			}
+			current = l.mode(name)
+			continue
+		}
+		if current == nil {
+			current = l.mode(DefaultMode)
+		}
+		r, err := newRule(ff)
+		if err != nil {
+			return nil, definitionErr(i, err.Error())
+		}
+		current.rules = append(current.rules, r)
+	}
+	if l.initial == nil {
+		return nil, definitionErr(0, "no rules")
+	}
+	for _, m := range l.modes {
+		for _, r := range m.rules {
+			if r.enter == "" {
+				continue
+			}
+			if r.mode = l.modes[r.enter]; r.mode == nil {
+				return nil, fmt.Errorf("%w: unknown mode: %s",
+					ErrDefinition, r.enter)
+			}
+		}
+	}
+	return l, nil
+}
+
+// mode adds a mode with given name to given lexer l which becomes l's
+// initial mode if it is its first mode.
+func (l *Lexer) mode(name string) *mode {
+	m := &mode{name: name}
+	l.modes[name] = m
+	if l.initial == nil {
+		l.initial = m
+	}
+	return m
+}
+
+// header returns the name of the mode started by given definition line
+// and false if it doesn't start a mode.
+func header(line string) (string, bool) {
+	line = strings.TrimSpace(line)
+	if len(line) < 3 || line[0] != '[' || line[len(line)-1] != ']' {
+		return "", false
+	}
+	name := strings.TrimSpace(line[1 : len(line)-1])
+	return name, name != "" && !strings.ContainsAny(name, " \t")
+}
+
+// newRule returns the rule of given fields ff of a definition line.
+func newRule(ff []string) (*rule, error) {
+	if len(ff) < 2 || len(ff) > 3 {
+		return nil, errors.New("rule needs class, expression " +
+			"and optional action")
+	}
+	re, err := regexp.Compile(`^(?:` + ff[1] + `)`)
+	if err != nil {
+		return nil, err
+	}
+	r := &rule{class: ff[0], re: re}
+	if len(ff) == 2 {
+		return r, nil
+	}
+	switch action := ff[2]; {
+	case action == "<":
+		r.leave = true
+	case len(action) > 1 && action[0] == '>':
+		r.enter = action[1:]
+	default:
+		return nil, errors.New("invalid action: " + action)
+	}
+	return r, nil
+}
+
+func definitionErr(ln int, msg string) error {
+	return fmt.Errorf("%w: line %d: %s", ErrDefinition, ln+1, msg)
+}
+
+// Modes returns the names of given lexer l's modes starting with its
+// initial mode followed by the other modes in alphabetical order.
+func (l *Lexer) Modes() []string {
+	mm := []string{}
+	for name := range l.modes {
+		if name != l.initial.name {
+			mm = append(mm, name)
+		}
+	}
+	sort.Strings(mm)
+	return append([]string{l.initial.name}, mm...)
+}
+
+// Classes returns the token classes of given lexer l's rules without
+// duplicates in the order of l's modes and their rules.
+func (l *Lexer) Classes() (cc []string) {
+	seen := map[string]bool{}
+	for _, name := range l.Modes() {
+		for _, r := range l.modes[name].rules {
+			if !seen[r.class] {
+				seen[r.class] = true
+				cc = append(cc, r.class)
+			}
+		}
+	}
+	return cc
+}
+
+// state is the stack of entered modes whose top is the current mode.
+// States are immutable hence they may be shared between lines.
+type state struct {
+	mode *mode
+	up   *state
+}
+
+// equal returns true if given states s and o have the same modes.
+func (s *state) equal(o *state) bool {
+	for ; s != nil && o != nil; s, o = s.up, o.up {
+		if s == o {
+			return true
+		}
+		if s.mode != o.mode {
+			return false
+		}
+	}
+	return s == o
+}
+
+// line returns the tokens of given line with given index ln lexed in
+// given state s and the state at the line's end.
+func (l *Lexer) line(line string, ln int, s *state) ([]Token, *state) {
+	var tt []Token
+	cl, unknown, unknownCl := 0, -1, 0
+	flush := func(at int) {
+		if unknown < 0 {
+			return
+		}
+		tt = append(tt, Token{Class: Unknown, Ln: ln, Cl: unknownCl,
+			Len: cl - unknownCl, Text: line[unknown:at]})
+		unknown = -1
+	}
+	for at := 0; at < len(line); {
+		r, n := s.mode.match(line[at:])
+		if r == nil {
+			if unknown < 0 {
+				unknown, unknownCl = at, cl
+			}
+			_, size := utf8.DecodeRuneInString(line[at:])
+			at, cl = at+size, cl+1
+			continue
+		}
+		flush(at)
+		text := line[at : at+n]
+		runes := utf8.RuneCountInString(text)
+		tt = append(tt, Token{Class: r.class, Ln: ln, Cl: cl,
+			Len: runes, Text: text})
+		at, cl = at+n, cl+runes
+		switch {
+		case r.mode != nil:
+			s = &state{mode: r.mode, up: s}
+		case r.leave && s.up != nil:
+			s = s.up
+		}
+	}
+	flush(len(line))
+	return tt, s
+}
+
+// match returns the first rule of given mode m matching a non-empty
+// prefix of given text and the prefix's length in bytes.
+func (m *mode) match(text string) (*rule, int) {
+	for _, r := range m.rules {
+		if loc := r.re.FindStringIndex(text); loc != nil && loc[1] > 0 {
+			return r, loc[1]
+		}
+	}
+	return nil, 0
+}
+
+// Lexed are lexed lines which are re-lexed incrementally on updates.
+type Lexed struct {
+	lexer  *Lexer
+	ll     []string
+	tt     [][]Token
+	states []*state
+	end    *state
+}
+
+// Lex lexes given lines ll.
+func (l *Lexer) Lex(ll []string) *Lexed {
+	x := &Lexed{lexer: l}
+	x.Update(0, 0, ll)
+	return x
+}
+
+// Lines returns the number of lexed lines.
+func (x *Lexed) Lines() int { return len(x.ll) }
+
+// Tokens returns the tokens of the line with given index ln.
+func (x *Lexed) Tokens(ln int) []Token {
+	if ln < 0 || ln >= len(x.tt) {
+		return nil
+	}
+	return x.tt[ln]
+}
+
+// All returns the tokens of all lexed lines in order.
+func (x *Lexed) All() (tt []Token) {
+	for _, lt := range x.tt {
+		tt = append(tt, lt...)
+	}
+	return tt
+}
+
+// Mode returns the name of the mode which is current at the beginning
+// of the line with given index ln, e.g. to find out if a line starts
+// inside a block comment.  Behind the last line the mode at its end is
+// returned.
+func (x *Lexed) Mode(ln int) string {
+	if ln >= 0 && ln < len(x.states) {
+		return x.states[ln].mode.name
+	}
+	if x.end == nil {
+		return x.lexer.initial.name
+	}
+	return x.end.mode.name
+}
+
+// Update replaces given number of lines starting at given line from by
+// given lines ll and re-lexes the replacing lines and the following
+// lines until a line starts in the same state as before the update.
+// The tokens of subsequent lines are moved to their new line indices.
+// Update returns the index behind the last re-lexed line.
+func (x *Lexed) Update(from, removed int, ll []string) (end int) {
+	if from < 0 || from > len(x.ll) {
+		return from
+	}
+	if from+removed > len(x.ll) {
+		removed = len(x.ll) - from
+	}
+	x.ll = splice(x.ll, from, removed, ll)
+	x.tt = splice(x.tt, from, removed, make([][]Token, len(ll)))
+	x.states = splice(x.states, from, removed, make([]*state, len(ll)))
+	if delta := len(ll) - removed; delta != 0 {
+		for _, lt := range x.tt[from+len(ll):] {
+			for i := range lt {
+				lt[i].Ln += delta
+			}
+		}
+	}
+	s := &state{mode: x.lexer.initial}
+	if from > 0 {
+		_, s = x.lexer.line(x.ll[from-1], from-1, x.states[from-1])
+	}
+	for end = from; end < len(x.ll); end++ {
+		if end >= from+len(ll) && s.equal(x.states[end]) {
+			return end
+		}
+		x.states[end] = s
+		x.tt[end], s = x.lexer.line(x.ll[end], end, s)
+	}
+	x.end = s
+	return end
+}
+
+// splice returns a copy of given slice ss whose given number n of
+// elements starting at given index at are replaced by given elements
+// with.
+func splice[T any](ss []T, at, n int, with []T) []T {
+	spliced := make([]T, 0, len(ss)-n+len(with))
+	spliced = append(append(spliced, ss[:at]...), with...)
+	return append(spliced, ss[at+n:]...)
+}
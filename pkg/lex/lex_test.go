@@ -0,0 +1,147 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package lex
+
+import (
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	. "github.com/slukits/gounit"
+)
+
+type _Lexer struct{ Suite }
+
+func (s *_Lexer) SetUp(t *T) { t.Parallel() }
+
+// goDef defines a lexer of identifiers, numbers, line comments and
+// strings whose raw strings may span several lines.
+const goDef = "# go\n[code]\n" +
+	"comment //.*\n" +
+	"string  `  >raw\n" +
+	"string  \"  >string\n" +
+	"space   \\s+\n" +
+	"ident   [\\pL_][\\pL\\pN_]*\n" +
+	"number  \\d+\n" +
+	"\n[raw]\n" +
+	"string  `  <\n" +
+	"string  [^`]+\n" +
+	"\n[string]\n" +
+	"string  \"  <\n" +
+	"escape  \\\\.\n" +
+	"string  [^\"\\\\]+\n"
+
+// classesOf returns the classes of given tokens tt.
+func classesOf(tt []Token) (cc []string) {
+	for _, t := range tt {
+		cc = append(cc, t.Class)
+	}
+	return cc
+}
+
+func (s *_Lexer) Is_compiled_from_its_definition(t *T) {
+	l, err := Compile(goDef)
+	t.FatalOn(err)
+	t.Eq([]string{"code", "raw", "string"}, l.Modes())
+	t.Eq([]string{"comment", "string", "space", "ident", "number",
+		"escape"}, l.Classes())
+	l, err = Compile("ident \\w+")
+	t.FatalOn(err)
+	t.Eq([]string{DefaultMode}, l.Modes())
+}
+
+func (s *_Lexer) Fails_on_invalid_definitions(t *T) {
+	for _, def := range []string{
+		"", "# only a comment", "ident", "ident ( ", "ident \\w+ !",
+		"ident \\w+ >unknown", "[a]\nx y\n[a]\nz y",
+	} {
+		_, err := Compile(def)
+		t.ErrIs(err, ErrDefinition)
+	}
+	_, err := Compile("ok x\n\nbad (")
+	t.ErrMatched(err, "line 3")
+}
+
+func (s *_Lexer) Lexes_lines_into_tokens_with_positions(t *T) {
+	l, err := Compile(goDef)
+	t.FatalOn(err)
+	x := l.Lex([]string{`größe := 42 // answer`, `"a\"b"`})
+	tt := x.Tokens(0)
+	t.Eq([]string{"ident", "space", Unknown, "space", "number",
+		"space", "comment"}, classesOf(tt))
+	t.Eq(Token{Class: Unknown, Ln: 0, Cl: 6, Len: 2, Text: ":="},
+		tt[2])
+	t.Eq(Token{Class: "number", Ln: 0, Cl: 9, Len: 2, Text: "42"},
+		tt[4])
+	t.Eq([]string{"string", "string", "escape", "string", "string"},
+		classesOf(x.Tokens(1)))
+	t.Eq(1, x.Tokens(1)[4].Ln)
+	t.Eq(12, len(x.All()))
+	t.Eq(0, len(x.Tokens(2)))
+}
+
+func (s *_Lexer) Lexes_modes_spanning_lines(t *T) {
+	l, err := Compile(goDef)
+	t.FatalOn(err)
+	x := l.Lex([]string{"a := `raw", "42", "` + 1"})
+	t.Eq("code", x.Mode(0))
+	t.Eq("raw", x.Mode(1))
+	t.Eq("raw", x.Mode(2))
+	t.Eq("code", x.Mode(3))
+	t.Eq([]string{"string"}, classesOf(x.Tokens(1)))
+	t.Eq([]string{"string", "space", Unknown, "space", "number"},
+		classesOf(x.Tokens(2)))
+}
+
+func (s *_Lexer) Relexes_updated_lines_incrementally(t *T) {
+	l, err := Compile(goDef)
+	t.FatalOn(err)
+	x := l.Lex([]string{"a", "b", "c", "d"})
+	t.Eq(2, x.Update(1, 1, []string{"42"}))
+	t.Eq([]string{"number"}, classesOf(x.Tokens(1)))
+	t.Eq(6, x.Update(1, 0, []string{"`", "x"}))
+	t.Eq(6, x.Lines())
+	t.Eq([]string{"string"}, classesOf(x.Tokens(5)))
+	t.Eq(5, x.Tokens(5)[0].Ln)
+	t.Eq("raw", x.Mode(6))
+	t.Eq(6, x.Update(2, 1, []string{"y`"}))
+	t.Eq([]string{"ident"}, classesOf(x.Tokens(5)))
+	t.Eq("code", x.Mode(6))
+	t.Eq(1, x.Update(1, 2, nil))
+	t.Eq(4, x.Lines())
+	t.Eq(3, x.Tokens(3)[0].Ln)
+}
+
+func (s *_Lexer) Is_loaded_from_a_definition_file(t *T) {
+	d := t.FS().Tmp()
+	d.MkFile("go.lex", []byte(goDef))
+	l, err := Load(filepath.Join(d.Path(), "go.lex"))
+	t.FatalOn(err)
+	t.Eq("code", l.Modes()[0])
+	_, err = Load(filepath.Join(d.Path(), "none.lex"))
+	t.ErrIs(err, fs.ErrNotExist)
+}
+
+func TestLexer(t *testing.T) {
+	t.Parallel()
+	Run(&_Lexer{}, t)
+}
@@ -0,0 +1,447 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+/*
+Package parse provides a generic parser engine whose parsers are built
+at runtime from a grammar.  A grammar is given in an EBNF-like notation
+whose productions are terminated by a dot:
+
+	// a list of declarations
+	%skip space comment
+
+	File  = { Decl } .
+	Decl  = "var" ident "=" Expr ";" .
+	Expr  = Term { ( "+" | "-" ) Term } .
+	Term  = ident | number | "(" Expr ")" .
+
+A production's expression is made of alternatives separated by "|",
+sequences, groups "( )", options "[ ]" and repetitions "{ }".  A quoted
+literal matches a token with the same text while a name which isn't
+defined by a production matches a token of the lexer class with this
+name (see lex.Token).  The first production is the start production.
+The directive %skip lists the classes of tokens which are ignored by
+the parser, e.g. white space and comments; // starts a comment in a
+grammar.  Left recursive productions are rejected.
+
+Parsing is done by ordered choice, i.e. the first alternative matching
+the tokens wins, and results in a concrete syntax tree of productions
+and tokens with positions.  Tokens which can't be parsed don't stop the
+parser; instead it recovers by adding error nodes to the tree:  a
+sequence which has consumed tokens reports its missing parts and
+continues while a start production like File, which is a repetition,
+skips tokens it can't parse.  Hence a partial tree stays usable while
+its source is being typed.  The tree of a start production which is a
+repetition is reparsed incrementally after modifications of its
+source (see Grammar.Reparse).
+*/
+package parse
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/slukits/gini/pkg/lex"
+)
+
+// ErrGrammar is returned for an invalid grammar.
+var ErrGrammar = errors.New("gini: parse: grammar")
+
+// notation lexes the notation of grammars.
+var notation = func() *lex.Lexer {
+	l, err := lex.Compile(`[ebnf]
+comment    //.*
+space      \s+
+directive  %[a-z]+
+name       [\pL_][\pL\pN_]*
+literal    "(?:[^"\\]|\\.)*"
+symbol     [=|()\[\]{}.]
+`)
+	if err != nil {
+		panic(err)
+	}
+	return l
+}()
+
+// Grammar parses tokens into concrete syntax trees (see Compile).
+type Grammar struct {
+	start *production
+	prods map[string]*production
+	skip  map[string]bool
+
+	// item is the repeated expression of a start production which is a
+	// repetition.
+	item *expr
+}
+
+type production struct {
+	name string
+	expr *expr
+}
+
+type exprKind int
+
+const (
+	seqExpr exprKind = iota
+	altExpr
+	optExpr
+	repExpr
+	refExpr
+	classExpr
+	literalExpr
+)
+
+// expr is an expression of a production.
+type expr struct {
+	kind exprKind
+
+	// name is the name of a referenced production or token class or
+	// the text of a literal.
+	name string
+
+	// prod is the referenced production.
+	prod *production
+
+	xx []*expr
+}
+
+// Load returns the grammar defined in the file with given path.
+func Load(path string) (*Grammar, error) {
+	bb, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("gini: parse: load: %w", err)
+	}
+	return Compile(string(bb))
+}
+
+// Compile returns the grammar of given definition def or an error
+// wrapping ErrGrammar if def is invalid.
+func Compile(def string) (*Grammar, error) {
+	c := &compiler{g: &Grammar{prods: map[string]*production{},
+		skip: map[string]bool{}}}
+	for _, t := range notation.Lex(strings.Split(def, "\n")).All() {
+		switch t.Class {
+		case "space", "comment":
+		case lex.Unknown:
+			return nil, grammarErr(t, "unexpected "+strconv.Quote(t.Text))
+		default:
+			c.tt = append(c.tt, t)
+		}
+	}
+	if err := c.grammar(); err != nil {
+		return nil, err
+	}
+	return c.g, nil
+}
+
+func grammarErr(t lex.Token, msg string) error {
+	return fmt.Errorf("%w: line %d: %s", ErrGrammar, t.Ln+1, msg)
+}
+
+// compiler compiles the tokens of a grammar's definition.
+type compiler struct {
+	g   *Grammar
+	tt  []lex.Token
+	pos int
+}
+
+func (c *compiler) peek() (lex.Token, bool) {
+	if c.pos >= len(c.tt) {
+		return lex.Token{}, false
+	}
+	return c.tt[c.pos], true
+}
+
+// next consumes the next token if it is the given symbol.
+func (c *compiler) next(symbol string) bool {
+	t, ok := c.peek()
+	if !ok || t.Class != "symbol" || t.Text != symbol {
+		return false
+	}
+	c.pos++
+	return true
+}
+
+// expected returns the error of a missing token.
+func (c *compiler) expected(what string) error {
+	t, ok := c.peek()
+	if !ok {
+		return fmt.Errorf("%w: expected %s at end", ErrGrammar, what)
+	}
+	return grammarErr(t, fmt.Sprintf("expected %s got %q", what, t.Text))
+}
+
+// grammar compiles the directives and productions of a grammar.
+func (c *compiler) grammar() error {
+	for {
+		t, ok := c.peek()
+		if !ok {
+			break
+		}
+		var err error
+		switch t.Class {
+		case "directive":
+			err = c.directive(t)
+		case "name":
+			err = c.production(t)
+		default:
+			err = c.expected("production")
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if c.g.start == nil {
+		return fmt.Errorf("%w: no productions", ErrGrammar)
+	}
+	for _, p := range c.g.prods {
+		resolve(c.g, p.expr)
+	}
+	if err := c.g.leftRecursion(); err != nil {
+		return err
+	}
+	if x := c.g.start.expr; x.kind == repExpr {
+		c.g.item = x.xx[0]
+	}
+	return nil
+}
+
+// directive compiles the directive starting with given token t whose
+// arguments are the names in t's line.
+func (c *compiler) directive(t lex.Token) error {
+	if t.Text != "%skip" {
+		return grammarErr(t, "unknown directive "+t.Text)
+	}
+	c.pos++
+	for {
+		a, ok := c.peek()
+		if !ok || a.Ln != t.Ln || a.Class != "name" {
+			return nil
+		}
+		c.g.skip[a.Text] = true
+		c.pos++
+	}
+}
+
+// production compiles the production whose name is given token t.
+func (c *compiler) production(t lex.Token) error {
+	if _, ok := c.g.prods[t.Text]; ok {
+		return grammarErr(t, "production redefined: "+t.Text)
+	}
+	c.pos++
+	if !c.next("=") {
+		return c.expected(`"="`)
+	}
+	x, err := c.expression()
+	if err != nil {
+		return err
+	}
+	if !c.next(".") {
+		return c.expected(`"."`)
+	}
+	p := &production{name: t.Text, expr: x}
+	c.g.prods[p.name] = p
+	if c.g.start == nil {
+		c.g.start = p
+	}
+	return nil
+}
+
+// expression compiles alternatives of sequences.
+func (c *compiler) expression() (*expr, error) {
+	alt := &expr{kind: altExpr}
+	for {
+		seq, err := c.sequence()
+		if err != nil {
+			return nil, err
+		}
+		alt.xx = append(alt.xx, seq)
+		if !c.next("|") {
+			break
+		}
+	}
+	if len(alt.xx) == 1 {
+		return alt.xx[0], nil
+	}
+	return alt, nil
+}
+
+// sequence compiles a possibly empty sequence of factors.
+func (c *compiler) sequence() (*expr, error) {
+	seq := &expr{kind: seqExpr}
+	for {
+		x, err := c.factor()
+		if err != nil {
+			return nil, err
+		}
+		if x == nil {
+			break
+		}
+		seq.xx = append(seq.xx, x)
+	}
+	if len(seq.xx) == 1 {
+		return seq.xx[0], nil
+	}
+	return seq, nil
+}
+
+// groups maps the opening symbol of a group, an option or a repetition
+// to its closing symbol and its kind.
+var groups = map[string]struct {
+	close string
+	kind  exprKind
+}{"(": {")", seqExpr}, "[": {"]", optExpr}, "{": {"}", repExpr}}
+
+// factor compiles a name, a literal, a group, an option or a repetition
+// and returns nil if there is none.
+func (c *compiler) factor() (*expr, error) {
+	t, ok := c.peek()
+	if !ok {
+		return nil, nil
+	}
+	switch t.Class {
+	case "name":
+		c.pos++
+		return &expr{kind: refExpr, name: t.Text}, nil
+	case "literal":
+		c.pos++
+		s, err := strconv.Unquote(t.Text)
+		if err != nil || s == "" {
+			return nil, grammarErr(t, "invalid literal "+t.Text)
+		}
+		return &expr{kind: literalExpr, name: s}, nil
+	}
+	group, ok := groups[t.Text]
+	if !ok {
+		return nil, nil
+	}
+	c.pos++
+	x, err := c.expression()
+	if err != nil {
+		return nil, err
+	}
+	if !c.next(group.close) {
+		return nil, c.expected(strconv.Quote(group.close))
+	}
+	if group.kind == seqExpr {
+		return x, nil
+	}
+	return &expr{kind: group.kind, xx: []*expr{x}}, nil
+}
+
+// resolve resolves the references of given expression x to productions
+// of given grammar g or to token classes.
+func resolve(g *Grammar, x *expr) {
+	if x.kind == refExpr {
+		if x.prod = g.prods[x.name]; x.prod == nil {
+			x.kind = classExpr
+		}
+		return
+	}
+	for _, x := range x.xx {
+		resolve(g, x)
+	}
+}
+
+// nullable returns true if given expression x matches without tokens.
+// Productions are assumed to be not nullable while they are evaluated
+// by given map of visited productions.
+func nullable(x *expr, visited map[*production]bool) bool {
+	switch x.kind {
+	case optExpr, repExpr:
+		return true
+	case classExpr, literalExpr:
+		return false
+	case refExpr:
+		if visited[x.prod] {
+			return false
+		}
+		visited[x.prod] = true
+		defer delete(visited, x.prod)
+		return nullable(x.prod.expr, visited)
+	case altExpr:
+		for _, x := range x.xx {
+			if nullable(x, visited) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, x := range x.xx {
+		if !nullable(x, visited) {
+			return false
+		}
+	}
+	return true
+}
+
+// leftmost calls given function fn for each production which given
+// expression x may reference before it consumes a token.
+func leftmost(x *expr, fn func(*production)) {
+	switch x.kind {
+	case refExpr:
+		fn(x.prod)
+	case seqExpr:
+		for _, x := range x.xx {
+			leftmost(x, fn)
+			if !nullable(x, map[*production]bool{}) {
+				return
+			}
+		}
+	case altExpr, optExpr, repExpr:
+		for _, x := range x.xx {
+			leftmost(x, fn)
+		}
+	}
+}
+
+// leftRecursion returns an error if a production of given grammar g
+// may reference itself before it consumes a token.
+func (g *Grammar) leftRecursion() error {
+	for _, p := range g.prods {
+		visited := map[*production]bool{}
+		var visit func(*production) bool
+		visit = func(q *production) (recursive bool) {
+			if q == p {
+				return true
+			}
+			if visited[q] {
+				return false
+			}
+			visited[q] = true
+			leftmost(q.expr, func(r *production) {
+				recursive = recursive || visit(r)
+			})
+			return recursive
+		}
+		recursive := false
+		leftmost(p.expr, func(r *production) {
+			recursive = recursive || visit(r)
+		})
+		if recursive {
+			return fmt.Errorf("%w: left recursive: %s", ErrGrammar,
+				p.name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package parse
+
+import (
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	. "github.com/slukits/gounit"
+)
+
+type _Grammar struct{ Suite }
+
+func (s *_Grammar) SetUp(t *T) { t.Parallel() }
+
+// declsDef defines a grammar of variable declarations whose start
+// production is a repetition.
+const declsDef = `// declarations
+%skip space comment
+
+File = { Decl } .
+Decl = "var" ident "=" Expr ";" .
+Expr = Term { ( "+" | "-" ) Term } .
+Term = ident | number | "(" Expr ")" .
+`
+
+func (s *_Grammar) Is_compiled_from_its_definition(t *T) {
+	g, err := Compile(declsDef)
+	t.FatalOn(err)
+	t.Eq("File", g.start.name)
+	t.Eq(4, len(g.prods))
+	t.True(g.skip["space"] && g.skip["comment"])
+	t.Eq(refExpr, g.item.kind)
+	t.Eq(classExpr, g.prods["Decl"].expr.xx[1].kind)
+	t.Eq(literalExpr, g.prods["Decl"].expr.xx[0].kind)
+	t.Eq(altExpr, g.prods["Term"].expr.kind)
+}
+
+func (s *_Grammar) Fails_on_invalid_definitions(t *T) {
+	for _, def := range []string{
+		"", "// nothing", "A = x", "A x .", "A = ( x .", "A = [ x ) .",
+		"A = x .\nA = y .", "A = \"\" .", "%unknown x", "A = x ! .",
+		"= x .",
+	} {
+		_, err := Compile(def)
+		t.ErrIs(err, ErrGrammar)
+	}
+	_, err := Compile("A = x .\n\nB = ( .")
+	t.ErrMatched(err, "line 3")
+}
+
+func (s *_Grammar) Rejects_left_recursion(t *T) {
+	for _, def := range []string{
+		"A = A x .", "A = [ y ] A .", "A = B | x .\nB = { y } A z .",
+	} {
+		_, err := Compile(def)
+		t.ErrMatched(err, "left recursive")
+	}
+	_, err := Compile("A = x A | y .")
+	t.FatalOn(err)
+}
+
+func (s *_Grammar) Is_loaded_from_a_definition_file(t *T) {
+	d := t.FS().Tmp()
+	d.MkFile("decls.ebnf", []byte(declsDef))
+	g, err := Load(filepath.Join(d.Path(), "decls.ebnf"))
+	t.FatalOn(err)
+	t.Eq("File", g.start.name)
+	_, err = Load(filepath.Join(d.Path(), "none.ebnf"))
+	t.ErrIs(err, fs.ErrNotExist)
+}
+
+func TestGrammar(t *testing.T) {
+	t.Parallel()
+	Run(&_Grammar{}, t)
+}
@@ -0,0 +1,441 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package parse
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/slukits/gini/pkg/lex"
+)
+
+// Kind classifies the nodes of a syntax tree.
+type Kind int
+
+const (
+
+	// Production is a node of a production holding the nodes of its
+	// expression.
+	Production Kind = iota
+
+	// Token is a leaf of a parsed token.
+	Token
+
+	// Error reports a missing or an unexpected part of the source.
+	Error
+)
+
+var kinds = []string{"production", "token", "error"}
+
+// String returns the name of given kind k.
+func (k Kind) String() string {
+	if k < 0 || int(k) >= len(kinds) {
+		return "unknown"
+	}
+	return kinds[k]
+}
+
+// Node is a node of a concrete syntax tree.
+type Node struct {
+	Kind Kind
+
+	// Name is the name of a production or the class of a token.
+	Name string
+
+	// Text is the text of a token or the message of an error.
+	Text string
+
+	// Ln and Cl are the line and rune column of the node's first rune
+	// while EndLn and EndCl are the line and the column behind its
+	// last rune.  An empty node is positioned at the following token.
+	Ln, Cl, EndLn, EndCl int
+
+	// Children are the nodes of a production.
+	Children []*Node
+}
+
+// Contains returns true if given position is inside given node n.
+func (n *Node) Contains(ln, cl int) bool {
+	switch {
+	case ln < n.Ln || ln > n.EndLn:
+		return false
+	case ln == n.Ln && cl < n.Cl:
+		return false
+	case ln == n.EndLn && cl >= n.EndCl:
+		return false
+	}
+	return true
+}
+
+// At returns the innermost node of given node n's tree containing given
+// position or nil if n doesn't contain it.
+func (n *Node) At(ln, cl int) *Node {
+	if !n.Contains(ln, cl) {
+		return nil
+	}
+	for _, c := range n.Children {
+		if at := c.At(ln, cl); at != nil {
+			return at
+		}
+	}
+	return n
+}
+
+// Walk calls given function fn for given node n and its descendants in
+// document order.
+func (n *Node) Walk(fn func(*Node)) {
+	fn(n)
+	for _, c := range n.Children {
+		c.Walk(fn)
+	}
+}
+
+// Errors returns the error nodes of given node n's tree in document
+// order.
+func (n *Node) Errors() (ee []*Node) {
+	n.Walk(func(n *Node) {
+		if n.Kind == Error {
+			ee = append(ee, n)
+		}
+	})
+	return ee
+}
+
+// shift moves given node n's tree by given number of lines.
+func (n *Node) shift(lines int) {
+	n.Walk(func(n *Node) {
+		n.Ln, n.EndLn = n.Ln+lines, n.EndLn+lines
+	})
+}
+
+// Parse parses given tokens into the syntax tree of g's start
+// production.  Tokens of skipped classes are ignored.
+func (g *Grammar) Parse(tt []lex.Token) *Node {
+	p := g.parser(tt)
+	if g.item != nil {
+		return p.root(p.items(0, nil, nil))
+	}
+	n, end, ok := p.production(g.start, 0, false)
+	if !ok || end < len(p.tt) {
+		n, end, ok = p.production(g.start, 0, true)
+	}
+	if !ok {
+		n, end = p.root([]*Node{p.missing(g.start.name, 0)}), 0
+	}
+	if end < len(p.tt) {
+		n.Children = append(n.Children, p.unexpected(end, len(p.tt)))
+		p.spanNodes(n)
+	}
+	return n
+}
+
+// Reparse parses given tokens of a modified source whose previous
+// syntax tree is given tree old.  The tokens of the lines from given
+// line from to the line before given line to have changed while the
+// number of lines changed by given delta (see lex.Lexed.Update).  Only
+// the nodes of the start production's repetition around the modified
+// lines are parsed again while the other nodes of old are reused.  If
+// the start production isn't a repetition the tokens are parsed from
+// scratch.  Note that old mustn't be used after it was reparsed.
+func (g *Grammar) Reparse(
+	old *Node, tt []lex.Token, from, to, delta int,
+) *Node {
+	if g.item == nil || old == nil || old.Name != g.start.name {
+		return g.Parse(tt)
+	}
+	p, kk := g.parser(tt), old.Children
+	keep := 0
+	for keep < len(kk) && kk[keep].EndLn < from &&
+		len(kk[keep].Errors()) == 0 {
+		keep++
+	}
+	if keep > 0 {
+		// the previous node may have looked ahead into the modification
+		keep--
+	}
+	pos := 0
+	if keep > 0 {
+		pos = p.after(kk[keep-1].EndLn, kk[keep-1].EndCl)
+	}
+	suffix := map[[2]int]int{}
+	for i := keep; i < len(kk); i++ {
+		if kk[i].Ln+delta >= to && len(kk[i].Errors()) == 0 {
+			suffix[[2]int{kk[i].Ln + delta, kk[i].Cl}] = i
+		}
+	}
+	nn := append([]*Node{}, kk[:keep]...)
+	return p.root(p.items(pos, nn, func(pos int) []*Node {
+		t := p.tt[pos]
+		i, ok := suffix[[2]int{t.Ln, t.Cl}]
+		if !ok {
+			return nil
+		}
+		for _, k := range kk[i:] {
+			k.shift(delta)
+		}
+		return kk[i:]
+	}))
+}
+
+// memoKey identifies the parse of a production at a token position.
+type memoKey struct {
+	prod    *production
+	pos     int
+	recover bool
+}
+
+type memoed struct {
+	n   *Node
+	end int
+	ok  bool
+}
+
+// parser parses the not skipped tokens of a source.
+type parser struct {
+	g    *Grammar
+	tt   []lex.Token
+	memo map[memoKey]memoed
+}
+
+func (g *Grammar) parser(tt []lex.Token) *parser {
+	p := &parser{g: g, memo: map[memoKey]memoed{}}
+	for _, t := range tt {
+		if !g.skip[t.Class] {
+			p.tt = append(p.tt, t)
+		}
+	}
+	return p
+}
+
+// after returns the index of the first token at or behind given
+// position.
+func (p *parser) after(ln, cl int) int {
+	for i, t := range p.tt {
+		if t.Ln > ln || t.Ln == ln && t.Cl >= cl {
+			return i
+		}
+	}
+	return len(p.tt)
+}
+
+// root returns the node of the start production holding given nodes.
+func (p *parser) root(nn []*Node) *Node {
+	n := &Node{Kind: Production, Name: p.g.start.name, Children: nn}
+	p.spanNodes(n)
+	return n
+}
+
+// items parses the repeated items of a start production from given
+// token position pos on appending them to given nodes nn.  Each item is
+// parsed strictly first and with recovery if that fails; tokens which
+// don't start an item are skipped.  Given function reuse is called
+// before each item and stops the parsing if it returns the nodes of the
+// remaining items.
+func (p *parser) items(
+	pos int, nn []*Node, reuse func(int) []*Node,
+) []*Node {
+	for pos < len(p.tt) {
+		if reuse != nil {
+			if rest := reuse(pos); rest != nil {
+				return append(nn, rest...)
+			}
+		}
+		mm, end, ok := p.parse(p.g.item, pos, false)
+		if !ok || end == pos {
+			mm, end, ok = p.parse(p.g.item, pos, true)
+		}
+		if !ok || end == pos {
+			nn, pos = append(nn, p.unexpected(pos, pos+1)), pos+1
+			continue
+		}
+		nn, pos = append(nn, mm...), end
+	}
+	return nn
+}
+
+// parse parses given expression x at given token position pos and
+// returns the parsed nodes, the position behind them and false if x
+// doesn't match.  If recover is true a sequence which consumed tokens
+// doesn't fail but reports its missing parts by error nodes.
+func (p *parser) parse(x *expr, pos int, recover bool) (
+	nn []*Node, end int, ok bool,
+) {
+	switch x.kind {
+	case literalExpr, classExpr:
+		if pos >= len(p.tt) {
+			return nil, pos, false
+		}
+		t := p.tt[pos]
+		if x.kind == literalExpr && t.Text != x.name ||
+			x.kind == classExpr && t.Class != x.name {
+			return nil, pos, false
+		}
+		n := &Node{Kind: Token, Name: t.Class, Text: t.Text, Ln: t.Ln,
+			Cl: t.Cl, EndLn: t.Ln, EndCl: t.Cl + t.Len}
+		return []*Node{n}, pos + 1, true
+	case refExpr:
+		n, end, ok := p.production(x.prod, pos, recover)
+		if !ok {
+			return nil, pos, false
+		}
+		return []*Node{n}, end, true
+	case optExpr:
+		nn, end, ok = p.parse(x.xx[0], pos, recover)
+		if !ok {
+			return nil, pos, true
+		}
+		return nn, end, true
+	case repExpr:
+		for end = pos; ; {
+			mm, next, ok := p.parse(x.xx[0], end, recover)
+			if !ok || next == end {
+				return nn, end, true
+			}
+			nn, end = append(nn, mm...), next
+		}
+	case altExpr:
+		for _, x := range x.xx {
+			if nn, end, ok = p.parse(x, pos, false); ok {
+				return nn, end, true
+			}
+		}
+		if !recover {
+			return nil, pos, false
+		}
+		for _, x := range x.xx {
+			if nn, end, ok = p.parse(x, pos, true); ok && end > pos {
+				return nn, end, true
+			}
+		}
+		return nil, pos, false
+	}
+	end = pos
+	for _, x := range x.xx {
+		mm, next, ok := p.parse(x, end, recover)
+		if !ok {
+			if !recover || end == pos {
+				return nil, pos, false
+			}
+			mm, next = []*Node{p.missing(describe(x), end)}, end
+		}
+		nn, end = append(nn, mm...), next
+	}
+	return nn, end, true
+}
+
+// production parses given production at given token position pos
+// remembering the outcome.
+func (p *parser) production(prod *production, pos int, recover bool) (
+	*Node, int, bool,
+) {
+	key := memoKey{prod: prod, pos: pos, recover: recover}
+	if m, ok := p.memo[key]; ok {
+		return m.n, m.end, m.ok
+	}
+	nn, end, ok := p.parse(prod.expr, pos, recover)
+	var n *Node
+	if ok {
+		n = &Node{Kind: Production, Name: prod.name, Children: nn}
+		p.span(n, pos, end)
+	}
+	p.memo[key] = memoed{n: n, end: end, ok: ok}
+	return n, end, ok
+}
+
+// span sets the position of given node n to the tokens from given
+// position pos to the token before given position end.
+func (p *parser) span(n *Node, pos, end int) {
+	if end <= pos {
+		n.Ln, n.Cl = p.at(pos)
+		n.EndLn, n.EndCl = n.Ln, n.Cl
+		return
+	}
+	first, last := p.tt[pos], p.tt[end-1]
+	n.Ln, n.Cl = first.Ln, first.Cl
+	n.EndLn, n.EndCl = last.Ln, last.Cl+last.Len
+}
+
+// spanNodes sets the position of given node n to the span of its
+// children.
+func (p *parser) spanNodes(n *Node) {
+	if len(n.Children) == 0 {
+		n.Ln, n.Cl = p.at(0)
+		n.EndLn, n.EndCl = n.Ln, n.Cl
+		return
+	}
+	first, last := n.Children[0], n.Children[len(n.Children)-1]
+	n.Ln, n.Cl, n.EndLn, n.EndCl = first.Ln, first.Cl, last.EndLn,
+		last.EndCl
+}
+
+// at returns the position of the token at given index pos or the
+// position behind the last token.
+func (p *parser) at(pos int) (ln, cl int) {
+	switch {
+	case pos < len(p.tt):
+		return p.tt[pos].Ln, p.tt[pos].Cl
+	case len(p.tt) > 0:
+		last := p.tt[len(p.tt)-1]
+		return last.Ln, last.Cl + last.Len
+	}
+	return 0, 0
+}
+
+// missing returns the error node of given missing part at given token
+// position pos.
+func (p *parser) missing(part string, pos int) *Node {
+	n := &Node{Kind: Error, Text: "expected " + part}
+	p.span(n, pos, pos)
+	return n
+}
+
+// unexpected returns the error node of the unexpected tokens from given
+// position pos to the token before given position end.
+func (p *parser) unexpected(pos, end int) *Node {
+	n := &Node{Kind: Error, Text: "unexpected " + strconv.Quote(
+		p.tt[pos].Text)}
+	p.span(n, pos, end)
+	return n
+}
+
+// describe returns the description of given expression x in error
+// messages.
+func describe(x *expr) string {
+	switch x.kind {
+	case literalExpr:
+		return strconv.Quote(x.name)
+	case classExpr:
+		return x.name
+	case refExpr:
+		return x.prod.name
+	case altExpr:
+		dd := make([]string, len(x.xx))
+		for i, x := range x.xx {
+			dd[i] = describe(x)
+		}
+		return strings.Join(dd, " or ")
+	}
+	if len(x.xx) == 0 {
+		return "nothing"
+	}
+	return describe(x.xx[0])
+}
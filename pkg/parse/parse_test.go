@@ -0,0 +1,173 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package parse
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/slukits/gini/pkg/lex"
+	. "github.com/slukits/gounit"
+)
+
+type _Parser struct{ Suite }
+
+func (s *_Parser) SetUp(t *T) { t.Parallel() }
+
+// declsLex defines a lexer for the tokens of declsDef.
+const declsLex = `comment //.*
+space   \s+
+ident   [a-z]+
+number  \d+
+symbol  [=;+\-()]
+`
+
+// fx returns the grammar of given definition def and the tokens of
+// given source src lexed by the lexer of declsLex.
+func fx(t *T, def, src string) (*Grammar, []lex.Token) {
+	g, err := Compile(def)
+	t.FatalOn(err)
+	l, err := lex.Compile(declsLex)
+	t.FatalOn(err)
+	return g, l.Lex(strings.Split(src, "\n")).All()
+}
+
+// names returns the names of given nodes nn whereas errors are named
+// by their kind.
+func names(nn []*Node) (ss []string) {
+	for _, n := range nn {
+		if n.Kind == Error {
+			ss = append(ss, Error.String())
+			continue
+		}
+		ss = append(ss, n.Name)
+	}
+	return ss
+}
+
+func (s *_Parser) Tokens_into_a_concrete_syntax_tree(t *T) {
+	g, tt := fx(t, declsDef, "var a = 1;\n// b\nvar b = (a + 2);")
+	root := g.Parse(tt)
+	t.Eq(0, len(root.Errors()))
+	t.Eq("File", root.Name)
+	t.Eq([]string{"Decl", "Decl"}, names(root.Children))
+	d := root.Children[1]
+	t.Eq([]string{"ident", "ident", "symbol", "Expr", "symbol"},
+		names(d.Children))
+	t.Eq(2, d.Ln)
+	t.Eq(0, d.Cl)
+	t.Eq(2, d.EndLn)
+	t.Eq(16, d.EndCl)
+	t.Eq(16, root.EndCl)
+	n := root.At(2, 13)
+	t.Eq(Token, n.Kind)
+	t.Eq("2", n.Text)
+}
+
+func (s *_Parser) Reports_missing_parts_of_started_sequences(t *T) {
+	g, tt := fx(t, declsDef, "var a = ;\nvar b 2;")
+	root := g.Parse(tt)
+	ee := root.Errors()
+	t.Eq(2, len(ee))
+	t.Eq("expected Expr", ee[0].Text)
+	t.Eq(0, ee[0].Ln)
+	t.Eq(8, ee[0].Cl)
+	t.Eq(`expected "="`, ee[1].Text)
+	t.Eq([]string{"Decl", "Decl"}, names(root.Children))
+	t.Eq("b", root.Children[1].Children[1].Text)
+}
+
+func (s *_Parser) Skips_unexpected_tokens_of_a_repetition(t *T) {
+	g, tt := fx(t, declsDef, "= 1 var a = 1; )")
+	root := g.Parse(tt)
+	t.Eq([]string{"error", "error", "Decl", "error"},
+		names(root.Children))
+	t.Eq(`unexpected "="`, root.Children[0].Text)
+	t.Eq(`unexpected ")"`, root.Children[3].Text)
+}
+
+func (s *_Parser) Partial_input_while_typing(t *T) {
+	g, tt := fx(t, declsDef, "var a = (1 +")
+	root := g.Parse(tt)
+	t.Eq([]string{"Decl"}, names(root.Children))
+	ee := root.Errors()
+	t.Eq(3, len(ee))
+	t.Eq("expected Term", ee[0].Text)
+	t.Eq(`expected ")"`, ee[1].Text)
+	t.Eq(`expected ";"`, ee[2].Text)
+	t.Eq(0, ee[2].Ln)
+	t.Eq(12, ee[2].Cl)
+}
+
+func (s *_Parser) Start_productions_which_are_no_repetition(t *T) {
+	g, tt := fx(t, "%skip space\nE = ident \"+\" ident .", "a + b")
+	root := g.Parse(tt)
+	t.Eq("E", root.Name)
+	t.Eq(0, len(root.Errors()))
+	_, tt = fx(t, declsDef, "a + b c")
+	root = g.Parse(tt)
+	t.Eq([]string{"ident", "symbol", "ident", "error"},
+		names(root.Children))
+	_, tt = fx(t, declsDef, "1")
+	root = g.Parse(tt)
+	t.Eq("E", root.Name)
+	t.Eq([]string{"error", "error"}, names(root.Children))
+	t.Eq("expected E", root.Children[0].Text)
+}
+
+func (s *_Parser) Ordered_choice_backtracks(t *T) {
+	g, tt := fx(t, "%skip space\nS = A | B .\n"+
+		"A = \"(\" ident \")\" .\nB = \"(\" number \")\" .", "(1)")
+	root := g.Parse(tt)
+	t.Eq(0, len(root.Errors()))
+	t.Eq([]string{"B"}, names(root.Children))
+}
+
+func (s *_Parser) Reparses_modified_lines_incrementally(t *T) {
+	g, err := Compile(declsDef)
+	t.FatalOn(err)
+	l, err := lex.Compile(declsLex)
+	t.FatalOn(err)
+	x := l.Lex([]string{"var a = 1;", "var b = 2;", "var c = 3;",
+		"var d = 4;"})
+	root := g.Parse(x.All())
+	first, last := root.Children[0], root.Children[3]
+	end := x.Update(2, 1, []string{"var c = (3", "+ 5);"})
+	root = g.Reparse(root, x.All(), 2, end, 1)
+	t.Eq(0, len(root.Errors()))
+	t.Eq([]string{"Decl", "Decl", "Decl", "Decl"}, names(root.Children))
+	t.True(root.Children[0] == first)
+	t.True(root.Children[3] == last)
+	t.Eq(4, last.Ln)
+	t.Eq(4, last.Children[3].Ln)
+	t.Eq(3, root.Children[2].EndLn)
+	end = x.Update(0, 1, []string{"var a ="})
+	root = g.Reparse(root, x.All(), 0, end, 0)
+	t.Eq(`expected ";"`, root.Errors()[0].Text)
+	t.True(root.Children[len(root.Children)-1] == last)
+	t.Eq(names(g.Parse(x.All()).Children), names(root.Children))
+}
+
+func TestParser(t *testing.T) {
+	t.Parallel()
+	Run(&_Parser{}, t)
+}
@@ -0,0 +1,626 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+/*
+Package settings provides a registry of typed settings whose values are
+resolved for a file from layered settings files.  A value may be set for
+a file, for a directory, for a project or globally while the most
+specific value takes precedence over the more general ones which in turn
+take precedence over a setting's default.
+
+Global values and the values of files and directories outside a project
+are stored in the config directory's GlobalFile.  Values of a project,
+i.e. a repository found by dir.Dir.Repo, and of its files and
+directories are stored in the project's ProjectFile.
+*/
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/slukits/gini/pkg/dir"
+	"github.com/slukits/gini/pkg/env"
+	"github.com/slukits/gini/pkg/lg"
+)
+
+const (
+
+	// GlobalFile is the settings file in the config directory.
+	GlobalFile = "settings.json"
+
+	// ProjectFile is the settings file of a project relative to its
+	// root directory.
+	ProjectFile = ".gini/settings.json"
+)
+
+var (
+
+	// ErrUnknown is returned for a setting which isn't registered.
+	ErrUnknown = errors.New("gini: pkg: settings: unknown setting")
+
+	// ErrRegistered is returned by Register for a setting whose name is
+	// already registered.
+	ErrRegistered = errors.New("gini: pkg: settings: already registered")
+
+	// ErrValue is returned for a value which isn't valid for its
+	// setting.
+	ErrValue = errors.New("gini: pkg: settings: invalid value")
+
+	// ErrNoProject is returned by Set for a project value of a file
+	// which isn't inside a project.
+	ErrNoProject = errors.New("gini: pkg: settings: no project")
+
+	// ErrScope is returned by Set for a scope values can't be set in,
+	// i.e. the Default scope.
+	ErrScope = errors.New("gini: pkg: settings: invalid scope")
+)
+
+// Scope is the scope a setting's value is set in.  Scopes are ordered
+// from the most specific to the most general.
+type Scope int
+
+const (
+
+	// File scoped values apply to a single file.
+	File Scope = iota
+
+	// Dir scoped values apply to the files of a directory.
+	Dir
+
+	// Project scoped values apply to the files of a project.
+	Project
+
+	// Global values apply to all files.
+	Global
+
+	// Default is the scope of a setting's default value.
+	Default
+)
+
+// String returns the name of given scope s.
+func (s Scope) String() string {
+	switch s {
+	case File:
+		return "file"
+	case Dir:
+		return "dir"
+	case Project:
+		return "project"
+	case Global:
+		return "global"
+	}
+	return "default"
+}
+
+// ParseScope returns the settable scope with given name.
+func ParseScope(name string) (Scope, bool) {
+	for s := File; s < Default; s++ {
+		if s.String() == name {
+			return s, true
+		}
+	}
+	return Default, false
+}
+
+// Kind is the type of a setting's values.
+type Kind int
+
+const (
+
+	// Int settings have integer values.
+	Int Kind = iota
+
+	// Bool settings have boolean values.
+	Bool
+
+	// String settings have string values.
+	String
+)
+
+// Setting describes a registered setting.
+type Setting struct {
+
+	// Name identifies the setting, e.g. "backups".
+	Name string
+
+	// Doc describes the setting.
+	Doc string
+
+	// Kind is the type of the setting's values.
+	Kind Kind
+
+	// Default is the value of a setting which isn't set in any scope.
+	Default interface{}
+
+	// Check optionally validates a value of the setting's kind.
+	Check func(interface{}) error
+}
+
+// Parse returns given string s as value of given setting s's kind.
+func (s *Setting) Parse(str string) (v interface{}, err error) {
+	switch s.Kind {
+	case Int:
+		v, err = strconv.Atoi(str)
+	case Bool:
+		v, err = strconv.ParseBool(str)
+	default:
+		v = str
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %s", ErrValue, s.Name, str)
+	}
+	return v, s.check(v)
+}
+
+// decode returns given json value raw as value of given setting s's
+// kind.
+func (s *Setting) decode(raw json.RawMessage) (interface{}, error) {
+	var err error
+	var v interface{}
+	switch s.Kind {
+	case Int:
+		var i int
+		err = json.Unmarshal(raw, &i)
+		v = i
+	case Bool:
+		var b bool
+		err = json.Unmarshal(raw, &b)
+		v = b
+	default:
+		var str string
+		err = json.Unmarshal(raw, &str)
+		v = str
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %s", ErrValue, s.Name, raw)
+	}
+	return v, s.check(v)
+}
+
+func (s *Setting) check(v interface{}) error {
+	if s.Check == nil {
+		return nil
+	}
+	if err := s.Check(v); err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrValue, s.Name, err)
+	}
+	return nil
+}
+
+// Value is the effective value of a setting for a file.
+type Value struct {
+
+	// Setting is the setting of the value.
+	Setting *Setting
+
+	// Value is the setting's effective value.
+	Value interface{}
+
+	// Scope is the scope the value is set in.
+	Scope Scope
+
+	// Source is the path of the settings file providing the value or
+	// empty for a default value.
+	Source string
+}
+
+// String returns given value v's value formatted by fmt.
+func (v Value) String() string { return fmt.Sprint(v.Value) }
+
+// Settings registers settings and resolves their values for files.
+// Parsed settings files and found projects are cached until a value is
+// set or Reload is called.  The zero-type is ready to use.
+type Settings struct {
+
+	// Log is a logger for reporting errors it defaults to the
+	// zero-logger.  Log.Env determines the config directory.
+	Log *lg.Logger
+
+	// Lib provides the std-lib functions Settings needs to provide its
+	// features
+	Lib Lib
+
+	settings map[string]*Setting
+	initLib  bool
+
+	// files are the parsed settings files by their paths while projects
+	// are the project roots by the directories of resolved files.
+	files    map[string]*file
+	projects map[string]string
+}
+
+func (ss *Settings) lg() *lg.Logger {
+	if ss.Log == nil {
+		ss.Log = &lg.Logger{}
+	}
+	return ss.Log
+}
+
+func (ss *Settings) env() *env.Env {
+	if ss.lg().Env == nil {
+		ss.Log.Env = &env.Env{}
+	}
+	return ss.Log.Env
+}
+
+func (ss *Settings) lib() Lib {
+	if !ss.initLib {
+		ss.initLib = true
+		if ss.Lib.ReadFile == nil {
+			ss.Lib.ReadFile = ioutil.ReadFile
+		}
+		if ss.Lib.WriteFile == nil {
+			ss.Lib.WriteFile = ioutil.WriteFile
+		}
+		if ss.Lib.MkdirAll == nil {
+			ss.Lib.MkdirAll = os.MkdirAll
+		}
+		if ss.Lib.Stat == nil {
+			ss.Lib.Stat = os.Stat
+		}
+	}
+	return ss.Lib
+}
+
+// Register registers given setting s whose default value must be valid.
+func (ss *Settings) Register(s Setting) error {
+	if _, ok := ss.settings[s.Name]; ok {
+		return fmt.Errorf("%w: %s", ErrRegistered, s.Name)
+	}
+	bb, err := json.Marshal(s.Default)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrValue, s.Name, err)
+	}
+	if _, err := s.decode(bb); err != nil {
+		return err
+	}
+	if ss.settings == nil {
+		ss.settings = map[string]*Setting{}
+	}
+	ss.settings[s.Name] = &s
+	return nil
+}
+
+// Settings returns the registered settings sorted by their names.
+func (ss *Settings) Settings() (sorted []*Setting) {
+	for _, s := range ss.settings {
+		sorted = append(sorted, s)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+// Value returns the effective value of the setting with given name for
+// the file with given path.  Invalid values of settings files are
+// logged to lg.ERR and skipped.  An empty path resolves the global
+// value.
+func (ss *Settings) Value(name, path string) (Value, error) {
+	s, ok := ss.settings[name]
+	if !ok {
+		return Value{}, fmt.Errorf("%w: %s", ErrUnknown, name)
+	}
+	for _, l := range ss.layers(path) {
+		raw, ok := l.values[name]
+		if !ok {
+			continue
+		}
+		v, err := s.decode(raw)
+		if err != nil {
+			ss.lg().Tof(lg.ERR, "%v (%s)", err, l.source)
+			continue
+		}
+		return Value{Setting: s, Value: v, Scope: l.scope,
+			Source: l.source}, nil
+	}
+	return Value{Setting: s, Value: s.Default, Scope: Default}, nil
+}
+
+// Int returns the effective value of the int setting with given name
+// for the file with given path or zero if there is no such setting.
+func (ss *Settings) Int(name, path string) int {
+	v, err := ss.Value(name, path)
+	if err != nil {
+		return 0
+	}
+	i, _ := v.Value.(int)
+	return i
+}
+
+// Bool returns the effective value of the bool setting with given name
+// for the file with given path or false if there is no such setting.
+func (ss *Settings) Bool(name, path string) bool {
+	v, err := ss.Value(name, path)
+	if err != nil {
+		return false
+	}
+	b, _ := v.Value.(bool)
+	return b
+}
+
+// String returns the effective value of the string setting with given
+// name for the file with given path or "" if there is no such setting.
+func (ss *Settings) String(name, path string) string {
+	v, err := ss.Value(name, path)
+	if err != nil {
+		return ""
+	}
+	s, _ := v.Value.(string)
+	return s
+}
+
+// Set parses given value for the setting with given name and stores it
+// in given scope for the file with given path.  File and directory
+// values are stored in the file's project if it is inside a project.
+func (ss *Settings) Set(name, value string, scope Scope, path string) error {
+	s, ok := ss.settings[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknown, name)
+	}
+	if scope < File || scope >= Default {
+		return fmt.Errorf("%w: %s", ErrScope, scope)
+	}
+	v, err := s.Parse(value)
+	if err != nil {
+		return err
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("%w: %s: %v", ErrValue, name, err)
+	}
+	source, root, shared := ss.globalFile(), ss.project(path), false
+	switch {
+	case scope == Project && root == "":
+		return fmt.Errorf("%w: %s", ErrNoProject, path)
+	case scope == Project || scope < Project && root != "":
+		source, shared = filepath.Join(root, ProjectFile), true
+	}
+	f, err := ss.load(source)
+	if err != nil {
+		return err
+	}
+	f.set(scope, ss.key(scope, root, path), name, raw)
+	defer ss.Reload()
+	return ss.save(source, f, shared)
+}
+
+// Reload drops the cached settings files and projects, e.g. after a
+// settings file was edited, so they are read again on the next request.
+func (ss *Settings) Reload() {
+	ss.files, ss.projects = nil, nil
+}
+
+// IsFile returns true if given path is the path of the global settings
+// file or of a project's settings file, e.g. to reload the settings
+// after such a file was saved.
+func (ss *Settings) IsFile(path string) bool {
+	path = filepath.Clean(path)
+	if path == ss.globalFile() {
+		return true
+	}
+	return path == filepath.Join(
+		filepath.Dir(filepath.Dir(path)), ProjectFile)
+}
+
+// key returns the key of given path in a settings file of given scope
+// which is relative to given project root if not empty.
+func (ss *Settings) key(scope Scope, root, path string) string {
+	if scope == Dir {
+		path = filepath.Dir(path)
+	}
+	if root == "" {
+		return path
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// layer holds the values of a scope for a file.
+type layer struct {
+	scope  Scope
+	source string
+	values map[string]json.RawMessage
+}
+
+// layers returns the layers of values for the file with given path
+// from the most specific to the most general.
+func (ss *Settings) layers(path string) (ll []layer) {
+	global := ss.globalFile()
+	gf, err := ss.load(global)
+	if err != nil {
+		ss.lg().Tof(lg.ERR, "%v", err)
+		gf = &file{}
+	}
+	if path == "" {
+		return []layer{{Global, global, gf.Values}}
+	}
+	root, pf, project := ss.project(path), &file{}, ""
+	if root != "" {
+		project = filepath.Join(root, ProjectFile)
+		if pf, err = ss.load(project); err != nil {
+			ss.lg().Tof(lg.ERR, "%v", err)
+			pf = &file{}
+		}
+	}
+	for _, scope := range []Scope{File, Dir} {
+		if root != "" {
+			ll = append(ll, layer{scope, project,
+				pf.values(scope, ss.key(scope, root, path))})
+		}
+		ll = append(ll, layer{scope, global,
+			gf.values(scope, ss.key(scope, "", path))})
+	}
+	if root != "" {
+		ll = append(ll, layer{Project, project, pf.Values})
+	}
+	return append(ll, layer{Global, global, gf.Values})
+}
+
+// globalFile returns the path of the global settings file.
+func (ss *Settings) globalFile() string {
+	return filepath.Join(ss.env().Conf(), GlobalFile)
+}
+
+// project returns the root directory of the project containing the file
+// with given path or an empty string if there is no such project.
+func (ss *Settings) project(path string) string {
+	if path == "" {
+		return ""
+	}
+	d := filepath.Dir(path)
+	if root, ok := ss.projects[d]; ok {
+		return root
+	}
+	root := ""
+	if _, err := ss.lib().Stat(d); err == nil {
+		if repo, ok := (&dir.Dir{Log: ss.lg(), Path: d}).Repo(); ok {
+			root = repo.String()
+		}
+	}
+	if ss.projects == nil {
+		ss.projects = map[string]string{}
+	}
+	ss.projects[d] = root
+	return root
+}
+
+// file is the json representation of a settings file.
+type file struct {
+	Values map[string]json.RawMessage            `json:"values,omitempty"`
+	Dirs   map[string]map[string]json.RawMessage `json:"dirs,omitempty"`
+	Files  map[string]map[string]json.RawMessage `json:"files,omitempty"`
+}
+
+// values returns given file f's values of given scope for given key.
+func (f *file) values(scope Scope, key string) map[string]json.RawMessage {
+	switch scope {
+	case File:
+		return f.Files[key]
+	case Dir:
+		return f.Dirs[key]
+	}
+	return f.Values
+}
+
+// set sets the setting with given name to given value raw in given
+// scope for given key.
+func (f *file) set(scope Scope, key, name string, raw json.RawMessage) {
+	var vv *map[string]json.RawMessage
+	switch scope {
+	case File:
+		vv = scoped(&f.Files, key)
+	case Dir:
+		vv = scoped(&f.Dirs, key)
+	default:
+		vv = &f.Values
+	}
+	if *vv == nil {
+		*vv = map[string]json.RawMessage{}
+	}
+	(*vv)[name] = raw
+}
+
+func scoped(
+	m *map[string]map[string]json.RawMessage, key string,
+) *map[string]json.RawMessage {
+	if *m == nil {
+		*m = map[string]map[string]json.RawMessage{}
+	}
+	vv := (*m)[key]
+	if vv == nil {
+		vv = map[string]json.RawMessage{}
+		(*m)[key] = vv
+	}
+	return &vv
+}
+
+// load returns the settings file with given path which is empty if it
+// doesn't exist.  A loaded file is cached.
+func (ss *Settings) load(path string) (*file, error) {
+	if f, ok := ss.files[path]; ok {
+		return f, nil
+	}
+	f := &file{}
+	bb, err := ss.lib().ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("gini: pkg: settings: load: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(bb, f); err != nil {
+			return nil, fmt.Errorf("gini: pkg: settings: load: %s: %w",
+				path, err)
+		}
+	}
+	if ss.files == nil {
+		ss.files = map[string]*file{}
+	}
+	ss.files[path] = f
+	return f, nil
+}
+
+// save writes given settings file f to given path.  A shared file, i.e.
+// a project's settings file, is readable by everyone while the global
+// settings file is private.
+func (ss *Settings) save(path string, f *file, shared bool) error {
+	bb, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("gini: pkg: settings: save: %w", err)
+	}
+	dirPerm, filePerm := fs.FileMode(0700), fs.FileMode(0600)
+	if shared {
+		dirPerm, filePerm = 0755, 0644
+	}
+	if err := ss.lib().MkdirAll(filepath.Dir(path), dirPerm); err != nil {
+		return fmt.Errorf("gini: pkg: settings: save: %w", err)
+	}
+	if err := ss.lib().WriteFile(path, bb, filePerm); err != nil {
+		return fmt.Errorf("gini: pkg: settings: save: %w", err)
+	}
+	return nil
+}
+
+// Lib provides std-lib functions which may fail.
+type Lib struct {
+
+	// ReadFile defaults to ioutil.ReadFile and its semantics
+	ReadFile func(name string) ([]byte, error)
+
+	// WriteFile defaults to ioutil.WriteFile and its semantics
+	WriteFile func(name string, data []byte, perm fs.FileMode) error
+
+	// MkdirAll defaults to os.MkdirAll and its semantics
+	MkdirAll func(path string, perm fs.FileMode) error
+
+	// Stat defaults to os.Stat and its semantics
+	Stat func(name string) (fs.FileInfo, error)
+}
@@ -0,0 +1,239 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package settings
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/slukits/gini/pkg/env"
+	"github.com/slukits/gini/pkg/lg"
+	. "github.com/slukits/gounit"
+)
+
+type _Settings struct{ Suite }
+
+func (s *_Settings) SetUp(t *T) { t.Parallel() }
+
+// fx returns settings in a temporary environment having the int setting
+// "backups" and a project "repo" containing the file "repo/pkg/a.go".
+func fx(t *T) (ss *Settings, file string) {
+	tmp := t.FS().Tmp().Path()
+	ss = &Settings{Log: &lg.Logger{Env: (&env.Env{}).SetHome(tmp)}}
+	t.FatalOn(ss.Register(Setting{Name: "backups", Kind: Int,
+		Default: 3, Check: func(v interface{}) error {
+			if v.(int) < 1 {
+				return fmt.Errorf("must be positive")
+			}
+			return nil
+		}}))
+	file = filepath.Join(tmp, "repo", "pkg", "a.go")
+	t.FatalOn(os.MkdirAll(filepath.Join(tmp, "repo", ".git"), 0700))
+	t.FatalOn(os.MkdirAll(filepath.Dir(file), 0700))
+	return ss, file
+}
+
+func (s *_Settings) Fails_to_register_a_setting_twice(t *T) {
+	ss, _ := fx(t)
+	t.ErrIs(ss.Register(Setting{Name: "backups", Default: 1}),
+		ErrRegistered)
+}
+
+func (s *_Settings) Fails_to_register_a_default_of_an_other_kind(t *T) {
+	ss, _ := fx(t)
+	t.ErrIs(ss.Register(Setting{Name: "wrap", Kind: Bool, Default: 1}),
+		ErrValue)
+}
+
+func (s *_Settings) Lists_registered_settings_sorted_by_name(t *T) {
+	ss, _ := fx(t)
+	t.FatalOn(ss.Register(Setting{Name: "auto", Kind: Bool}))
+	got := ss.Settings()
+	t.FatalIfNot(t.Eq(2, len(got)))
+	t.Eq("auto", got[0].Name)
+	t.Eq("backups", got[1].Name)
+}
+
+func (s *_Settings) Fails_to_resolve_an_unknown_setting(t *T) {
+	ss, file := fx(t)
+	_, err := ss.Value("unknown", file)
+	t.ErrIs(err, ErrUnknown)
+	t.ErrIs(ss.Set("unknown", "1", Global, file), ErrUnknown)
+}
+
+func (s *_Settings) Resolves_a_default_if_a_setting_is_not_set(t *T) {
+	ss, file := fx(t)
+	v, err := ss.Value("backups", file)
+	t.FatalOn(err)
+	t.Eq(3, v.Value)
+	t.Eq(Default, v.Scope)
+	t.Eq("", v.Source)
+}
+
+func (s *_Settings) Fails_to_set_an_invalid_value(t *T) {
+	ss, file := fx(t)
+	t.ErrIs(ss.Set("backups", "many", Global, file), ErrValue)
+	t.ErrIs(ss.Set("backups", "0", Global, file), ErrValue)
+}
+
+func (s *_Settings) Resolves_from_file_up_to_global_scope(t *T) {
+	ss, file := fx(t)
+	for i, scope := range []Scope{Global, Project, Dir, File} {
+		t.FatalOn(ss.Set("backups", fmt.Sprint(i+4), scope, file))
+		v, err := ss.Value("backups", file)
+		t.FatalOn(err)
+		t.Eq(i+4, v.Value)
+		t.Eq(scope, v.Scope)
+	}
+	t.Eq(7, ss.Int("backups", file))
+	other := filepath.Join(filepath.Dir(file), "b.go")
+	t.Eq(6, ss.Int("backups", other))
+	other = filepath.Join(filepath.Dir(filepath.Dir(file)), "c.go")
+	t.Eq(5, ss.Int("backups", other))
+	t.Eq(4, ss.Int("backups", ""))
+}
+
+func (s *_Settings) Stores_project_values_in_the_project(t *T) {
+	ss, file := fx(t)
+	t.FatalOn(ss.Set("backups", "5", Dir, file))
+	v, err := ss.Value("backups", file)
+	t.FatalOn(err)
+	root := filepath.Dir(filepath.Dir(file))
+	t.Eq(filepath.Join(root, ProjectFile), v.Source)
+	bb, err := os.ReadFile(v.Source)
+	t.FatalOn(err)
+	t.Contains(string(bb), `"pkg": {`)
+}
+
+func (s *_Settings) Stores_other_values_in_the_config_directory(t *T) {
+	ss, file := fx(t)
+	t.FatalOn(ss.Set("backups", "5", Global, file))
+	outside := filepath.Join(ss.Log.Env.Home(), "b.go")
+	t.FatalOn(ss.Set("backups", "6", File, outside))
+	v, err := ss.Value("backups", outside)
+	t.FatalOn(err)
+	t.Eq(filepath.Join(ss.Log.Env.Conf(), GlobalFile), v.Source)
+	t.Eq(File, v.Scope)
+	t.Eq(5, ss.Int("backups", file))
+}
+
+func (s *_Settings) Fails_to_set_a_project_value_outside_a_project(t *T) {
+	ss, _ := fx(t)
+	outside := filepath.Join(ss.Log.Env.Home(), "b.go")
+	t.ErrIs(ss.Set("backups", "5", Project, outside), ErrNoProject)
+}
+
+func (s *_Settings) Skips_and_reports_invalid_values_of_files(t *T) {
+	ss, file := fx(t)
+	t.FatalOn(ss.Set("backups", "5", Global, file))
+	project := filepath.Join(filepath.Dir(filepath.Dir(file)), ProjectFile)
+	t.FatalOn(os.MkdirAll(filepath.Dir(project), 0700))
+	t.FatalOn(os.WriteFile(project,
+		[]byte(`{"values":{"backups":"many"}}`), 0600))
+	t.Eq(5, ss.Int("backups", file))
+	t.Contains(ss.Log.String(lg.ERR), "invalid value")
+}
+
+func (s *_Settings) Reports_failing_to_save_a_value(t *T) {
+	ss, file := fx(t)
+	ss.Lib.WriteFile = func(string, []byte, fs.FileMode) error {
+		return errors.New("write mock")
+	}
+	t.ErrMatched(ss.Set("backups", "5", Global, file), "write mock")
+}
+
+func (s *_Settings) Fails_to_set_a_default_value(t *T) {
+	ss, file := fx(t)
+	t.ErrIs(ss.Set("backups", "5", Default, file), ErrScope)
+	_, err := os.Stat(filepath.Join(ss.Log.Env.Conf(), GlobalFile))
+	t.ErrIs(err, fs.ErrNotExist)
+}
+
+func (s *_Settings) Shares_project_files_and_keeps_global_files_private(
+	t *T,
+) {
+	ss, file := fx(t)
+	t.FatalOn(ss.Set("backups", "5", Project, file))
+	t.FatalOn(ss.Set("backups", "6", Global, file))
+	root := filepath.Dir(filepath.Dir(file))
+	for path, perm := range map[string]fs.FileMode{
+		filepath.Join(root, ProjectFile):               0644,
+		filepath.Dir(filepath.Join(root, ProjectFile)): 0755 | fs.ModeDir,
+		filepath.Join(ss.Log.Env.Conf(), GlobalFile):   0600,
+	} {
+		fi, err := os.Stat(path)
+		t.FatalOn(err)
+		t.Eq(perm, fi.Mode())
+	}
+}
+
+func (s *_Settings) Caches_settings_files_until_reloaded(t *T) {
+	ss, file := fx(t)
+	t.FatalOn(ss.Set("backups", "5", Project, file))
+	reads, stats := 0, 0
+	ss.Lib.ReadFile = func(name string) ([]byte, error) {
+		reads++
+		return os.ReadFile(name)
+	}
+	ss.Lib.Stat = func(name string) (fs.FileInfo, error) {
+		stats++
+		return os.Stat(name)
+	}
+	t.Eq(5, ss.Int("backups", file))
+	t.Eq(5, ss.Int("backups", file))
+	t.Eq(2, reads)
+	t.Eq(1, stats)
+	project := filepath.Join(filepath.Dir(filepath.Dir(file)), ProjectFile)
+	t.FatalOn(os.WriteFile(project, []byte(`{"values":{"backups":7}}`),
+		0644))
+	t.Eq(5, ss.Int("backups", file))
+	ss.Reload()
+	t.Eq(7, ss.Int("backups", file))
+}
+
+func (s *_Settings) Identifies_settings_files(t *T) {
+	ss, file := fx(t)
+	root := filepath.Dir(filepath.Dir(file))
+	t.True(ss.IsFile(filepath.Join(ss.Log.Env.Conf(), GlobalFile)))
+	t.True(ss.IsFile(filepath.Join(root, ProjectFile)))
+	t.Not.True(ss.IsFile(file))
+	t.Not.True(ss.IsFile(filepath.Join(root, GlobalFile)))
+}
+
+func (s *_Settings) Parses_scopes_by_name(t *T) {
+	for _, scope := range []Scope{File, Dir, Project, Global} {
+		got, ok := ParseScope(scope.String())
+		t.True(ok)
+		t.Eq(scope, got)
+	}
+	_, ok := ParseScope("default")
+	t.Not.True(ok)
+}
+
+func TestSettings(t *testing.T) {
+	t.Parallel()
+	Run(&_Settings{}, t)
+}
@@ -0,0 +1,268 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package spell
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrAffix is wrapped by the errors of invalid affix files.
+var ErrAffix = errors.New("gini: spell: affix")
+
+// Dict is a set of correctly spelled words.  The zero value is an empty
+// dictionary ready to use.
+type Dict struct{ words map[string]bool }
+
+// NewDict returns a dictionary of given words ww.
+func NewDict(ww ...string) *Dict {
+	d := &Dict{}
+	for _, w := range ww {
+		d.Add(w)
+	}
+	return d
+}
+
+// Add adds given word w to given dictionary d.
+func (d *Dict) Add(w string) {
+	if d.words == nil {
+		d.words = map[string]bool{}
+	}
+	d.words[w] = true
+}
+
+// Has returns true if given dictionary d contains given word w.
+func (d *Dict) Has(w string) bool { return d.words[w] }
+
+// Len returns the number of words of given dictionary d.
+func (d *Dict) Len() int { return len(d.words) }
+
+// Load loads the dictionary of the word list at given path which is
+// either a hunspell/myspell dictionary, an aspell personal word list or
+// a plain list of one word per line.  The words of a hunspell
+// dictionary are expanded by the prefix and suffix rules of the affix
+// file next to it, e.g. en_US.aff for en_US.dic.  Empty lines and lines
+// starting with # are ignored.
+func Load(path string) (*Dict, error) {
+	bb, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("gini: spell: %w", err)
+	}
+	var aa *affixes
+	if strings.HasSuffix(path, ".dic") {
+		aff, err := os.ReadFile(strings.TrimSuffix(path, ".dic") + ".aff")
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("gini: spell: %w", err)
+		}
+		if aa, err = parseAffixes(string(aff)); err != nil {
+			return nil, err
+		}
+	}
+	d := &Dict{}
+	scn := bufio.NewScanner(strings.NewReader(string(bb)))
+	for first := true; scn.Scan(); first = false {
+		line := strings.TrimSpace(scn.Text())
+		if line == "" || strings.HasPrefix(line, "#") || first &&
+			(isCount(line) || strings.HasPrefix(line, "personal_ws")) {
+			continue
+		}
+		if aa == nil {
+			d.Add(line)
+			continue
+		}
+		aa.expand(d, line)
+	}
+	return d, nil
+}
+
+// isCount returns true if given line is the word count heading a
+// hunspell dictionary.
+func isCount(line string) bool {
+	_, err := strconv.Atoi(line)
+	return err == nil
+}
+
+// affixes are the prefix and suffix rules of a hunspell affix file by
+// their flags.
+type affixes struct {
+	flags    string
+	prefixes map[string]*affix
+	suffixes map[string]*affix
+}
+
+// affix is a prefix or suffix class which may be combined with affixes
+// of the other kind if cross is true.
+type affix struct {
+	cross bool
+	rules []rule
+}
+
+// rule strips strip from a word matching cond and adds add.
+type rule struct {
+	strip, add string
+	cond       *regexp.Regexp
+}
+
+// parseAffixes parses the PFX and SFX rules and the FLAG type of given
+// affix file content src ignoring all other directives.
+func parseAffixes(src string) (*affixes, error) {
+	aa := &affixes{prefixes: map[string]*affix{},
+		suffixes: map[string]*affix{}}
+	for i, line := range strings.Split(src, "\n") {
+		ff := strings.Fields(line)
+		if len(ff) < 2 {
+			continue
+		}
+		if ff[0] == "FLAG" {
+			aa.flags = ff[1]
+			continue
+		}
+		if ff[0] != "PFX" && ff[0] != "SFX" || len(ff) < 4 {
+			continue
+		}
+		kind := aa.suffixes
+		if ff[0] == "PFX" {
+			kind = aa.prefixes
+		}
+		a, ok := kind[ff[1]]
+		if !ok {
+			kind[ff[1]] = &affix{cross: ff[2] == "Y"}
+			continue
+		}
+		r, err := newRule(ff[0] == "PFX", ff[2], ff[3], ff[4:])
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrAffix, i+1, err)
+		}
+		a.rules = append(a.rules, r)
+	}
+	return aa, nil
+}
+
+// newRule returns the rule of a prefix or suffix with given strip, add
+// and condition fields cc.
+func newRule(prefix bool, strip, add string, cc []string) (rule, error) {
+	if strip == "0" {
+		strip = ""
+	}
+	if i := strings.IndexByte(add, '/'); i >= 0 {
+		add = add[:i]
+	}
+	if add == "0" {
+		add = ""
+	}
+	cond := "."
+	if len(cc) > 0 {
+		cond = cc[0]
+	}
+	if prefix {
+		cond = "^" + cond
+	} else {
+		cond += "$"
+	}
+	re, err := regexp.Compile(cond)
+	return rule{strip: strip, add: add, cond: re}, err
+}
+
+// expand adds given dictionary entry, i.e. a word followed by its
+// flags, and the words derived from it by the affixes of its flags to
+// given dictionary d.
+func (aa *affixes) expand(d *Dict, entry string) {
+	if i := strings.IndexAny(entry, " \t"); i >= 0 {
+		entry = entry[:i]
+	}
+	w, flags, _ := strings.Cut(entry, "/")
+	d.Add(w)
+	var crossing []string
+	for _, f := range aa.split(flags) {
+		a, ok := aa.prefixes[f]
+		if !ok {
+			continue
+		}
+		for _, pw := range a.apply(w, true) {
+			d.Add(pw)
+			if a.cross {
+				crossing = append(crossing, pw)
+			}
+		}
+	}
+	for _, f := range aa.split(flags) {
+		a, ok := aa.suffixes[f]
+		if !ok {
+			continue
+		}
+		for _, sw := range a.apply(w, false) {
+			d.Add(sw)
+		}
+		if !a.cross {
+			continue
+		}
+		for _, pw := range crossing {
+			for _, sw := range a.apply(pw, false) {
+				d.Add(sw)
+			}
+		}
+	}
+}
+
+// apply returns the words derived from given word w by the rules of
+// given prefix or suffix a whose condition w matches.
+func (a *affix) apply(w string, prefix bool) (ww []string) {
+	for _, r := range a.rules {
+		if !r.cond.MatchString(w) {
+			continue
+		}
+		if prefix && strings.HasPrefix(w, r.strip) {
+			ww = append(ww, r.add+w[len(r.strip):])
+		}
+		if !prefix && strings.HasSuffix(w, r.strip) {
+			ww = append(ww, w[:len(w)-len(r.strip)]+r.add)
+		}
+	}
+	return ww
+}
+
+// split splits given flags of a dictionary entry according to given
+// affixes' flag type: one character per flag by default, two
+// characters per flag for the type long or comma separated numbers for
+// the type num.
+func (aa *affixes) split(flags string) []string {
+	switch aa.flags {
+	case "long":
+		ff := []string{}
+		for i := 0; i+1 < len(flags); i += 2 {
+			ff = append(ff, flags[i:i+2])
+		}
+		return ff
+	case "num":
+		return strings.Split(flags, ",")
+	}
+	ff := []string{}
+	for _, r := range flags {
+		ff = append(ff, string(r))
+	}
+	return ff
+}
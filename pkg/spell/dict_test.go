@@ -0,0 +1,111 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package spell
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/slukits/gounit"
+)
+
+type _Dict struct{ Suite }
+
+func (s *_Dict) SetUp(t *T) { t.Parallel() }
+
+// fileFX writes given content to a file with given name in given
+// directory dir and returns its path.
+func fileFX(t *T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	t.FatalOn(os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func (s *_Dict) Loads_plain_word_lists(t *T) {
+	dir := t.FS().Tmp().Path()
+	d, err := Load(fileFX(t, dir, "words", "# project words\ngini\n\nlexer\n"))
+	t.FatalOn(err)
+	t.Eq(2, d.Len())
+	t.True(d.Has("gini"))
+	t.True(d.Has("lexer"))
+}
+
+func (s *_Dict) Loads_aspell_personal_word_lists(t *T) {
+	dir := t.FS().Tmp().Path()
+	d, err := Load(fileFX(t, dir, ".aspell.en.pws",
+		"personal_ws-1.1 en 2\ngini\nlexer\n"))
+	t.FatalOn(err)
+	t.Eq(2, d.Len())
+	t.True(d.Has("gini"))
+}
+
+func (s *_Dict) Loads_hunspell_dictionaries_without_affixes(t *T) {
+	dir := t.FS().Tmp().Path()
+	d, err := Load(fileFX(t, dir, "en.dic", "2\nword/S\ncity/S po:noun\n"))
+	t.FatalOn(err)
+	t.Eq(2, d.Len())
+	t.True(d.Has("word"))
+	t.True(d.Has("city"))
+}
+
+func (s *_Dict) Expands_hunspell_affixes(t *T) {
+	dir := t.FS().Tmp().Path()
+	dic := fileFX(t, dir, "en.dic", "3\nword/SU\ncity/S\ndo/U\n")
+	fileFX(t, dir, "en.aff", `SET UTF-8
+PFX U Y 1
+PFX U   0     un         .
+
+SFX S Y 3
+SFX S   y     ies        [^aeiou]y
+SFX S   0     s          [aeiou]y
+SFX S   0     s          [^y]
+`)
+	d, err := Load(dic)
+	t.FatalOn(err)
+	for _, w := range []string{"word", "words", "unword", "unwords",
+		"city", "cities", "do", "undo"} {
+		t.True(d.Has(w))
+	}
+	t.Not.True(d.Has("citys"))
+}
+
+func (s *_Dict) Splits_long_and_numeric_flags(t *T) {
+	t.Eq([]string{"Aa", "Bb"}, (&affixes{flags: "long"}).split("AaBb"))
+	t.Eq([]string{"1", "22"}, (&affixes{flags: "num"}).split("1,22"))
+	t.Eq([]string{"A", "B"}, (&affixes{}).split("AB"))
+}
+
+func (s *_Dict) Fails_loading_invalid_affixes(t *T) {
+	dir := t.FS().Tmp().Path()
+	dic := fileFX(t, dir, "en.dic", "1\nword/S\n")
+	fileFX(t, dir, "en.aff", "SFX S Y 1\nSFX S 0 s [\n")
+	_, err := Load(dic)
+	t.ErrIs(err, ErrAffix)
+	_, err = Load(filepath.Join(dir, "none"))
+	t.Not.True(err == nil)
+}
+
+func TestDict(t *testing.T) {
+	t.Parallel()
+	Run(&_Dict{}, t)
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package spell
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StartTimeout is the maximal duration a started checker may take to
+// report its version.
+var StartTimeout = 5 * time.Second
+
+// QueryTimeout is the maximal duration a checker may take to report on
+// a word before it is considered hung and killed.
+var QueryTimeout = 2 * time.Second
+
+// ErrTimeout is wrapped by the error of a Pipe whose checker didn't
+// report on a word within the QueryTimeout.
+var ErrTimeout = errors.New("gini: spell: pipe: timeout")
+
+// ErrProtocol is wrapped by the errors of a Pipe whose checker doesn't
+// speak the ispell pipe protocol.
+var ErrProtocol = errors.New("gini: spell: pipe: protocol")
+
+// Pipe talks to an external spell-checker speaking the ispell pipe
+// protocol, e.g. "aspell -a" or "hunspell -a", which runs as long-lived
+// subprocess.  Its verdicts are cached by word.  A Pipe which failed
+// considers every word correct; its error is reported by Err.
+type Pipe struct {
+	mutex sync.Mutex
+	cmd   *exec.Cmd
+	in    io.WriteCloser
+	out   *bufio.Reader
+	err   error
+
+	// verdicts are the suggestions of misspelled words and nil for
+	// correct words.
+	verdicts map[string][]string
+}
+
+// Start starts the checker with given name and arguments args which
+// must put it into the pipe mode, e.g. Start("hunspell", "-a", "-d",
+// "en_US").  The checker is switched to its terse mode reporting only
+// misspelled words.
+func Start(name string, args ...string) (*Pipe, error) {
+	p := &Pipe{cmd: exec.Command(name, args...),
+		verdicts: map[string][]string{}}
+	in, err := p.cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("gini: spell: pipe: %w", err)
+	}
+	out, err := p.cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("gini: spell: pipe: %w", err)
+	}
+	if err := p.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("gini: spell: pipe: %w", err)
+	}
+	p.in, p.out = in, bufio.NewReader(out)
+	if !p.banner() {
+		p.cmd.Process.Kill()
+		p.Close()
+		return nil, fmt.Errorf("%w: %s: no banner", ErrProtocol, name)
+	}
+	if _, err := io.WriteString(p.in, "!\n"); err != nil {
+		p.Close()
+		return nil, fmt.Errorf("gini: spell: pipe: %w", err)
+	}
+	return p, nil
+}
+
+// banner returns true if given pipe p's checker reports its version
+// within the StartTimeout.
+func (p *Pipe) banner() bool {
+	line, err := p.readLine(time.Now().Add(StartTimeout))
+	return err == nil && strings.HasPrefix(line, "@(#)")
+}
+
+// readLine reads the next line of given pipe p's checker which must be
+// reported before given deadline; otherwise the checker is killed and
+// an error wrapping ErrTimeout is returned.
+func (p *Pipe) readLine(deadline time.Time) (string, error) {
+	type read struct {
+		line string
+		err  error
+	}
+	c := make(chan read, 1)
+	go func() {
+		line, err := p.out.ReadString('\n')
+		c <- read{line: line, err: err}
+	}()
+	select {
+	case r := <-c:
+		return r.line, r.err
+	case <-time.After(time.Until(deadline)):
+		p.cmd.Process.Kill()
+		return "", fmt.Errorf("%w: %s", ErrTimeout, p.cmd.Path)
+	}
+}
+
+// Correct returns true if given pipe p's checker accepts given word w.
+func (p *Pipe) Correct(w string) bool {
+	ss, ok := p.verdict(w)
+	return !ok || ss == nil
+}
+
+// Suggest returns at most given number n of the corrections given pipe
+// p's checker suggests for given word w.
+func (p *Pipe) Suggest(w string, n int) []string {
+	ss, _ := p.verdict(w)
+	if len(ss) > n {
+		ss = ss[:n]
+	}
+	return ss
+}
+
+// Err returns the error which made given pipe p fail.
+func (p *Pipe) Err() error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.err
+}
+
+// Close ends given pipe p's checker.
+func (p *Pipe) Close() error {
+	if p.in != nil {
+		p.in.Close()
+	}
+	return p.cmd.Wait()
+}
+
+// verdict returns the suggestions for given word w which are nil if w
+// is correct and false if the checker failed.
+func (p *Pipe) verdict(w string) ([]string, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.err != nil {
+		return nil, false
+	}
+	if ss, ok := p.verdicts[w]; ok {
+		return ss, true
+	}
+	ss, err := p.query(w)
+	if err != nil {
+		p.err = err
+		return nil, false
+	}
+	p.verdicts[w] = ss
+	return ss, true
+}
+
+// query sends given word w to the checker and reads its report which
+// is terminated by an empty line and must be complete within the
+// QueryTimeout.  The leading ^ keeps the checker from interpreting w as
+// a command.
+func (p *Pipe) query(w string) (ss []string, err error) {
+	if _, err := io.WriteString(p.in, "^"+w+"\n"); err != nil {
+		return nil, fmt.Errorf("gini: spell: pipe: %w", err)
+	}
+	deadline := time.Now().Add(QueryTimeout)
+	for {
+		line, err := p.readLine(deadline)
+		if errors.Is(err, ErrTimeout) {
+			return nil, err
+		}
+		if err != nil {
+			return nil, fmt.Errorf("gini: spell: pipe: %w", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			return ss, nil
+		}
+		if misspelled, ok := parseReport(line); ok && ss == nil {
+			ss = misspelled
+		}
+	}
+}
+
+// parseReport parses given report line of a misspelled word, i.e.
+//
+//	& word count offset: suggestion, suggestion, ...
+//	? word count offset: guess, guess, ...
+//	# word offset
+//
+// and returns its suggestions which are empty but not nil for a word
+// without suggestions.  It returns false for other report lines, e.g.
+// "*" for a correct word.
+func parseReport(line string) ([]string, bool) {
+	switch {
+	case strings.HasPrefix(line, "#"):
+		return []string{}, true
+	case strings.HasPrefix(line, "&"), strings.HasPrefix(line, "?"):
+	default:
+		return nil, false
+	}
+	_, list, ok := strings.Cut(line, ": ")
+	if !ok {
+		return []string{}, true
+	}
+	ss := []string{}
+	for _, s := range strings.Split(list, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			ss = append(ss, s)
+		}
+	}
+	return ss, true
+}
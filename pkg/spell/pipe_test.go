@@ -0,0 +1,115 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package spell
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/slukits/gounit"
+)
+
+type _Pipe struct{ Suite }
+
+func (s *_Pipe) SetUp(t *T) { t.Parallel() }
+
+// fakeSpell is a fake checker speaking the ispell pipe protocol which
+// accepts the words given after -a.
+var fakeSpell = filepath.Join("testdata", "fakespell")
+
+// pipeFX starts the fake checker accepting given words ww.
+func pipeFX(t *T, ww ...string) *Pipe {
+	p, err := Start(fakeSpell, append([]string{"-a"}, ww...)...)
+	t.FatalOn(err)
+	return p
+}
+
+func (s *_Pipe) Reports_correct_and_misspelled_words(t *T) {
+	p := pipeFX(t, "the", "word", "world")
+	defer p.Close()
+	t.True(p.Correct("word"))
+	t.Not.True(p.Correct("wrod"))
+	t.Eq([]string{"word", "world"}, p.Suggest("wrod", 5))
+	t.Eq([]string{"word"}, p.Suggest("wrod", 1))
+	t.Not.True(p.Correct("xyz"))
+	t.Eq(0, len(p.Suggest("xyz", 5)))
+	t.FatalOn(p.Err())
+}
+
+func (s *_Pipe) Caches_verdicts(t *T) {
+	p := pipeFX(t, "word")
+	t.Not.True(p.Correct("wrod"))
+	t.FatalOn(p.Close())
+	t.Not.True(p.Correct("wrod"))
+	t.True(p.Err() == nil)
+}
+
+func (s *_Pipe) Accepts_all_words_after_failing(t *T) {
+	p := pipeFX(t, "word")
+	t.FatalOn(p.Close())
+	t.True(p.Correct("wrod"))
+	t.Not.True(p.Err() == nil)
+}
+
+func (s *_Pipe) Fails_on_a_hanging_checker(t *T) {
+	p := pipeFX(t, "word")
+	t.True(p.Correct("hang"))
+	t.ErrIs(p.Err(), ErrTimeout)
+	t.True(p.Correct("wrod"))
+	p.Close()
+}
+
+func (s *_Pipe) Fails_to_start_a_checker_without_pipe_mode(t *T) {
+	_, err := Start(fakeSpell)
+	t.ErrIs(err, ErrProtocol)
+	_, err = Start(filepath.Join("testdata", "none"), "-a")
+	t.Not.True(err == nil)
+}
+
+func (s *_Pipe) Parses_reports_of_misspelled_words(t *T) {
+	ss, ok := parseReport("& wrod 2 0: word, world")
+	t.True(ok)
+	t.Eq([]string{"word", "world"}, ss)
+	ss, ok = parseReport("? wrod 0 0: word")
+	t.True(ok)
+	t.Eq([]string{"word"}, ss)
+	ss, ok = parseReport("# xyz 0")
+	t.True(ok)
+	t.True(ss != nil && len(ss) == 0)
+	_, ok = parseReport("*")
+	t.Not.True(ok)
+}
+
+func (s *_Pipe) Is_consulted_by_a_checker(t *T) {
+	p := pipeFX(t, "the", "word", "world")
+	defer p.Close()
+	c := &Checker{Dicts: []*Dict{NewDict("gini")}, Pipe: p}
+	mm := c.Line(0, "the gini wrod")
+	t.Eq(1, len(mm))
+	t.Eq(Misspelling{Word: "wrod", Cl: 9}, mm[0])
+	t.Eq([]string{"word", "world"}, c.Suggest("wrod", 5))
+}
+
+func TestPipe(t *testing.T) {
+	t.Parallel()
+	Run(&_Pipe{}, t)
+}
@@ -0,0 +1,272 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+/*
+Package spell checks the spelling of prose and of the words of code
+identifiers against local word lists, i.e. hunspell/myspell dictionaries,
+aspell personal word lists and the word list of a project.  Identifiers
+are split at camelCase humps, underscores and digits while the tokens of
+a lexer (see lex) restrict the checked text of source files to comments,
+strings and identifiers.  A Pipe consults an external checker speaking
+the ispell pipe protocol like "aspell -a" or "hunspell -a".
+*/
+package spell
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/slukits/gini/pkg/lex"
+)
+
+// ProjectFile is the word list of a project relative to its root
+// directory.
+const ProjectFile = ".gini/words"
+
+// DictDirs are the directories searched for hunspell/myspell
+// dictionaries.
+var DictDirs = []string{"/usr/share/hunspell", "/usr/share/myspell",
+	"/usr/share/myspell/dicts", "/usr/local/share/hunspell"}
+
+// Classes are the token classes whose words are checked.
+var Classes = map[string]bool{"comment": true, "string": true,
+	"ident": true}
+
+// Find returns the paths of the existing word lists of given language
+// lang, e.g. en_US, which are the hunspell dictionaries in given
+// directories dirs and the personal word lists of aspell and hunspell
+// in given home directory.
+func Find(dirs []string, home, lang string) (pp []string) {
+	for _, d := range dirs {
+		pp = append(pp, filepath.Join(d, lang+".dic"))
+	}
+	if home != "" {
+		short, _, _ := strings.Cut(lang, "_")
+		pp = append(pp, filepath.Join(home, ".aspell."+short+".pws"),
+			filepath.Join(home, ".hunspell_"+lang))
+	}
+	existing := pp[:0]
+	for _, p := range pp {
+		if fi, err := os.Stat(p); err == nil && !fi.IsDir() {
+			existing = append(existing, p)
+		}
+	}
+	return existing
+}
+
+// Word is a word of a text at the rune column Cl.
+type Word struct {
+	Text string
+	Cl   int
+}
+
+// Words returns the words of given text s which are the sequences of
+// letters split at camelCase humps, e.g. parseHTTPRequest has the words
+// parse, HTTP and Request.  An apostrophe between letters is part of a
+// word.
+func Words(s string) (ww []Word) {
+	rr := []rune(s)
+	start := -1
+	for i := 0; i <= len(rr); i++ {
+		if i < len(rr) && (unicode.IsLetter(rr[i]) || start >= 0 &&
+			rr[i] == '\'' && i+1 < len(rr) && unicode.IsLetter(rr[i+1])) {
+			if start < 0 {
+				start = i
+			}
+			if start < i && hump(rr, i) {
+				ww = append(ww, Word{Text: string(rr[start:i]), Cl: start})
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			ww = append(ww, Word{Text: string(rr[start:i]), Cl: start})
+			start = -1
+		}
+	}
+	return ww
+}
+
+// hump returns true if a camelCase word starts at given index i of
+// given runes rr, i.e. an upper case letter following a lower case
+// letter or an upper case letter followed by a lower case letter which
+// follows an upper case letter.
+func hump(rr []rune, i int) bool {
+	if !unicode.IsUpper(rr[i]) {
+		return false
+	}
+	if unicode.IsLower(rr[i-1]) {
+		return true
+	}
+	return unicode.IsUpper(rr[i-1]) && i+1 < len(rr) &&
+		unicode.IsLower(rr[i+1])
+}
+
+// Misspelling is a misspelled word of a line.
+type Misspelling struct {
+	Word string
+
+	// Ln and Cl are the zero-based line and rune column of the word.
+	Ln, Cl int
+}
+
+// Len returns the number of runes of given misspelling's word.
+func (m Misspelling) Len() int { return utf8.RuneCountInString(m.Word) }
+
+// Checker checks words against its dictionaries and its Pipe to an
+// external checker.  A checker without dictionaries and pipe considers
+// every word correct.
+type Checker struct {
+	Dicts []*Dict
+
+	// Pipe is consulted about words which aren't in the dictionaries.
+	Pipe *Pipe
+}
+
+// Correct returns true if given word w is in one of the dictionaries of
+// given checker c either as it is or lower cased or if c's pipe accepts
+// it.  Single letters, acronyms which are all upper case and a word's
+// possessive form are correct as well.
+func (c *Checker) Correct(w string) bool {
+	if len(c.Dicts) == 0 && c.Pipe == nil ||
+		utf8.RuneCountInString(w) < 2 || strings.ToUpper(w) == w {
+		return true
+	}
+	if c.has(w) || c.has(strings.ToLower(w)) {
+		return true
+	}
+	if stem := strings.TrimSuffix(w, "'s"); stem != w && stem != "" &&
+		c.Correct(stem) {
+		return true
+	}
+	return c.Pipe != nil && c.Pipe.Correct(w)
+}
+
+func (c *Checker) has(w string) bool {
+	for _, d := range c.Dicts {
+		if d.Has(w) {
+			return true
+		}
+	}
+	return false
+}
+
+// Line returns the misspelled words of given line s with given index
+// ln.
+func (c *Checker) Line(ln int, s string) (mm []Misspelling) {
+	for _, w := range Words(s) {
+		if !c.Correct(w.Text) {
+			mm = append(mm, Misspelling{Word: w.Text, Ln: ln, Cl: w.Cl})
+		}
+	}
+	return mm
+}
+
+// Tokens returns the misspelled words of given tokens tt whose class is
+// one of the checked Classes.
+func (c *Checker) Tokens(tt []lex.Token) (mm []Misspelling) {
+	for _, t := range tt {
+		if !Classes[t.Class] {
+			continue
+		}
+		for _, m := range c.Line(t.Ln, t.Text) {
+			m.Cl += t.Cl
+			mm = append(mm, m)
+		}
+	}
+	return mm
+}
+
+// Suggest returns at most given number n of the corrections of given
+// word w suggested by given checker c's pipe or the dictionary words
+// which are one edit away from w or two edits if there are no such
+// words.  An edit deletes, inserts, replaces or transposes letters.
+// The suggestions of a capitalized word are capitalized.
+func (c *Checker) Suggest(w string, n int) []string {
+	if c.Pipe != nil {
+		return c.Pipe.Suggest(w, n)
+	}
+	lower := strings.ToLower(w)
+	found := map[string]bool{}
+	ee := edits(lower)
+	for _, e := range ee {
+		if c.has(e) {
+			found[e] = true
+		}
+	}
+	if len(found) == 0 {
+		for _, e := range ee {
+			for _, e2 := range edits(e) {
+				if c.has(e2) {
+					found[e2] = true
+				}
+			}
+		}
+	}
+	delete(found, lower)
+	ss := make([]string, 0, len(found))
+	for s := range found {
+		if r, _ := utf8.DecodeRuneInString(w); unicode.IsUpper(r) {
+			s = capitalized(s)
+		}
+		ss = append(ss, s)
+	}
+	sort.Strings(ss)
+	if len(ss) > n {
+		ss = ss[:n]
+	}
+	return ss
+}
+
+// alphabet are the letters inserted or replaced by edits.
+const alphabet = "abcdefghijklmnopqrstuvwxyz'"
+
+// edits returns the strings which are one edit away from given word w.
+func edits(w string) (ee []string) {
+	rr := []rune(w)
+	for i := 0; i <= len(rr); i++ {
+		head, tail := string(rr[:i]), rr[i:]
+		if len(tail) > 0 {
+			ee = append(ee, head+string(tail[1:]))
+		}
+		if len(tail) > 1 {
+			ee = append(ee, head+string(tail[1])+string(tail[0])+
+				string(tail[2:]))
+		}
+		for _, l := range alphabet {
+			ee = append(ee, head+string(l)+string(tail))
+			if len(tail) > 0 && l != tail[0] {
+				ee = append(ee, head+string(l)+string(tail[1:]))
+			}
+		}
+	}
+	return ee
+}
+
+// capitalized returns given string s with its first letter upper case.
+func capitalized(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
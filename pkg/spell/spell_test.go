@@ -0,0 +1,113 @@
+/*
+Copyright 2022 - present Stephan Lukits. All rights reserved.
+Use of this source code is governed by the GNU GPLv3 that can
+be found in the LICENSE file.
+
+This file is part of GINI.
+
+GINI is free software: you can redistribute it and/or modify it
+under the terms of the GNU General Public License as published
+by the Free Software Foundation, either version 3 of the License,
+or (at your option) any later version.
+
+GINI is distributed in the hope that it will be useful, but
+WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with GINI. If not, see <https://www.gnu.org/licenses/#GPL>.
+*/
+
+package spell
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/slukits/gini/pkg/lex"
+	. "github.com/slukits/gounit"
+)
+
+type _Checker struct{ Suite }
+
+func (s *_Checker) SetUp(t *T) { t.Parallel() }
+
+// words returns the texts of given words ww.
+func words(ww []Word) (ss []string) {
+	for _, w := range ww {
+		ss = append(ss, w.Text)
+	}
+	return ss
+}
+
+func (s *_Checker) Splits_identifiers_into_words(t *T) {
+	t.Eq([]string{"parse", "HTTP", "Request"},
+		words(Words("parseHTTPRequest")))
+	t.Eq([]string{"max", "line", "len"}, words(Words("max_line2len")))
+	t.Eq([]string{"don't", "go"}, words(Words("don't 'go'")))
+	ww := Words("a fooBar")
+	t.Eq(2, ww[1].Cl)
+	t.Eq(5, ww[2].Cl)
+}
+
+func (s *_Checker) Accepts_words_of_its_dictionaries(t *T) {
+	c := &Checker{Dicts: []*Dict{NewDict("the", "word"),
+		NewDict("gini")}}
+	t.True(c.Correct("word"))
+	t.True(c.Correct("The"))
+	t.True(c.Correct("gini"))
+	t.True(c.Correct("GINI"))
+	t.True(c.Correct("gini's"))
+	t.True(c.Correct("x"))
+	t.Not.True(c.Correct("wrod"))
+	t.True((&Checker{}).Correct("wrod"))
+}
+
+func (s *_Checker) Reports_misspelled_words_of_a_line(t *T) {
+	c := &Checker{Dicts: []*Dict{NewDict("the", "word", "line")}}
+	mm := c.Line(2, "the wrod lineLenght")
+	t.Eq(2, len(mm))
+	t.Eq(Misspelling{Word: "wrod", Ln: 2, Cl: 4}, mm[0])
+	t.Eq(Misspelling{Word: "Lenght", Ln: 2, Cl: 13}, mm[1])
+	t.Eq(6, mm[1].Len())
+}
+
+func (s *_Checker) Reports_misspelled_words_of_checked_tokens(t *T) {
+	l, err := lex.Compile("comment //.*\nkeyword func\\b\n" +
+		"ident \\pL+\nspace \\s+\n")
+	t.FatalOn(err)
+	c := &Checker{Dicts: []*Dict{NewDict("the", "run")}}
+	mm := c.Tokens(l.Lex([]string{"func rnu // teh"}).Tokens(0))
+	t.Eq(2, len(mm))
+	t.Eq(Misspelling{Word: "rnu", Cl: 5}, mm[0])
+	t.Eq(Misspelling{Word: "teh", Cl: 12}, mm[1])
+}
+
+func (s *_Checker) Suggests_corrections(t *T) {
+	c := &Checker{Dicts: []*Dict{NewDict("the", "they", "word",
+		"world", "lexer")}}
+	t.Eq([]string{"the", "they"}, c.Suggest("thy", 5))
+	t.Eq([]string{"Word"}, c.Suggest("Wrod", 5))
+	t.Eq([]string{"the"}, c.Suggest("thy", 1))
+	t.Eq([]string{"lexer"}, c.Suggest("lxre", 5))
+	t.Eq(0, len(c.Suggest("qqqqqq", 5)))
+}
+
+func (s *_Checker) Finds_existing_word_lists(t *T) {
+	dir, home := t.FS().Tmp().Path(), t.FS().Tmp().Path()
+	for _, p := range []string{filepath.Join(dir, "en_US.dic"),
+		filepath.Join(home, ".aspell.en.pws")} {
+		t.FatalOn(os.WriteFile(p, []byte("word\n"), 0600))
+	}
+	t.Eq([]string{filepath.Join(dir, "en_US.dic"),
+		filepath.Join(home, ".aspell.en.pws")},
+		Find([]string{dir, filepath.Join(dir, "none")}, home, "en_US"))
+	t.Eq(0, len(Find([]string{dir}, "", "de_DE")))
+}
+
+func TestChecker(t *testing.T) {
+	t.Parallel()
+	Run(&_Checker{}, t)
+}